@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	account "github.com/lmnzx/slopify/account/proto"
+	"github.com/lmnzx/slopify/pkg/dataloader"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxBatchSize matches the limit enforced by BatchGetUsers on the server
+	MaxBatchSize = 100
+	// BatchWait is how long a loader waits for more lookups before sending
+	BatchWait = 2 * time.Millisecond
+)
+
+func BatchGetUsers(ctx context.Context, c account.AccountServiceClient, ids []string) (*account.BatchGetUsersResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	r, err := c.BatchGetUsers(ctx, &account.BatchGetUsersRequest{UserIds: ids})
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// NewUserLoader returns a loader that turns concurrent single user lookups
+// into BatchGetUsers calls. Unknown and malformed ids resolve to
+// dataloader.ErrNotFound.
+func NewUserLoader(c account.AccountServiceClient) *dataloader.Loader[string, *account.User] {
+	return dataloader.New(func(ctx context.Context, keys []string) (map[string]*account.User, error) {
+		// the server keys users by their canonical id, which need not be the
+		// form a caller asked for, e.g. upper case
+		canonical := make(map[string]string, len(keys))
+		ids := make([]string, 0, len(keys))
+		for _, key := range keys {
+			id, err := uuid.Parse(key)
+			if err != nil {
+				// left out so it can't fail the batch for the other keys
+				continue
+			}
+			if !slices.Contains(ids, id.String()) {
+				ids = append(ids, id.String())
+			}
+			canonical[key] = id.String()
+		}
+		if len(ids) == 0 {
+			return map[string]*account.User{}, nil
+		}
+
+		r, err := BatchGetUsers(ctx, c, ids)
+		if err != nil {
+			return nil, err
+		}
+
+		users := make(map[string]*account.User, len(keys))
+		for key, id := range canonical {
+			if user, ok := r.Users[id]; ok {
+				users[key] = user
+			}
+		}
+		return users, nil
+	}, BatchWait, MaxBatchSize)
+}
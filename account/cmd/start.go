@@ -10,13 +10,15 @@ import (
 
 	"github.com/lmnzx/slopify/account/config"
 	"github.com/lmnzx/slopify/account/handler"
-	"github.com/lmnzx/slopify/account/repository"
+	"github.com/lmnzx/slopify/account/internal"
 	auth "github.com/lmnzx/slopify/auth/proto"
 	"github.com/lmnzx/slopify/pkg/instrumentation"
 	"github.com/lmnzx/slopify/pkg/logger"
 
 	"github.com/exaring/otelpgx"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/valkey-io/valkey-go"
+	"github.com/valkey-io/valkey-go/valkeyotel"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -56,7 +58,19 @@ func main() {
 		log.Fatal().Err(err).Msg("unable to ping database")
 	}
 
-	queries := repository.New(dbpool)
+	client, err := valkey.ParseURL(config.GetValkeyConnectionString())
+	if err != nil {
+		log.Fatal().Err(err).Msg("unable to parse valkey url")
+	}
+
+	valkeyClient, err := valkeyotel.NewClient(client)
+	if err != nil {
+		log.Fatal().Err(err).Msg("unable to connect to valkey database")
+	}
+	if err := valkeyClient.Do(ctx, valkeyClient.B().Ping().Build()).Error(); err != nil {
+		log.Fatal().Err(err).Msg("unable to ping to valkey database")
+	}
+	defer valkeyClient.Close()
 
 	conn, err := grpc.NewClient(config.AuthServiceAddress,
 		grpc.WithUnaryInterceptor(instrumentation.UnaryClientInstrumentationMiddleware(config.Name)),
@@ -69,13 +83,42 @@ func main() {
 
 	c := auth.NewAuthServiceClient(conn)
 
+	var smsSender internal.SMSSender
+	switch config.SMS.Sender {
+	case "file":
+		fileSender, err := internal.NewFileSMSSender(config.SMS.File)
+		if err != nil {
+			log.Fatal().Err(err).Str("file", config.SMS.File).Msg("unable to open sms file")
+		}
+		defer fileSender.Close()
+		smsSender = fileSender
+	case "log", "":
+		smsSender = internal.NewLogSMSSender()
+	default:
+		log.Fatal().Str("sender", config.SMS.Sender).Msg("unknown sms sender")
+	}
+
+	accountService := internal.NewAccountService(dbpool, valkeyClient, c, smsSender, internal.DeletionPolicy{
+		GracePeriod: config.Deletion.GracePeriod,
+		Anonymise:   config.Deletion.Anonymise,
+	})
+
 	var wg sync.WaitGroup
 
 	wg.Add(1)
-	go handler.StartGrpcServer(ctx, config.GrpcServerAddress, queries, &wg)
+	go handler.StartGrpcServer(ctx, config.GrpcServerAddress, dbpool, accountService, c, config.Admins, &wg)
+
+	wg.Add(1)
+	go handler.StartRestServer(ctx, config.RestServerAddress, dbpool, accountService, c, config.Admins, &wg)
+
+	wg.Add(1)
+	go accountService.StartDeletionWorker(ctx, config.Deletion.PurgeInterval, &wg)
+
+	wg.Add(1)
+	go accountService.StartOutboxRelay(ctx, config.Outbox.RelayInterval, &wg)
 
 	wg.Add(1)
-	go handler.StartRestServer(ctx, config.RestServerAddress, queries, c, &wg)
+	go accountService.StartDataExportWorker(ctx, config.Export.PollInterval, &wg)
 
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
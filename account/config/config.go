@@ -4,17 +4,19 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/lmnzx/slopify/pkg/logger"
 	"github.com/spf13/viper"
 )
 
 type AccountServiceConfig struct {
-	Name               string `mapstructure:"name"`
-	Version            string `mapstructure:"version"`
-	RestServerAddress  string `mapstructure:"restserveraddress"`
-	GrpcServerAddress  string `mapstructure:"grpcserveraddress"`
-	AuthServiceAddress string `mapstructure:"authserviceaddress"`
+	Name               string   `mapstructure:"name"`
+	Version            string   `mapstructure:"version"`
+	RestServerAddress  string   `mapstructure:"restserveraddress"`
+	GrpcServerAddress  string   `mapstructure:"grpcserveraddress"`
+	AuthServiceAddress string   `mapstructure:"authserviceaddress"`
+	Admins             []string `mapstructure:"admins"`
 	Postgres           struct {
 		User     string `mapstructure:"user"`
 		Password string `mapstructure:"password"`
@@ -23,6 +25,31 @@ type AccountServiceConfig struct {
 		DBName   string `mapstructure:"dbname"`
 		SSL      bool   `mapstructure:"ssl"`
 	}
+	Valkey struct {
+		User     string `mapstructure:"user"`
+		Password string `mapstructure:"password"`
+		Host     string `mapstructure:"host"`
+		Port     string `mapstructure:"port"`
+		DBNumber string `mapstructure:"dbnumber"`
+	}
+	SMS struct {
+		// Sender is "log" or "file", both are for development only
+		Sender string `mapstructure:"sender"`
+		File   string `mapstructure:"file"`
+	}
+	Deletion struct {
+		GracePeriod   time.Duration `mapstructure:"graceperiod"`
+		PurgeInterval time.Duration `mapstructure:"purgeinterval"`
+		Anonymise     bool          `mapstructure:"anonymise"`
+	}
+	Outbox struct {
+		RelayInterval time.Duration `mapstructure:"relayinterval"`
+	}
+	Export struct {
+		// PollInterval is how often exports left pending by a crash are
+		// looked for, new requests are picked up straight away
+		PollInterval time.Duration `mapstructure:"pollinterval"`
+	}
 	OtelCollectorURL string `mapstructure:"otelcollectorurl"`
 }
 
@@ -65,3 +92,12 @@ func (c *AccountServiceConfig) GetDBConnectionString() string {
 		c.Postgres.DBName,
 		sslMode)
 }
+
+func (c *AccountServiceConfig) GetValkeyConnectionString() string {
+	return fmt.Sprintf("valkey://%s:%s@%s:%s/%s",
+		c.Valkey.User,
+		c.Valkey.Password,
+		c.Valkey.Host,
+		c.Valkey.Port,
+		c.Valkey.DBNumber)
+}
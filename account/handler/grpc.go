@@ -2,38 +2,49 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net"
-	"strings"
 	"sync"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lmnzx/slopify/account/internal"
 	"github.com/lmnzx/slopify/account/proto"
 	"github.com/lmnzx/slopify/account/repository"
+	auth "github.com/lmnzx/slopify/auth/proto"
 	"github.com/lmnzx/slopify/pkg/instrumentation"
 	"github.com/lmnzx/slopify/pkg/logger"
+	"github.com/lmnzx/slopify/pkg/middleware"
 
 	"golang.org/x/crypto/bcrypt"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type GrpcHandler struct {
 	proto.UnimplementedAccountServiceServer
-	queries *repository.Queries
+	queries        *repository.Queries
+	accountService *internal.AccountService
+	authClient     auth.AuthServiceClient
+	admins         []string
 }
 
-func NewGrpcHandler(queries *repository.Queries) *GrpcHandler {
+func NewGrpcHandler(dbpool *pgxpool.Pool, accountService *internal.AccountService, authClient auth.AuthServiceClient, admins []string) *GrpcHandler {
 	return &GrpcHandler{
-		queries: queries,
+		queries:        repository.New(dbpool),
+		accountService: accountService,
+		authClient:     authClient,
+		admins:         admins,
 	}
 }
 
-func StartGrpcServer(ctx context.Context, port string, queries *repository.Queries, wg *sync.WaitGroup) {
+func StartGrpcServer(ctx context.Context, port string, dbpool *pgxpool.Pool, accountService *internal.AccountService, authClient auth.AuthServiceClient, admins []string, wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	log := logger.GetLogger()
@@ -50,7 +61,7 @@ func StartGrpcServer(ctx context.Context, port string, queries *repository.Queri
 		),
 	)
 
-	h := NewGrpcHandler(queries)
+	h := NewGrpcHandler(dbpool, accountService, authClient, admins)
 	proto.RegisterAccountServiceServer(s, h)
 	reflection.Register(s)
 
@@ -91,8 +102,8 @@ func (h *GrpcHandler) GetUserById(ctx context.Context, req *proto.GetUserByIdReq
 
 	user, err := h.queries.GetUserById(ctx, id)
 	if err != nil {
-		if strings.Contains(err.Error(), "no row") {
-			return nil, nil
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Error(codes.NotFound, "user not found")
 		}
 		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
 	}
@@ -106,8 +117,8 @@ func (h *GrpcHandler) GetUserByEmail(ctx context.Context, req *proto.GetUserByEm
 
 	user, err := h.queries.GetUserByEmail(ctx, req.Email)
 	if err != nil {
-		if strings.Contains(err.Error(), "no row") {
-			return nil, nil
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Error(codes.NotFound, "user not found")
 		}
 		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
 	}
@@ -115,33 +126,107 @@ func (h *GrpcHandler) GetUserByEmail(ctx context.Context, req *proto.GetUserByEm
 	return dbUserToProtoUser(&user), nil
 }
 
-func (h *GrpcHandler) CreateUser(ctx context.Context, req *proto.CreateUserRequest) (*proto.User, error) {
-	if req.Name == "" || req.Email == "" || req.Address == "" || req.Password == "" {
-		return nil, status.Errorf(codes.InvalidArgument, "missing field")
+func (h *GrpcHandler) UserExists(ctx context.Context, req *proto.UserExistsRequest) (*proto.UserExistsResponse, error) {
+	var err error
+	switch lookup := req.Lookup.(type) {
+	case *proto.UserExistsRequest_UserId:
+		var id uuid.UUID
+		id, err = parseID("user id", lookup.UserId)
+		if err != nil {
+			return nil, err
+		}
+		_, err = h.queries.GetUserById(ctx, id)
+	case *proto.UserExistsRequest_Email:
+		if lookup.Email == "" {
+			return nil, status.Error(codes.InvalidArgument, "email is required")
+		}
+		_, err = h.queries.GetUserByEmail(ctx, lookup.Email)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "user id or email is required")
 	}
 
-	id, err := uuid.NewV7()
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to create uuid: %v", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &proto.UserExistsResponse{Exists: false}, nil
+		}
+		return nil, status.Errorf(codes.Internal, "failed to look up user: %v", err)
 	}
+	return &proto.UserExistsResponse{Exists: true}, nil
+}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+const maxBatchGetUsers = 100
+
+func (h *GrpcHandler) BatchGetUsers(ctx context.Context, req *proto.BatchGetUsersRequest) (*proto.BatchGetUsersResponse, error) {
+	if len(req.UserIds) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user ids are required")
+	}
+	if len(req.UserIds) > maxBatchGetUsers {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d user ids can be requested at once", maxBatchGetUsers)
+	}
+
+	ids := make([]uuid.UUID, 0, len(req.UserIds))
+	seen := make(map[uuid.UUID]struct{}, len(req.UserIds))
+	for _, raw := range req.UserIds {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid user id %q: %v", raw, err)
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+
+	users, err := h.queries.GetUsersByIds(ctx, ids)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to hash password: %v", err)
+		return nil, status.Errorf(codes.Internal, "failed to get users: %v", err)
 	}
 
-	user, err := h.queries.CreateUser(ctx, repository.CreateUserParams{
-		ID:       id,
-		Name:     req.Name,
-		Email:    req.Email,
-		Address:  req.Address,
-		Password: string(hashedPassword),
-	})
+	res := &proto.BatchGetUsersResponse{
+		Users: make(map[string]*proto.User, len(users)),
+	}
+	for i := range users {
+		res.Users[users[i].ID.String()] = dbUserToProtoUser(&users[i])
+	}
+	// missing ids are echoed back in the form the caller sent them
+	for _, raw := range req.UserIds {
+		id, _ := uuid.Parse(raw)
+		if _, ok := res.Users[id.String()]; !ok {
+			res.MissingIds = append(res.MissingIds, raw)
+		}
+	}
+
+	return res, nil
+}
+
+func (h *GrpcHandler) CreateUser(ctx context.Context, req *proto.CreateUserRequest) (*proto.User, error) {
+	if req.Name == "" || req.Email == "" || req.Password == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "missing field")
+	}
+
+	var address *internal.AddressInput
+	if a := req.InitialAddress; a != nil {
+		address = &internal.AddressInput{
+			Label:      a.Label,
+			Line1:      a.Line1,
+			Line2:      a.Line2,
+			City:       a.City,
+			Region:     a.Region,
+			PostalCode: a.PostalCode,
+			Country:    a.Country,
+		}
+	}
+
+	user, err := h.accountService.CreateUser(ctx, req.Name, req.Email, req.Password, address)
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		if errors.Is(err, internal.ErrUserAlreadyExists) {
 			return nil, status.Errorf(codes.AlreadyExists, "user already exists")
 		}
+		var validationErr *internal.AddressValidationError
+		if errors.As(err, &validationErr) || errors.Is(err, internal.ErrUnsupportedCountry) {
+			return nil, addressErrorToStatus(err)
+		}
 		return nil, status.Errorf(codes.Internal, "failed to create user: %v", err)
 	}
 
@@ -158,6 +243,10 @@ func (h *GrpcHandler) VaildEmailPassword(ctx context.Context, req *proto.VaildEm
 		return &proto.ValidResponse{IsValid: false}, nil
 	}
 
+	if user.SuspendedAt.Valid || user.DeletedAt.Valid {
+		return &proto.ValidResponse{IsValid: false}, nil
+	}
+
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
 	if err != nil {
 		return &proto.ValidResponse{IsValid: false}, nil
@@ -165,13 +254,324 @@ func (h *GrpcHandler) VaildEmailPassword(ctx context.Context, req *proto.VaildEm
 	return &proto.ValidResponse{IsValid: true}, nil
 }
 
+func (h *GrpcHandler) ListAddresses(ctx context.Context, req *proto.ListAddressesRequest) (*proto.ListAddressesResponse, error) {
+	userID, err := parseID("user id", req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses, err := h.accountService.ListAddresses(ctx, userID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list addresses: %v", err)
+	}
+
+	res := &proto.ListAddressesResponse{Addresses: make([]*proto.Address, 0, len(addresses))}
+	for i := range addresses {
+		res.Addresses = append(res.Addresses, dbAddressToProtoAddress(&addresses[i]))
+	}
+	return res, nil
+}
+
+func (h *GrpcHandler) GetAddress(ctx context.Context, req *proto.GetAddressRequest) (*proto.Address, error) {
+	userID, err := parseID("user id", req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	addressID, err := parseID("address id", req.AddressId)
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := h.accountService.GetAddress(ctx, userID, addressID)
+	if err != nil {
+		return nil, addressErrorToStatus(err)
+	}
+	return dbAddressToProtoAddress(&address), nil
+}
+
+func (h *GrpcHandler) CreateAddress(ctx context.Context, req *proto.CreateAddressRequest) (*proto.Address, error) {
+	userID, err := parseID("user id", req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := h.accountService.CreateAddress(ctx, userID, internal.AddressInput{
+		Label:             req.Label,
+		Line1:             req.Line1,
+		Line2:             req.Line2,
+		City:              req.City,
+		Region:            req.Region,
+		PostalCode:        req.PostalCode,
+		Country:           req.Country,
+		IsDefaultShipping: req.IsDefaultShipping,
+		IsDefaultBilling:  req.IsDefaultBilling,
+	})
+	if err != nil {
+		return nil, addressErrorToStatus(err)
+	}
+	return dbAddressToProtoAddress(&address), nil
+}
+
+func (h *GrpcHandler) UpdateAddress(ctx context.Context, req *proto.UpdateAddressRequest) (*proto.Address, error) {
+	userID, err := parseID("user id", req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	addressID, err := parseID("address id", req.AddressId)
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := h.accountService.UpdateAddress(ctx, userID, addressID, internal.AddressInput{
+		Label:             req.Label,
+		Line1:             req.Line1,
+		Line2:             req.Line2,
+		City:              req.City,
+		Region:            req.Region,
+		PostalCode:        req.PostalCode,
+		Country:           req.Country,
+		IsDefaultShipping: req.IsDefaultShipping,
+		IsDefaultBilling:  req.IsDefaultBilling,
+	})
+	if err != nil {
+		return nil, addressErrorToStatus(err)
+	}
+	return dbAddressToProtoAddress(&address), nil
+}
+
+func (h *GrpcHandler) DeleteAddress(ctx context.Context, req *proto.DeleteAddressRequest) (*proto.DeleteAddressResponse, error) {
+	userID, err := parseID("user id", req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	addressID, err := parseID("address id", req.AddressId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.accountService.DeleteAddress(ctx, userID, addressID); err != nil {
+		return nil, addressErrorToStatus(err)
+	}
+	return &proto.DeleteAddressResponse{Success: true}, nil
+}
+
+func (h *GrpcHandler) ListUsers(ctx context.Context, req *proto.ListUsersRequest) (*proto.ListUsersResponse, error) {
+	if _, err := middleware.AuthorizeAdminRPC(ctx, h.authClient, h.admins); err != nil {
+		return nil, err
+	}
+
+	filter := internal.UserFilter{
+		Cursor:      req.Cursor,
+		PageSize:    int(req.PageSize),
+		EmailPrefix: req.EmailPrefix,
+	}
+	if req.CreatedFrom != nil {
+		filter.CreatedFrom = req.CreatedFrom.AsTime()
+	}
+	if req.CreatedTo != nil {
+		filter.CreatedTo = req.CreatedTo.AsTime()
+	}
+
+	users, nextCursor, err := h.accountService.ListUsers(ctx, filter)
+	if err != nil {
+		if errors.Is(err, internal.ErrInvalidCursor) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to list users: %v", err)
+	}
+
+	res := &proto.ListUsersResponse{
+		Users:      make([]*proto.User, 0, len(users)),
+		NextCursor: nextCursor,
+	}
+	for i := range users {
+		res.Users = append(res.Users, dbUserToProtoUser(&users[i]))
+	}
+	return res, nil
+}
+
+func (h *GrpcHandler) SuspendUser(ctx context.Context, req *proto.SuspendUserRequest) (*proto.User, error) {
+	if _, err := middleware.AuthorizeAdminRPC(ctx, h.authClient, h.admins); err != nil {
+		return nil, err
+	}
+
+	userID, err := parseID("user id", req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := h.accountService.SuspendUser(ctx, userID, req.Reason)
+	if err != nil {
+		if errors.Is(err, internal.ErrUserNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to suspend user: %v", err)
+	}
+	return dbUserToProtoUser(&user), nil
+}
+
+func (h *GrpcHandler) UnsuspendUser(ctx context.Context, req *proto.UnsuspendUserRequest) (*proto.User, error) {
+	if _, err := middleware.AuthorizeAdminRPC(ctx, h.authClient, h.admins); err != nil {
+		return nil, err
+	}
+
+	userID, err := parseID("user id", req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := h.accountService.UnsuspendUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, internal.ErrUserNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to unsuspend user: %v", err)
+	}
+	return dbUserToProtoUser(&user), nil
+}
+
+func (h *GrpcHandler) GetPreferences(ctx context.Context, req *proto.GetPreferencesRequest) (*proto.Preferences, error) {
+	userID, err := parseID("user id", req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs, err := h.accountService.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get preferences: %v", err)
+	}
+
+	// structpb only takes []any for lists, going through JSON converts the
+	// typed values for us
+	data, err := json.Marshal(prefs.Values)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode preferences: %v", err)
+	}
+	values := &structpb.Struct{}
+	if err := values.UnmarshalJSON(data); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode preferences: %v", err)
+	}
+
+	res := &proto.Preferences{Values: values, Consents: make([]*proto.Consent, 0, len(prefs.Consents))}
+	for _, c := range prefs.Consents {
+		res.Consents = append(res.Consents, &proto.Consent{
+			Purpose:    c.Purpose,
+			Granted:    c.Granted,
+			RecordedAt: timestamppb.New(c.RecordedAt),
+		})
+	}
+	return res, nil
+}
+
+func (h *GrpcHandler) SendLoginChallenge(ctx context.Context, req *proto.SendLoginChallengeRequest) (*proto.SendLoginChallengeResponse, error) {
+	userID, err := parseID("user id", req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.LoginToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "login token is required")
+	}
+
+	hint, err := h.accountService.SendLoginChallenge(ctx, userID, req.LoginToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, internal.ErrUserNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case errors.Is(err, internal.ErrTwoFactorDisabled), errors.Is(err, internal.ErrPhoneNotVerified):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		case errors.Is(err, internal.ErrOTPResendTooSoon):
+			return nil, status.Error(codes.ResourceExhausted, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to send login challenge: %v", err)
+	}
+
+	return &proto.SendLoginChallengeResponse{PhoneHint: hint}, nil
+}
+
+func (h *GrpcHandler) VerifyLoginChallenge(ctx context.Context, req *proto.VerifyLoginChallengeRequest) (*proto.ValidResponse, error) {
+	userID, err := parseID("user id", req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if req.Code == "" || req.LoginToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "code and login token are required")
+	}
+
+	err = h.accountService.VerifyLoginChallenge(ctx, userID, req.LoginToken, req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, internal.ErrOTPInvalid):
+			return &proto.ValidResponse{IsValid: false}, nil
+		case errors.Is(err, internal.ErrOTPTooManyAttempts):
+			return nil, status.Error(codes.ResourceExhausted, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to verify login challenge: %v", err)
+	}
+
+	return &proto.ValidResponse{IsValid: true}, nil
+}
+
+func parseID(name, value string) (uuid.UUID, error) {
+	if value == "" {
+		return uuid.Nil, status.Errorf(codes.InvalidArgument, "%s is required", name)
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, status.Errorf(codes.InvalidArgument, "invalid %s: %v", name, err)
+	}
+	return id, nil
+}
+
+func addressErrorToStatus(err error) error {
+	var validationErr *internal.AddressValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return status.Error(codes.InvalidArgument, validationErr.Error())
+	case errors.Is(err, internal.ErrUnsupportedCountry):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, internal.ErrAddressNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		return status.Errorf(codes.Internal, "address operation failed: %v", err)
+	}
+}
+
 func dbUserToProtoUser(user *repository.User) *proto.User {
-	return &proto.User{
+	u := &proto.User{
 		UserId:    user.ID.String(),
 		Name:      user.Name,
 		Email:     user.Email,
-		Address:   user.Address,
 		CreatedAt: timestamppb.New(user.CreatedAt),
 		UpdatedAt: timestamppb.New(user.UpdatedAt),
 	}
+	if user.SuspendedAt.Valid {
+		u.SuspendedAt = timestamppb.New(user.SuspendedAt.Time)
+	}
+	if user.Phone.Valid {
+		u.Phone = user.Phone.String
+	}
+	if user.PhoneVerifiedAt.Valid {
+		u.PhoneVerifiedAt = timestamppb.New(user.PhoneVerifiedAt.Time)
+	}
+	u.TwoFactorEnabled = user.TwoFactorEnabled
+	return u
+}
+
+func dbAddressToProtoAddress(address *repository.Address) *proto.Address {
+	return &proto.Address{
+		AddressId:         address.ID.String(),
+		UserId:            address.UserID.String(),
+		Label:             address.Label,
+		Line1:             address.Line1,
+		Line2:             address.Line2,
+		City:              address.City,
+		Region:            address.Region,
+		PostalCode:        address.PostalCode,
+		Country:           address.Country,
+		IsDefaultShipping: address.IsDefaultShipping,
+		IsDefaultBilling:  address.IsDefaultBilling,
+		CreatedAt:         timestamppb.New(address.CreatedAt),
+		UpdatedAt:         timestamppb.New(address.UpdatedAt),
+	}
 }
@@ -3,10 +3,14 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"sync"
+	"time"
 
+	"github.com/lmnzx/slopify/account/internal"
 	"github.com/lmnzx/slopify/account/repository"
 	auth "github.com/lmnzx/slopify/auth/proto"
+	"github.com/lmnzx/slopify/pkg/cookie"
 	"github.com/lmnzx/slopify/pkg/instrumentation"
 	"github.com/lmnzx/slopify/pkg/logger"
 	"github.com/lmnzx/slopify/pkg/middleware"
@@ -15,36 +19,32 @@ import (
 
 	"github.com/fasthttp/router"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog"
 	"github.com/valyala/fasthttp"
 	"github.com/valyala/fasthttp/fasthttpadaptor"
 )
 
 type RestHandler struct {
-	queries *repository.Queries
-	res     *response.ResponseSender
-	log     zerolog.Logger
+	queries        *repository.Queries
+	accountService *internal.AccountService
+	res            *response.ResponseSender
+	log            zerolog.Logger
 }
 
-func NewRestHandler(queries *repository.Queries) *RestHandler {
+func NewRestHandler(dbpool *pgxpool.Pool, accountService *internal.AccountService) *RestHandler {
 	return &RestHandler{
-		queries: queries,
-		log:     logger.GetLogger(),
-		res:     response.NewResponseSender(),
+		queries:        repository.New(dbpool),
+		accountService: accountService,
+		log:            logger.GetLogger(),
+		res:            response.NewResponseSender(),
 	}
 }
 
-func StartRestServer(ctx context.Context, port string, queries *repository.Queries, authClient auth.AuthServiceClient, wg *sync.WaitGroup) {
+func StartRestServer(ctx context.Context, port string, dbpool *pgxpool.Pool, accountService *internal.AccountService, authClient auth.AuthServiceClient, admins []string, wg *sync.WaitGroup) {
 	defer wg.Done()
 
-	r := router.New()
-
-	handler := NewRestHandler(queries)
-	authMw := middleware.AuthMiddleware(authClient, "account")
-
-	r.GET("/health", handler.healthCheck)
-	r.GET("/metrics", fasthttpadaptor.NewFastHTTPHandler(promhttp.Handler()))
-	r.POST("/update", authMw(handler.update))
+	r := newRouter(NewRestHandler(dbpool, accountService), authClient, admins)
 
 	server := &fasthttp.Server{
 		Handler: instrumentation.RequestInstrumentationMiddleware(r.Handler, "account"),
@@ -81,13 +81,43 @@ func StartRestServer(ctx context.Context, port string, queries *repository.Queri
 	}
 }
 
+func newRouter(handler *RestHandler, authClient auth.AuthServiceClient, admins []string) *router.Router {
+	r := router.New()
+
+	authMw := middleware.RequireAuth(authClient, "account")
+	adminMw := middleware.AdminMiddleware(admins)
+
+	r.GET("/health", handler.healthCheck)
+	r.GET("/metrics", fasthttpadaptor.NewFastHTTPHandler(promhttp.Handler()))
+	r.POST("/update", authMw(handler.update))
+	r.GET("/addresses", authMw(handler.listAddresses))
+	r.POST("/addresses", authMw(handler.createAddress))
+	r.GET("/addresses/{id}", authMw(handler.getAddress))
+	r.PUT("/addresses/{id}", authMw(handler.updateAddress))
+	r.DELETE("/addresses/{id}", authMw(handler.deleteAddress))
+	r.DELETE("/account", authMw(handler.deleteAccount))
+	r.POST("/account/restore", authMw(handler.restoreAccount))
+	r.POST("/account/export", authMw(handler.requestExport))
+	r.GET("/account/export/{id}", authMw(handler.getExport))
+	r.GET("/preferences", authMw(handler.getPreferences))
+	r.PATCH("/preferences", authMw(handler.updatePreferences))
+	r.PUT("/phone", authMw(handler.setPhone))
+	r.DELETE("/phone", authMw(handler.removePhone))
+	r.POST("/phone/verify", authMw(handler.verifyPhone))
+	r.PUT("/two-factor", authMw(handler.setTwoFactor))
+	r.GET("/admin/users", authMw(adminMw(handler.listUsers)))
+	r.POST("/admin/users/{id}/suspend", authMw(adminMw(handler.suspendUser)))
+	r.POST("/admin/users/{id}/unsuspend", authMw(adminMw(handler.unsuspendUser)))
+
+	return r
+}
+
 func (h *RestHandler) healthCheck(ctx *fasthttp.RequestCtx) {
 	h.res.SendSuccess(ctx, fasthttp.StatusOK, "all ok")
 }
 
 type UpdateRequest struct {
-	Name    string `json:"name"`
-	Address string `json:"address"`
+	Name string `json:"name"`
 }
 
 func (h *RestHandler) update(ctx *fasthttp.RequestCtx) {
@@ -107,11 +137,11 @@ func (h *RestHandler) update(ctx *fasthttp.RequestCtx) {
 
 	var parsedBody UpdateRequest
 	if err := json.Unmarshal(body, &parsedBody); err != nil {
-		h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid request format, needs name and address to update")
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid request format, needs name to update")
 		return
 	}
 
-	if parsedBody.Name == "" && parsedBody.Address == "" {
+	if parsedBody.Name == "" {
 		h.res.SendError(ctx, fasthttp.StatusBadRequest, "no fields to update")
 		return
 	}
@@ -123,7 +153,7 @@ func (h *RestHandler) update(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
-	updatedUser, err := h.queries.UpdateUser(ctx, repository.UpdateUserParams{ID: id, Name: parsedBody.Name, Address: parsedBody.Address})
+	updatedUser, err := h.accountService.UpdateProfile(ctx, id, parsedBody.Name)
 	if err != nil {
 		h.log.Error().Err(err).Str("user_id", user_id).Msg("could not update the user")
 		h.res.SendError(ctx, fasthttp.StatusInternalServerError, "could not update the user")
@@ -133,6 +163,639 @@ func (h *RestHandler) update(ctx *fasthttp.RequestCtx) {
 	h.res.SendSuccess(ctx, fasthttp.StatusOK, map[string]string{
 		"user_id": updatedUser.ID.String(),
 		"name":    updatedUser.Name,
-		"address": updatedUser.Address,
 	})
 }
+
+type AddressRequest struct {
+	Label             string `json:"label"`
+	Line1             string `json:"line1"`
+	Line2             string `json:"line2"`
+	City              string `json:"city"`
+	Region            string `json:"region"`
+	PostalCode        string `json:"postal_code"`
+	Country           string `json:"country"`
+	IsDefaultShipping bool   `json:"is_default_shipping"`
+	IsDefaultBilling  bool   `json:"is_default_billing"`
+}
+
+func (r *AddressRequest) toInput() internal.AddressInput {
+	return internal.AddressInput{
+		Label:             r.Label,
+		Line1:             r.Line1,
+		Line2:             r.Line2,
+		City:              r.City,
+		Region:            r.Region,
+		PostalCode:        r.PostalCode,
+		Country:           r.Country,
+		IsDefaultShipping: r.IsDefaultShipping,
+		IsDefaultBilling:  r.IsDefaultBilling,
+	}
+}
+
+func (h *RestHandler) listAddresses(ctx *fasthttp.RequestCtx) {
+	userID, ok := h.requireUser(ctx)
+	if !ok {
+		return
+	}
+
+	addresses, err := h.accountService.ListAddresses(ctx, userID)
+	if err != nil {
+		h.log.Error().Err(err).Str("user_id", userID.String()).Msg("could not list addresses")
+		h.res.SendError(ctx, fasthttp.StatusInternalServerError, "could not list addresses")
+		return
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, addresses)
+}
+
+func (h *RestHandler) getAddress(ctx *fasthttp.RequestCtx) {
+	userID, ok := h.requireUser(ctx)
+	if !ok {
+		return
+	}
+	addressID, ok := h.idFromPath(ctx, "invalid address id")
+	if !ok {
+		return
+	}
+
+	address, err := h.accountService.GetAddress(ctx, userID, addressID)
+	if err != nil {
+		h.sendAddressError(ctx, err)
+		return
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, address)
+}
+
+func (h *RestHandler) createAddress(ctx *fasthttp.RequestCtx) {
+	userID, ok := h.requireUser(ctx)
+	if !ok {
+		return
+	}
+
+	var parsedBody AddressRequest
+	if err := json.Unmarshal(ctx.Request.Body(), &parsedBody); err != nil {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	address, err := h.accountService.CreateAddress(ctx, userID, parsedBody.toInput())
+	if err != nil {
+		h.sendAddressError(ctx, err)
+		return
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusCreated, address)
+}
+
+func (h *RestHandler) updateAddress(ctx *fasthttp.RequestCtx) {
+	userID, ok := h.requireUser(ctx)
+	if !ok {
+		return
+	}
+	addressID, ok := h.idFromPath(ctx, "invalid address id")
+	if !ok {
+		return
+	}
+
+	var parsedBody AddressRequest
+	if err := json.Unmarshal(ctx.Request.Body(), &parsedBody); err != nil {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	address, err := h.accountService.UpdateAddress(ctx, userID, addressID, parsedBody.toInput())
+	if err != nil {
+		h.sendAddressError(ctx, err)
+		return
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, address)
+}
+
+func (h *RestHandler) deleteAddress(ctx *fasthttp.RequestCtx) {
+	userID, ok := h.requireUser(ctx)
+	if !ok {
+		return
+	}
+	addressID, ok := h.idFromPath(ctx, "invalid address id")
+	if !ok {
+		return
+	}
+
+	if err := h.accountService.DeleteAddress(ctx, userID, addressID); err != nil {
+		h.sendAddressError(ctx, err)
+		return
+	}
+
+	h.res.SendEmpty(ctx, fasthttp.StatusOK)
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+func (h *RestHandler) deleteAccount(ctx *fasthttp.RequestCtx) {
+	userID, ok := h.requireUser(ctx)
+	if !ok {
+		return
+	}
+
+	var parsedBody DeleteAccountRequest
+	if err := json.Unmarshal(ctx.Request.Body(), &parsedBody); err != nil || parsedBody.Password == "" {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "password is required to delete the account")
+		return
+	}
+
+	deleteAfter, err := h.accountService.RequestDeletion(ctx, userID, parsedBody.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, internal.ErrInvalidPassword):
+			h.res.SendError(ctx, fasthttp.StatusUnauthorized, "invalid password")
+		case errors.Is(err, internal.ErrUserNotFound):
+			h.res.SendError(ctx, fasthttp.StatusNotFound, "user not found")
+		default:
+			h.res.SendError(ctx, fasthttp.StatusInternalServerError, "could not delete the account")
+		}
+		return
+	}
+
+	cookie.Delete(ctx, "access_token")
+	cookie.Delete(ctx, "refresh_token")
+
+	h.res.SendSuccess(ctx, fasthttp.StatusAccepted, map[string]string{
+		"user_id":      userID.String(),
+		"delete_after": deleteAfter.UTC().Format(time.RFC3339),
+	})
+}
+
+func (h *RestHandler) restoreAccount(ctx *fasthttp.RequestCtx) {
+	userID, ok := h.requireUser(ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.accountService.CancelDeletion(ctx, userID); err != nil {
+		if errors.Is(err, internal.ErrNoPendingDeletion) {
+			h.res.SendError(ctx, fasthttp.StatusConflict, err.Error())
+			return
+		}
+		h.res.SendError(ctx, fasthttp.StatusInternalServerError, "could not restore the account")
+		return
+	}
+
+	h.res.SendEmpty(ctx, fasthttp.StatusOK)
+}
+
+func (h *RestHandler) requestExport(ctx *fasthttp.RequestCtx) {
+	userID, ok := h.requireUser(ctx)
+	if !ok {
+		return
+	}
+
+	export, err := h.accountService.RequestDataExport(ctx, userID)
+	if err != nil {
+		h.res.SendError(ctx, fasthttp.StatusInternalServerError, "could not start the data export")
+		return
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusAccepted, map[string]string{
+		"export_id": export.ID.String(),
+		"status":    export.Status,
+	})
+}
+
+type ExportResponse struct {
+	ExportID    string          `json:"export_id"`
+	Status      string          `json:"status"`
+	Error       string          `json:"error,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+	Archive     json.RawMessage `json:"archive,omitempty"`
+}
+
+func (h *RestHandler) getExport(ctx *fasthttp.RequestCtx) {
+	userID, ok := h.requireUser(ctx)
+	if !ok {
+		return
+	}
+	exportID, ok := h.idFromPath(ctx, "invalid export id")
+	if !ok {
+		return
+	}
+
+	export, err := h.accountService.GetDataExport(ctx, userID, exportID)
+	if err != nil {
+		if errors.Is(err, internal.ErrDataExportNotFound) {
+			h.res.SendError(ctx, fasthttp.StatusNotFound, err.Error())
+			return
+		}
+		h.log.Error().Err(err).Str("user_id", userID.String()).Msg("could not get data export")
+		h.res.SendError(ctx, fasthttp.StatusInternalServerError, "could not get the data export")
+		return
+	}
+
+	res := ExportResponse{
+		ExportID:  export.ID.String(),
+		Status:    export.Status,
+		Error:     export.Error,
+		CreatedAt: export.CreatedAt,
+		Archive:   export.Archive,
+	}
+	if export.CompletedAt.Valid {
+		res.CompletedAt = &export.CompletedAt.Time
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, res)
+}
+
+func (h *RestHandler) getPreferences(ctx *fasthttp.RequestCtx) {
+	userID, ok := h.requireUser(ctx)
+	if !ok {
+		return
+	}
+
+	prefs, err := h.accountService.GetPreferences(ctx, userID)
+	if err != nil {
+		h.log.Error().Err(err).Str("user_id", userID.String()).Msg("could not get preferences")
+		h.res.SendError(ctx, fasthttp.StatusInternalServerError, "could not get preferences")
+		return
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, prefs)
+}
+
+// updatePreferences takes a partial object, keys that are left out keep their
+// current value
+func (h *RestHandler) updatePreferences(ctx *fasthttp.RequestCtx) {
+	userID, ok := h.requireUser(ctx)
+	if !ok {
+		return
+	}
+
+	var changes map[string]json.RawMessage
+	if err := json.Unmarshal(ctx.Request.Body(), &changes); err != nil || len(changes) == 0 {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	prefs, err := h.accountService.UpdatePreferences(ctx, userID, changes)
+	if err != nil {
+		var validationErr *internal.PreferenceValidationError
+		if errors.As(err, &validationErr) {
+			h.res.SendError(ctx, fasthttp.StatusBadRequest, validationErr.Error())
+			return
+		}
+		h.res.SendError(ctx, fasthttp.StatusInternalServerError, "could not update preferences")
+		return
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, prefs)
+}
+
+type PhoneView struct {
+	Phone            string     `json:"phone"`
+	VerifiedAt       *time.Time `json:"verified_at,omitempty"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
+}
+
+func newPhoneView(user *repository.User) PhoneView {
+	v := PhoneView{
+		Phone:            user.Phone.String,
+		TwoFactorEnabled: user.TwoFactorEnabled,
+	}
+	if user.PhoneVerifiedAt.Valid {
+		v.VerifiedAt = &user.PhoneVerifiedAt.Time
+	}
+	return v
+}
+
+type PhoneRequest struct {
+	Phone string `json:"phone"`
+	// Password is required while two factor authentication is on, changing
+	// the number turns it off
+	Password string `json:"password"`
+}
+
+// setPhone stores the number unverified and texts it a code to confirm with
+// POST /phone/verify
+func (h *RestHandler) setPhone(ctx *fasthttp.RequestCtx) {
+	userID, ok := h.requireUser(ctx)
+	if !ok {
+		return
+	}
+
+	var parsedBody PhoneRequest
+	if err := json.Unmarshal(ctx.Request.Body(), &parsedBody); err != nil || parsedBody.Phone == "" {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid request format, needs phone")
+		return
+	}
+
+	user, err := h.accountService.SetPhone(ctx, userID, parsedBody.Phone, parsedBody.Password)
+	if err != nil {
+		h.sendPhoneError(ctx, err, "could not update the phone number")
+		return
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusAccepted, newPhoneView(&user))
+}
+
+type VerifyPhoneRequest struct {
+	Code string `json:"code"`
+}
+
+func (h *RestHandler) verifyPhone(ctx *fasthttp.RequestCtx) {
+	userID, ok := h.requireUser(ctx)
+	if !ok {
+		return
+	}
+
+	var parsedBody VerifyPhoneRequest
+	if err := json.Unmarshal(ctx.Request.Body(), &parsedBody); err != nil || parsedBody.Code == "" {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid request format, needs code")
+		return
+	}
+
+	user, err := h.accountService.VerifyPhone(ctx, userID, parsedBody.Code)
+	if err != nil {
+		h.sendPhoneError(ctx, err, "could not verify the phone number")
+		return
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, newPhoneView(&user))
+}
+
+type RemovePhoneRequest struct {
+	// Password is required while two factor authentication is on, removing
+	// the number turns it off
+	Password string `json:"password"`
+}
+
+func (h *RestHandler) removePhone(ctx *fasthttp.RequestCtx) {
+	userID, ok := h.requireUser(ctx)
+	if !ok {
+		return
+	}
+
+	var parsedBody RemovePhoneRequest
+	if body := ctx.Request.Body(); len(body) > 0 {
+		if err := json.Unmarshal(body, &parsedBody); err != nil {
+			h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid request format")
+			return
+		}
+	}
+
+	if _, err := h.accountService.RemovePhone(ctx, userID, parsedBody.Password); err != nil {
+		h.sendPhoneError(ctx, err, "could not remove the phone number")
+		return
+	}
+
+	h.res.SendEmpty(ctx, fasthttp.StatusOK)
+}
+
+type TwoFactorRequest struct {
+	Enabled *bool `json:"enabled"`
+	// Password is required to turn two factor authentication off
+	Password string `json:"password"`
+}
+
+func (h *RestHandler) setTwoFactor(ctx *fasthttp.RequestCtx) {
+	userID, ok := h.requireUser(ctx)
+	if !ok {
+		return
+	}
+
+	var parsedBody TwoFactorRequest
+	if err := json.Unmarshal(ctx.Request.Body(), &parsedBody); err != nil || parsedBody.Enabled == nil {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid request format, needs enabled")
+		return
+	}
+
+	user, err := h.accountService.SetTwoFactor(ctx, userID, *parsedBody.Enabled, parsedBody.Password)
+	if err != nil {
+		h.sendPhoneError(ctx, err, "could not update two factor authentication")
+		return
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, newPhoneView(&user))
+}
+
+func (h *RestHandler) sendPhoneError(ctx *fasthttp.RequestCtx, err error, message string) {
+	switch {
+	case errors.Is(err, internal.ErrInvalidPhone), errors.Is(err, internal.ErrOTPInvalid):
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, err.Error())
+	case errors.Is(err, internal.ErrPhoneNotVerified), errors.Is(err, internal.ErrPhoneTaken):
+		h.res.SendError(ctx, fasthttp.StatusConflict, err.Error())
+	case errors.Is(err, internal.ErrOTPResendTooSoon), errors.Is(err, internal.ErrOTPTooManyAttempts):
+		h.res.SendError(ctx, fasthttp.StatusTooManyRequests, err.Error())
+	case errors.Is(err, internal.ErrInvalidPassword):
+		h.res.SendError(ctx, fasthttp.StatusUnauthorized, "the password is required while two factor authentication is on")
+	case errors.Is(err, internal.ErrUserNotFound):
+		h.res.SendError(ctx, fasthttp.StatusNotFound, err.Error())
+	default:
+		h.log.Error().Err(err).Msg(message)
+		h.res.SendError(ctx, fasthttp.StatusInternalServerError, message)
+	}
+}
+
+type AdminUserView struct {
+	UserID           string     `json:"user_id"`
+	Name             string     `json:"name"`
+	Email            string     `json:"email"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	SuspendedAt      *time.Time `json:"suspended_at,omitempty"`
+	SuspensionReason string     `json:"suspension_reason,omitempty"`
+	DeleteAfter      *time.Time `json:"delete_after,omitempty"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty"`
+}
+
+func newAdminUserView(user *repository.User) AdminUserView {
+	view := AdminUserView{
+		UserID:           user.ID.String(),
+		Name:             user.Name,
+		Email:            user.Email,
+		CreatedAt:        user.CreatedAt,
+		UpdatedAt:        user.UpdatedAt,
+		SuspensionReason: user.SuspensionReason,
+	}
+	if user.SuspendedAt.Valid {
+		view.SuspendedAt = &user.SuspendedAt.Time
+	}
+	if user.DeleteAfter.Valid {
+		view.DeleteAfter = &user.DeleteAfter.Time
+	}
+	if user.DeletedAt.Valid {
+		view.DeletedAt = &user.DeletedAt.Time
+	}
+	return view
+}
+
+type ListUsersResponse struct {
+	Users      []AdminUserView `json:"users"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+func (h *RestHandler) listUsers(ctx *fasthttp.RequestCtx) {
+	args := ctx.QueryArgs()
+
+	filter := internal.UserFilter{
+		Cursor:      string(args.Peek("cursor")),
+		EmailPrefix: string(args.Peek("email_prefix")),
+	}
+
+	if args.Has("limit") {
+		limit, err := args.GetUint("limit")
+		if err != nil {
+			h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid limit")
+			return
+		}
+		filter.PageSize = limit
+	}
+
+	var err error
+	if filter.CreatedFrom, err = parseDateArg(args.Peek("created_from"), false); err != nil {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid created_from, expected RFC 3339 or YYYY-MM-DD")
+		return
+	}
+	if filter.CreatedTo, err = parseDateArg(args.Peek("created_to"), true); err != nil {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid created_to, expected RFC 3339 or YYYY-MM-DD")
+		return
+	}
+
+	users, nextCursor, err := h.accountService.ListUsers(ctx, filter)
+	if err != nil {
+		if errors.Is(err, internal.ErrInvalidCursor) {
+			h.res.SendError(ctx, fasthttp.StatusBadRequest, err.Error())
+			return
+		}
+		h.res.SendError(ctx, fasthttp.StatusInternalServerError, "could not list users")
+		return
+	}
+
+	res := ListUsersResponse{
+		Users:      make([]AdminUserView, 0, len(users)),
+		NextCursor: nextCursor,
+	}
+	for i := range users {
+		res.Users = append(res.Users, newAdminUserView(&users[i]))
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, res)
+}
+
+type SuspendUserRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (h *RestHandler) suspendUser(ctx *fasthttp.RequestCtx) {
+	userID, ok := h.idFromPath(ctx, "invalid user id")
+	if !ok {
+		return
+	}
+
+	var parsedBody SuspendUserRequest
+	if body := ctx.Request.Body(); len(body) > 0 {
+		if err := json.Unmarshal(body, &parsedBody); err != nil {
+			h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid request format")
+			return
+		}
+	}
+
+	user, err := h.accountService.SuspendUser(ctx, userID, parsedBody.Reason)
+	if err != nil {
+		h.sendUserError(ctx, err, "could not suspend the user")
+		return
+	}
+
+	h.log.Info().Str("admin_id", middleware.GetUserIDFromCtx(ctx)).Str("user_id", userID.String()).Msg("user suspended")
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, newAdminUserView(&user))
+}
+
+func (h *RestHandler) unsuspendUser(ctx *fasthttp.RequestCtx) {
+	userID, ok := h.idFromPath(ctx, "invalid user id")
+	if !ok {
+		return
+	}
+
+	user, err := h.accountService.UnsuspendUser(ctx, userID)
+	if err != nil {
+		h.sendUserError(ctx, err, "could not unsuspend the user")
+		return
+	}
+
+	h.log.Info().Str("admin_id", middleware.GetUserIDFromCtx(ctx)).Str("user_id", userID.String()).Msg("user unsuspended")
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, newAdminUserView(&user))
+}
+
+func (h *RestHandler) sendUserError(ctx *fasthttp.RequestCtx, err error, message string) {
+	if errors.Is(err, internal.ErrUserNotFound) {
+		h.res.SendError(ctx, fasthttp.StatusNotFound, err.Error())
+		return
+	}
+	h.res.SendError(ctx, fasthttp.StatusInternalServerError, message)
+}
+
+// parseDateArg reads an RFC 3339 time or a YYYY-MM-DD date. A date is the
+// start of that day, or with endOfDay the start of the next one so an
+// exclusive upper bound still takes in the whole day.
+func parseDateArg(value []byte, endOfDay bool) (time.Time, error) {
+	if len(value) == 0 {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, string(value)); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, string(value))
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// requireUser resolves the logged in user set by the auth middleware and
+// writes the error response itself when there is none
+func (h *RestHandler) requireUser(ctx *fasthttp.RequestCtx) (uuid.UUID, bool) {
+	user_id := middleware.GetUserIDFromCtx(ctx)
+	if user_id == "" {
+		h.res.SendError(ctx, fasthttp.StatusUnauthorized, "user is not logged in")
+		return uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(user_id)
+	if err != nil {
+		h.log.Error().Err(err).Str("user_id", user_id).Msg("could not parse the user_id")
+		h.res.SendError(ctx, fasthttp.StatusInternalServerError, "could not parse the user_id")
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
+
+func (h *RestHandler) idFromPath(ctx *fasthttp.RequestCtx, message string) (uuid.UUID, bool) {
+	raw, _ := ctx.UserValue("id").(string)
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, message)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (h *RestHandler) sendAddressError(ctx *fasthttp.RequestCtx, err error) {
+	var validationErr *internal.AddressValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, validationErr.Error())
+	case errors.Is(err, internal.ErrUnsupportedCountry):
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, err.Error())
+	case errors.Is(err, internal.ErrAddressNotFound):
+		h.res.SendError(ctx, fasthttp.StatusNotFound, err.Error())
+	default:
+		h.log.Error().Err(err).Msg("address operation failed")
+		h.res.SendError(ctx, fasthttp.StatusInternalServerError, "could not process the address")
+	}
+}
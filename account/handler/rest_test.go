@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	auth "github.com/lmnzx/slopify/auth/proto"
+
+	"github.com/valyala/fasthttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testUserID = "0198b3a4-6c1e-7d2a-9f0b-3c5d7e9f1a2b"
+
+// fakeAuthClient answers ValidateSession with a canned response, the other
+// calls are not used by the middleware
+type fakeAuthClient struct {
+	auth.AuthServiceClient
+
+	res   *auth.ValidateSessionResponse
+	err   error
+	calls int
+}
+
+func (f *fakeAuthClient) ValidateSession(ctx context.Context, in *auth.TokenPair, opts ...grpc.CallOption) (*auth.ValidateSessionResponse, error) {
+	f.calls++
+	return f.res, f.err
+}
+
+func validSession(accessToken, refreshToken string) *auth.ValidateSessionResponse {
+	userID := testUserID
+	return &auth.ValidateSessionResponse{
+		Status:    auth.ValidateSessionResponse_VALID,
+		UserId:    &userID,
+		TokenPair: &auth.TokenPair{AccessToken: accessToken, RefreshToken: refreshToken},
+	}
+}
+
+// TestRequireAuth drives an account route through the session middleware,
+// covering how each answer from the auth service reaches the caller
+func TestRequireAuth(t *testing.T) {
+	tests := []struct {
+		name    string
+		cookies map[string]string
+		client  *fakeAuthClient
+		// wantStatus is 400 when the request made it past the middleware,
+		// the handler rejects the empty body
+		wantStatus  int
+		wantCookies map[string]string
+		wantCalls   int
+	}{
+		{
+			name:        "valid session",
+			cookies:     map[string]string{"access_token": "access", "refresh_token": "refresh"},
+			client:      &fakeAuthClient{res: validSession("access", "refresh")},
+			wantStatus:  fasthttp.StatusBadRequest,
+			wantCookies: map[string]string{"access_token": "access"},
+			wantCalls:   1,
+		},
+		{
+			name:        "rotated tokens",
+			cookies:     map[string]string{"refresh_token": "refresh"},
+			client:      &fakeAuthClient{res: validSession("new-access", "new-refresh")},
+			wantStatus:  fasthttp.StatusBadRequest,
+			wantCookies: map[string]string{"access_token": "new-access", "refresh_token": "new-refresh"},
+			wantCalls:   1,
+		},
+		{
+			name:       "expired session",
+			cookies:    map[string]string{"access_token": "access", "refresh_token": "refresh"},
+			client:     &fakeAuthClient{err: status.Error(codes.Unauthenticated, "session expired")},
+			wantStatus: fasthttp.StatusUnauthorized,
+			wantCalls:  1,
+		},
+		{
+			name:       "invalid session",
+			cookies:    map[string]string{"access_token": "forged"},
+			client:     &fakeAuthClient{res: &auth.ValidateSessionResponse{Status: auth.ValidateSessionResponse_INVALID}},
+			wantStatus: fasthttp.StatusUnauthorized,
+			wantCalls:  1,
+		},
+		{
+			name:       "auth service unavailable",
+			cookies:    map[string]string{"access_token": "access", "refresh_token": "refresh"},
+			client:     &fakeAuthClient{err: status.Error(codes.Unavailable, "connection refused")},
+			wantStatus: fasthttp.StatusServiceUnavailable,
+			wantCalls:  1,
+		},
+		{
+			name:       "auth service timed out",
+			cookies:    map[string]string{"access_token": "access"},
+			client:     &fakeAuthClient{err: status.Error(codes.DeadlineExceeded, "context deadline exceeded")},
+			wantStatus: fasthttp.StatusServiceUnavailable,
+			wantCalls:  1,
+		},
+		{
+			name:       "missing cookies",
+			client:     &fakeAuthClient{},
+			wantStatus: fasthttp.StatusUnauthorized,
+			wantCalls:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRouter(NewRestHandler(nil, nil), tt.client, nil)
+
+			var ctx fasthttp.RequestCtx
+			ctx.Request.Header.SetMethod(fasthttp.MethodPost)
+			ctx.Request.SetRequestURI("/update")
+			for name, value := range tt.cookies {
+				ctx.Request.Header.SetCookie(name, value)
+			}
+
+			r.Handler(&ctx)
+
+			if got := ctx.Response.StatusCode(); got != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body %s", got, tt.wantStatus, ctx.Response.Body())
+			}
+			if tt.client.calls != tt.wantCalls {
+				t.Errorf("ValidateSession called %d times, want %d", tt.client.calls, tt.wantCalls)
+			}
+
+			got := responseCookies(&ctx)
+			if len(got) != len(tt.wantCookies) {
+				t.Errorf("cookies = %v, want %v", got, tt.wantCookies)
+			}
+			for name, want := range tt.wantCookies {
+				c, ok := got[name]
+				if !ok {
+					t.Errorf("cookie %s not set", name)
+					continue
+				}
+				if c.Value != want {
+					t.Errorf("cookie %s = %q, want %q", name, c.Value, want)
+				}
+				if !c.HttpOnly {
+					t.Errorf("cookie %s is not HttpOnly", name)
+				}
+				if !c.Expires.After(time.Now()) {
+					t.Errorf("cookie %s expires %v, want a time in the future", name, c.Expires)
+				}
+			}
+		})
+	}
+}
+
+func responseCookies(ctx *fasthttp.RequestCtx) map[string]*http.Cookie {
+	header := http.Header{}
+	ctx.Response.Header.VisitAllCookie(func(key, value []byte) {
+		header.Add("Set-Cookie", string(value))
+	})
+
+	cookies := map[string]*http.Cookie{}
+	for _, c := range (&http.Response{Header: header}).Cookies() {
+		cookies[c.Name] = c
+	}
+	return cookies
+}
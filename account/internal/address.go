@@ -0,0 +1,315 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/lmnzx/slopify/account/repository"
+
+	"github.com/google/uuid"
+)
+
+type AddressInput struct {
+	Label             string
+	Line1             string
+	Line2             string
+	City              string
+	Region            string
+	PostalCode        string
+	Country           string
+	IsDefaultShipping bool
+	IsDefaultBilling  bool
+}
+
+type AddressValidationError struct {
+	Field  string
+	Reason string
+}
+
+func (e *AddressValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Reason)
+}
+
+type countryRule struct {
+	// postalCode is nil for countries that don't use postal codes
+	postalCode     *regexp.Regexp
+	requiresRegion bool
+}
+
+var countryRules = map[string]countryRule{
+	"AE": {},
+	"AU": {postalCode: regexp.MustCompile(`^\d{4}$`), requiresRegion: true},
+	"BR": {postalCode: regexp.MustCompile(`^\d{5}-?\d{3}$`), requiresRegion: true},
+	"CA": {postalCode: regexp.MustCompile(`^[ABCEGHJ-NPRSTVXY]\d[ABCEGHJ-NPRSTV-Z] ?\d[ABCEGHJ-NPRSTV-Z]\d$`), requiresRegion: true},
+	"DE": {postalCode: regexp.MustCompile(`^\d{5}$`)},
+	"ES": {postalCode: regexp.MustCompile(`^\d{5}$`)},
+	"FR": {postalCode: regexp.MustCompile(`^\d{5}$`)},
+	"GB": {postalCode: regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`)},
+	"HK": {},
+	"IE": {postalCode: regexp.MustCompile(`^[AC-FHKNPRTV-Y]\d[\dW] ?[\dAC-FHKNPRTV-Y]{4}$`)},
+	"IN": {postalCode: regexp.MustCompile(`^[1-9]\d{5}$`), requiresRegion: true},
+	"IT": {postalCode: regexp.MustCompile(`^\d{5}$`)},
+	"JP": {postalCode: regexp.MustCompile(`^\d{3}-?\d{4}$`), requiresRegion: true},
+	"NL": {postalCode: regexp.MustCompile(`^[1-9]\d{3} ?[A-Z]{2}$`)},
+	"SE": {postalCode: regexp.MustCompile(`^\d{3} ?\d{2}$`)},
+	"SG": {postalCode: regexp.MustCompile(`^\d{6}$`)},
+	"US": {postalCode: regexp.MustCompile(`^\d{5}(-\d{4})?$`), requiresRegion: true},
+}
+
+var whitespace = regexp.MustCompile(`\s+`)
+
+func (in *AddressInput) normalize() {
+	in.Label = strings.TrimSpace(in.Label)
+	in.Line1 = strings.TrimSpace(in.Line1)
+	in.Line2 = strings.TrimSpace(in.Line2)
+	in.City = strings.TrimSpace(in.City)
+	in.Region = strings.TrimSpace(in.Region)
+	in.Country = strings.ToUpper(strings.TrimSpace(in.Country))
+	in.PostalCode = whitespace.ReplaceAllString(strings.ToUpper(strings.TrimSpace(in.PostalCode)), " ")
+}
+
+func (in *AddressInput) validate() error {
+	if in.Line1 == "" {
+		return &AddressValidationError{Field: "line1", Reason: "is required"}
+	}
+	if in.City == "" {
+		return &AddressValidationError{Field: "city", Reason: "is required"}
+	}
+	if in.Country == "" {
+		return &AddressValidationError{Field: "country", Reason: "is required"}
+	}
+
+	rule, ok := countryRules[in.Country]
+	if !ok {
+		return ErrUnsupportedCountry
+	}
+
+	if rule.requiresRegion && in.Region == "" {
+		return &AddressValidationError{Field: "region", Reason: "is required for " + in.Country}
+	}
+
+	if rule.postalCode == nil {
+		in.PostalCode = ""
+		return nil
+	}
+	if in.PostalCode == "" {
+		return &AddressValidationError{Field: "postal_code", Reason: "is required for " + in.Country}
+	}
+	if !rule.postalCode.MatchString(in.PostalCode) {
+		return &AddressValidationError{Field: "postal_code", Reason: "does not match the format for " + in.Country}
+	}
+
+	return nil
+}
+
+func (s *AccountService) ListAddresses(ctx context.Context, userID uuid.UUID) ([]repository.Address, error) {
+	addresses, err := s.queries.ListAddressesByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if addresses == nil {
+		addresses = []repository.Address{}
+	}
+	return addresses, nil
+}
+
+func (s *AccountService) GetAddress(ctx context.Context, userID, addressID uuid.UUID) (repository.Address, error) {
+	address, err := s.queries.GetAddress(ctx, repository.GetAddressParams{ID: addressID, UserID: userID})
+	if err != nil {
+		if isNoRows(err) {
+			return repository.Address{}, ErrAddressNotFound
+		}
+		return repository.Address{}, err
+	}
+	return address, nil
+}
+
+func (s *AccountService) CreateAddress(ctx context.Context, userID uuid.UUID, in AddressInput) (repository.Address, error) {
+	in.normalize()
+	if err := in.validate(); err != nil {
+		return repository.Address{}, err
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return repository.Address{}, err
+	}
+
+	var address repository.Address
+	err = s.withTx(ctx, func(q *repository.Queries) error {
+		count, err := q.CountAddressesByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		// the first address in the book becomes the default for both
+		if count == 0 {
+			in.IsDefaultShipping = true
+			in.IsDefaultBilling = true
+		}
+
+		if err := clearDefaults(ctx, q, userID, in); err != nil {
+			return err
+		}
+
+		address, err = q.CreateAddress(ctx, repository.CreateAddressParams{
+			ID:                id,
+			UserID:            userID,
+			Label:             in.Label,
+			Line1:             in.Line1,
+			Line2:             in.Line2,
+			City:              in.City,
+			Region:            in.Region,
+			PostalCode:        in.PostalCode,
+			Country:           in.Country,
+			IsDefaultShipping: in.IsDefaultShipping,
+			IsDefaultBilling:  in.IsDefaultBilling,
+		})
+		return err
+	})
+	if err != nil {
+		s.log.Error().Err(err).Str("userId", userID.String()).Msg("failed to create address")
+		return repository.Address{}, err
+	}
+
+	s.RecordAudit(ctx, userID, AuditAddressCreated, map[string]string{"address_id": address.ID.String()})
+
+	return address, nil
+}
+
+// UpdateAddress replaces an address, when it stops being a default the oldest
+// other address in the book takes its place as it does on delete
+func (s *AccountService) UpdateAddress(ctx context.Context, userID, addressID uuid.UUID, in AddressInput) (repository.Address, error) {
+	in.normalize()
+	if err := in.validate(); err != nil {
+		return repository.Address{}, err
+	}
+
+	var address repository.Address
+	err := s.withTx(ctx, func(q *repository.Queries) error {
+		current, err := q.GetAddress(ctx, repository.GetAddressParams{ID: addressID, UserID: userID})
+		if err != nil {
+			return err
+		}
+
+		if err := clearDefaults(ctx, q, userID, in); err != nil {
+			return err
+		}
+
+		address, err = q.UpdateAddress(ctx, repository.UpdateAddressParams{
+			ID:                addressID,
+			UserID:            userID,
+			Label:             in.Label,
+			Line1:             in.Line1,
+			Line2:             in.Line2,
+			City:              in.City,
+			Region:            in.Region,
+			PostalCode:        in.PostalCode,
+			Country:           in.Country,
+			IsDefaultShipping: in.IsDefaultShipping,
+			IsDefaultBilling:  in.IsDefaultBilling,
+		})
+		if err != nil {
+			return err
+		}
+
+		shipping := current.IsDefaultShipping && !in.IsDefaultShipping
+		billing := current.IsDefaultBilling && !in.IsDefaultBilling
+		if !shipping && !billing {
+			return nil
+		}
+		if err := q.PromoteDefaultAddress(ctx, repository.PromoteDefaultAddressParams{
+			UserID:   userID,
+			ExceptID: addressID,
+			Shipping: shipping,
+			Billing:  billing,
+		}); err != nil {
+			return err
+		}
+
+		// the only address in the book stays the default
+		address, err = q.GetAddress(ctx, repository.GetAddressParams{ID: addressID, UserID: userID})
+		return err
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return repository.Address{}, ErrAddressNotFound
+		}
+		s.log.Error().Err(err).Str("userId", userID.String()).Str("addressId", addressID.String()).Msg("failed to update address")
+		return repository.Address{}, err
+	}
+
+	s.RecordAudit(ctx, userID, AuditAddressUpdated, map[string]string{"address_id": address.ID.String()})
+
+	return address, nil
+}
+
+// DeleteAddress removes an address, when it was a default the oldest address
+// left in the book takes its place
+func (s *AccountService) DeleteAddress(ctx context.Context, userID, addressID uuid.UUID) error {
+	err := s.withTx(ctx, func(q *repository.Queries) error {
+		deleted, err := q.DeleteAddress(ctx, repository.DeleteAddressParams{ID: addressID, UserID: userID})
+		if err != nil {
+			return err
+		}
+		if !deleted.IsDefaultShipping && !deleted.IsDefaultBilling {
+			return nil
+		}
+		return q.PromoteDefaultAddress(ctx, repository.PromoteDefaultAddressParams{
+			UserID:   userID,
+			ExceptID: addressID,
+			Shipping: deleted.IsDefaultShipping,
+			Billing:  deleted.IsDefaultBilling,
+		})
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return ErrAddressNotFound
+		}
+		s.log.Error().Err(err).Str("userId", userID.String()).Str("addressId", addressID.String()).Msg("failed to delete address")
+		return err
+	}
+
+	s.RecordAudit(ctx, userID, AuditAddressDeleted, map[string]string{"address_id": addressID.String()})
+
+	return nil
+}
+
+// createFirstAddress adds the address a user signed up with, the book is
+// empty so it is the default for both
+func createFirstAddress(ctx context.Context, q *repository.Queries, userID uuid.UUID, in AddressInput) error {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateAddress(ctx, repository.CreateAddressParams{
+		ID:                id,
+		UserID:            userID,
+		Label:             in.Label,
+		Line1:             in.Line1,
+		Line2:             in.Line2,
+		City:              in.City,
+		Region:            in.Region,
+		PostalCode:        in.PostalCode,
+		Country:           in.Country,
+		IsDefaultShipping: true,
+		IsDefaultBilling:  true,
+	})
+	return err
+}
+
+func clearDefaults(ctx context.Context, q *repository.Queries, userID uuid.UUID, in AddressInput) error {
+	if in.IsDefaultShipping {
+		if err := q.ClearDefaultShippingAddress(ctx, userID); err != nil {
+			return err
+		}
+	}
+	if in.IsDefaultBilling {
+		if err := q.ClearDefaultBillingAddress(ctx, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
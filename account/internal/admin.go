@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/lmnzx/slopify/account/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	DefaultUserPageSize = 50
+	MaxUserPageSize     = 200
+)
+
+type UserFilter struct {
+	// Cursor is the id of the last user of the previous page
+	Cursor      string
+	PageSize    int
+	EmailPrefix string
+	CreatedFrom time.Time
+	// CreatedTo is exclusive
+	CreatedTo time.Time
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ListUsers pages through users ordered by id. Ids are UUIDv7 so the order is
+// also the signup order, and the returned cursor is empty on the last page.
+func (s *AccountService) ListUsers(ctx context.Context, filter UserFilter) ([]repository.User, string, error) {
+	pageSize := filter.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultUserPageSize
+	}
+	if pageSize > MaxUserPageSize {
+		pageSize = MaxUserPageSize
+	}
+
+	params := repository.ListUsersParams{
+		// one extra row tells us whether there is another page
+		PageSize: int32(pageSize + 1),
+	}
+
+	if filter.Cursor != "" {
+		after, err := uuid.Parse(filter.Cursor)
+		if err != nil {
+			return nil, "", ErrInvalidCursor
+		}
+		params.After = uuid.NullUUID{UUID: after, Valid: true}
+	}
+	if filter.EmailPrefix != "" {
+		params.EmailPrefix = pgtype.Text{String: likeEscaper.Replace(filter.EmailPrefix), Valid: true}
+	}
+	if !filter.CreatedFrom.IsZero() {
+		params.CreatedFrom = pgtype.Timestamptz{Time: filter.CreatedFrom, Valid: true}
+	}
+	if !filter.CreatedTo.IsZero() {
+		params.CreatedTo = pgtype.Timestamptz{Time: filter.CreatedTo, Valid: true}
+	}
+
+	users, err := s.queries.ListUsers(ctx, params)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to list users")
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if len(users) > pageSize {
+		users = users[:pageSize]
+		nextCursor = users[pageSize-1].ID.String()
+	}
+	if users == nil {
+		users = []repository.User{}
+	}
+
+	return users, nextCursor, nil
+}
+
+// SuspendUser blocks the user from logging in and ends their current sessions
+func (s *AccountService) SuspendUser(ctx context.Context, userID uuid.UUID, reason string) (repository.User, error) {
+	user, err := s.queries.SuspendUser(ctx, repository.SuspendUserParams{ID: userID, SuspensionReason: reason})
+	if err != nil {
+		if isNoRows(err) {
+			return repository.User{}, ErrUserNotFound
+		}
+		s.log.Error().Err(err).Str("userId", userID.String()).Msg("failed to suspend user")
+		return repository.User{}, err
+	}
+
+	s.revokeSessions(ctx, userID)
+	s.RecordAudit(ctx, userID, AuditAccountSuspended, map[string]string{"reason": reason})
+
+	return user, nil
+}
+
+func (s *AccountService) UnsuspendUser(ctx context.Context, userID uuid.UUID) (repository.User, error) {
+	user, err := s.queries.UnsuspendUser(ctx, userID)
+	if err != nil {
+		if isNoRows(err) {
+			return repository.User{}, ErrUserNotFound
+		}
+		s.log.Error().Err(err).Str("userId", userID.String()).Msg("failed to unsuspend user")
+		return repository.User{}, err
+	}
+
+	s.RecordAudit(ctx, userID, AuditAccountUnsuspended, nil)
+
+	return user, nil
+}
@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/lmnzx/slopify/account/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AuditProfileUpdated      = "profile.updated"
+	AuditAddressCreated      = "address.created"
+	AuditAddressUpdated      = "address.updated"
+	AuditAddressDeleted      = "address.deleted"
+	AuditDeletionRequested   = "account.deletion_requested"
+	AuditDeletionCancelled   = "account.deletion_cancelled"
+	AuditDataExportRequested = "account.export_requested"
+	AuditAccountSuspended    = "account.suspended"
+	AuditAccountUnsuspended  = "account.unsuspended"
+	AuditPreferencesUpdated  = "preferences.updated"
+	AuditPhoneChanged        = "phone.changed"
+	AuditPhoneVerified       = "phone.verified"
+	AuditPhoneRemoved        = "phone.removed"
+	AuditTwoFactorChanged    = "account.two_factor_changed"
+)
+
+// RecordAudit appends an entry to the user's audit trail. Failures are logged
+// and swallowed, the audit trail must never block the action it describes.
+func (s *AccountService) RecordAudit(ctx context.Context, userID uuid.UUID, action string, metadata map[string]string) {
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		s.log.Error().Err(err).Str("action", action).Msg("failed to encode audit metadata")
+		return
+	}
+
+	err = s.queries.CreateAuditEntry(ctx, repository.CreateAuditEntryParams{
+		UserID:   userID,
+		Action:   action,
+		Metadata: data,
+	})
+	if err != nil {
+		s.log.Error().Err(err).Str("userId", userID.String()).Str("action", action).Msg("failed to record audit entry")
+	}
+}
@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/lmnzx/slopify/account/repository"
+	"github.com/lmnzx/slopify/pkg/events"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	EventStream      = "account.events"
+	EventUserCreated = "user.created"
+	EventUserUpdated = "user.updated"
+	EventUserDeleted = "user.deleted"
+)
+
+const (
+	outboxBatchSize = 100
+	// published rows are kept around for a while to help with debugging
+	outboxRetention = 24 * time.Hour
+)
+
+// UserEventPayload is the payload of user.created and user.updated
+type UserEventPayload struct {
+	UserID    string    `json:"user_id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func newUserEventPayload(user *repository.User) UserEventPayload {
+	return UserEventPayload{
+		UserID:    user.ID.String(),
+		Name:      user.Name,
+		Email:     user.Email,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+}
+
+// enqueueEvent writes an event to the outbox. It has to be called with the
+// queries of the transaction making the change, so the event exists if and
+// only if the change was committed.
+func enqueueEvent(ctx context.Context, q *repository.Queries, eventType string, userID uuid.UUID, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return err
+	}
+
+	return q.InsertOutboxEvent(ctx, repository.InsertOutboxEventParams{
+		EventID:     id,
+		EventType:   eventType,
+		AggregateID: userID,
+		Payload:     data,
+	})
+}
+
+// StartOutboxRelay publishes outbox events to the account.events stream every
+// interval until ctx is cancelled. An event is only marked as published after
+// it made it onto the stream, a crash in between publishes it again.
+func (s *AccountService) StartOutboxRelay(ctx context.Context, interval time.Duration, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s.log.Info().Dur("interval", interval).Msg("outbox relay started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Info().Msg("outbox relay stopped")
+			return
+		case <-ticker.C:
+			for {
+				relayed, err := s.relayOutbox(ctx)
+				if err != nil {
+					s.log.Error().Err(err).Msg("failed to relay outbox events")
+					break
+				}
+				if relayed < outboxBatchSize {
+					break
+				}
+			}
+
+			if _, err := s.queries.DeletePublishedOutboxEvents(ctx, pgtype.Timestamptz{Time: time.Now().Add(-outboxRetention), Valid: true}); err != nil {
+				s.log.Error().Err(err).Msg("failed to clean up published outbox events")
+			}
+		}
+	}
+}
+
+func (s *AccountService) relayOutbox(ctx context.Context) (int, error) {
+	var relayed int
+
+	err := s.withTx(ctx, func(q *repository.Queries) error {
+		rows, err := q.ListUnpublishedOutboxEvents(ctx, outboxBatchSize)
+		if err != nil {
+			return err
+		}
+
+		ids := make([]int64, 0, len(rows))
+		for _, row := range rows {
+			err := events.Publish(ctx, s.kv, EventStream, events.Event{
+				ID:          row.EventID.String(),
+				Type:        row.EventType,
+				AggregateID: row.AggregateID.String(),
+				OccurredAt:  row.CreatedAt,
+				Payload:     row.Payload,
+			})
+			if err != nil {
+				// keep what already went out, the rest is retried next tick
+				s.log.Error().Err(err).Str("eventId", row.EventID.String()).Str("type", row.EventType).Msg("failed to publish event")
+				break
+			}
+			ids = append(ids, row.ID)
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := q.MarkOutboxEventsPublished(ctx, ids); err != nil {
+			return err
+		}
+
+		relayed = len(ids)
+		return nil
+	})
+
+	return relayed, err
+}
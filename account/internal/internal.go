@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lmnzx/slopify/account/repository"
+	auth "github.com/lmnzx/slopify/auth/proto"
+	"github.com/lmnzx/slopify/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+	"github.com/valkey-io/valkey-go"
+)
+
+type DeletionPolicy struct {
+	GracePeriod time.Duration
+	// Anonymise keeps the user row with its personal data scrubbed instead of
+	// deleting it, so records in other services still resolve to a user
+	Anonymise bool
+}
+
+type AccountService struct {
+	db         *pgxpool.Pool
+	queries    *repository.Queries
+	kv         valkey.Client
+	authClient auth.AuthServiceClient
+	sms        SMSSender
+	deletion   DeletionPolicy
+	// exportQueued wakes the data export worker when an export is requested
+	exportQueued chan struct{}
+	log          zerolog.Logger
+}
+
+var (
+	ErrAddressNotFound    = errors.New("address not found")
+	ErrUnsupportedCountry = errors.New("country is not supported")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrUserAlreadyExists  = errors.New("user already exists")
+	ErrInvalidPassword    = errors.New("invalid password")
+	ErrNoPendingDeletion  = errors.New("no account deletion is pending")
+	ErrDataExportNotFound = errors.New("data export not found")
+	ErrInvalidCursor      = errors.New("invalid cursor")
+	ErrInvalidPhone       = errors.New("phone number must be in international format, e.g. +14155550123")
+	ErrPhoneNotVerified   = errors.New("phone number is not verified")
+	ErrPhoneTaken         = errors.New("phone number is already verified on another account")
+	ErrTwoFactorDisabled  = errors.New("two factor authentication is not enabled")
+	ErrOTPInvalid         = errors.New("invalid or expired code")
+	ErrOTPTooManyAttempts = errors.New("too many attempts, request a new code")
+	ErrOTPResendTooSoon   = errors.New("a code was sent recently, try again later")
+)
+
+func NewAccountService(db *pgxpool.Pool, kv valkey.Client, authClient auth.AuthServiceClient, sms SMSSender, deletion DeletionPolicy) *AccountService {
+	return &AccountService{
+		db:           db,
+		queries:      repository.New(db),
+		kv:           kv,
+		authClient:   authClient,
+		sms:          sms,
+		deletion:     deletion,
+		exportQueued: make(chan struct{}, 1),
+		log:          logger.GetLogger(),
+	}
+}
+
+func (s *AccountService) withTx(ctx context.Context, fn func(q *repository.Queries) error) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(s.queries.WithTx(tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func isNoRows(err error) bool {
+	return errors.Is(err, pgx.ErrNoRows)
+}
@@ -0,0 +1,418 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/lmnzx/slopify/account/repository"
+	auth "github.com/lmnzx/slopify/auth/proto"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	purgeBatchSize  = 50
+	exportTimeout   = time.Minute
+	exportBatchSize = 10
+	// an export still pending this long after its attempt started was left
+	// behind by a crash and is claimed again
+	exportStaleAfter  = 2 * exportTimeout
+	maxExportAttempts = 3
+)
+
+const (
+	DataExportPending = "pending"
+	DataExportReady   = "ready"
+	DataExportFailed  = "failed"
+)
+
+// RequestDeletion schedules the account for removal once the grace period is
+// over and logs the user out everywhere
+func (s *AccountService) RequestDeletion(ctx context.Context, userID uuid.UUID, password string) (time.Time, error) {
+	user, err := s.queries.GetUserById(ctx, userID)
+	if err != nil {
+		if isNoRows(err) {
+			return time.Time{}, ErrUserNotFound
+		}
+		return time.Time{}, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return time.Time{}, ErrInvalidPassword
+	}
+
+	deleteAfter := time.Now().Add(s.deletion.GracePeriod)
+	_, err = s.queries.ScheduleUserDeletion(ctx, repository.ScheduleUserDeletionParams{
+		ID:          userID,
+		DeleteAfter: pgtype.Timestamptz{Time: deleteAfter, Valid: true},
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return time.Time{}, ErrUserNotFound
+		}
+		s.log.Error().Err(err).Str("userId", userID.String()).Msg("failed to schedule account deletion")
+		return time.Time{}, err
+	}
+
+	s.revokeSessions(ctx, userID)
+	s.RecordAudit(ctx, userID, AuditDeletionRequested, map[string]string{"delete_after": deleteAfter.UTC().Format(time.RFC3339)})
+
+	return deleteAfter, nil
+}
+
+func (s *AccountService) CancelDeletion(ctx context.Context, userID uuid.UUID) error {
+	rows, err := s.queries.CancelUserDeletion(ctx, userID)
+	if err != nil {
+		s.log.Error().Err(err).Str("userId", userID.String()).Msg("failed to cancel account deletion")
+		return err
+	}
+	if rows == 0 {
+		return ErrNoPendingDeletion
+	}
+
+	s.RecordAudit(ctx, userID, AuditDeletionCancelled, nil)
+
+	return nil
+}
+
+// StartDeletionWorker purges accounts whose grace period has run out every
+// interval until ctx is cancelled
+func (s *AccountService) StartDeletionWorker(ctx context.Context, interval time.Duration, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s.log.Info().Dur("interval", interval).Msg("account deletion worker started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Info().Msg("account deletion worker stopped")
+			return
+		case <-ticker.C:
+			for {
+				purged, err := s.purgeDueAccounts(ctx)
+				if err != nil {
+					s.log.Error().Err(err).Msg("failed to purge accounts")
+					break
+				}
+				if purged < purgeBatchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+func (s *AccountService) purgeDueAccounts(ctx context.Context) (int, error) {
+	var purged []uuid.UUID
+
+	err := s.withTx(ctx, func(q *repository.Queries) error {
+		ids, err := q.ListUsersDueForDeletion(ctx, purgeBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			if err := q.DeleteAddressesByUser(ctx, id); err != nil {
+				return err
+			}
+			if err := q.DeleteDataExportsByUser(ctx, id); err != nil {
+				return err
+			}
+			if err := q.DeletePreferencesByUser(ctx, id); err != nil {
+				return err
+			}
+
+			if s.deletion.Anonymise {
+				err = q.AnonymiseUser(ctx, id)
+			} else {
+				if err = q.DeleteAuditEntriesByUser(ctx, id); err != nil {
+					return err
+				}
+				if err = q.DeleteConsentLogByUser(ctx, id); err != nil {
+					return err
+				}
+				err = q.DeleteUser(ctx, id)
+			}
+			if err != nil {
+				return err
+			}
+
+			err = enqueueEvent(ctx, q, EventUserDeleted, id, map[string]any{
+				"user_id":    id.String(),
+				"anonymised": s.deletion.Anonymise,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		purged = ids
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range purged {
+		s.revokeSessions(ctx, id)
+		s.log.Info().Str("userId", id.String()).Bool("anonymised", s.deletion.Anonymise).Msg("account purged")
+	}
+
+	return len(purged), nil
+}
+
+func (s *AccountService) revokeSessions(ctx context.Context, userID uuid.UUID) {
+	_, err := s.authClient.RevokeTokens(ctx, &auth.RevokeTokensRequest{UserId: userID.String()})
+	if err != nil {
+		s.log.Error().Err(err).Str("userId", userID.String()).Msg("failed to revoke sessions")
+	}
+}
+
+type DataExportArchive struct {
+	GeneratedAt  time.Time            `json:"generated_at"`
+	Profile      ExportedProfile      `json:"profile"`
+	Addresses    []repository.Address `json:"addresses"`
+	Preferences  map[string]any       `json:"preferences"`
+	Consents     []Consent            `json:"consents"`
+	Sessions     []ExportedSession    `json:"sessions"`
+	AuditEntries []ExportedAuditEntry `json:"audit_entries"`
+}
+
+type ExportedProfile struct {
+	UserID      string     `json:"user_id"`
+	Name        string     `json:"name"`
+	Email       string     `json:"email"`
+	Phone       string     `json:"phone,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeleteAfter *time.Time `json:"delete_after,omitempty"`
+}
+
+type ExportedSession struct {
+	SessionID string    `json:"session_id"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type ExportedAuditEntry struct {
+	Action    string          `json:"action"`
+	Metadata  json.RawMessage `json:"metadata"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// RequestDataExport queues a data export for StartDataExportWorker to
+// assemble. A pending export is returned as is instead of starting another
+// one.
+func (s *AccountService) RequestDataExport(ctx context.Context, userID uuid.UUID) (repository.DataExport, error) {
+	pending, err := s.queries.GetPendingDataExport(ctx, userID)
+	if err == nil {
+		return pending, nil
+	}
+	if !isNoRows(err) {
+		return repository.DataExport{}, err
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return repository.DataExport{}, err
+	}
+
+	export, err := s.queries.CreateDataExport(ctx, repository.CreateDataExportParams{ID: id, UserID: userID})
+	if err != nil {
+		s.log.Error().Err(err).Str("userId", userID.String()).Msg("failed to create data export")
+		return repository.DataExport{}, err
+	}
+
+	s.RecordAudit(ctx, userID, AuditDataExportRequested, map[string]string{"export_id": id.String()})
+
+	// wake the worker instead of waiting for its next tick
+	select {
+	case s.exportQueued <- struct{}{}:
+	default:
+	}
+
+	return export, nil
+}
+
+func (s *AccountService) GetDataExport(ctx context.Context, userID, exportID uuid.UUID) (repository.DataExport, error) {
+	export, err := s.queries.GetDataExport(ctx, repository.GetDataExportParams{ID: exportID, UserID: userID})
+	if err != nil {
+		if isNoRows(err) {
+			return repository.DataExport{}, ErrDataExportNotFound
+		}
+		return repository.DataExport{}, err
+	}
+	return export, nil
+}
+
+// StartDataExportWorker builds pending data exports as they are requested,
+// and every interval picks up the ones a crashed instance left pending, until
+// ctx is cancelled
+func (s *AccountService) StartDataExportWorker(ctx context.Context, interval time.Duration, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s.log.Info().Dur("interval", interval).Msg("data export worker started")
+
+	for {
+		for {
+			built, err := s.buildPendingDataExports(ctx)
+			if err != nil {
+				s.log.Error().Err(err).Msg("failed to claim data exports")
+				break
+			}
+			if built < exportBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			s.log.Info().Msg("data export worker stopped")
+			return
+		case <-ticker.C:
+		case <-s.exportQueued:
+		}
+	}
+}
+
+func (s *AccountService) buildPendingDataExports(ctx context.Context) (int, error) {
+	exports, err := s.queries.ClaimDataExports(ctx, repository.ClaimDataExportsParams{
+		StaleBefore: pgtype.Timestamptz{Time: time.Now().Add(-exportStaleAfter), Valid: true},
+		MaxResults:  exportBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, export := range exports {
+		if ctx.Err() != nil {
+			// left pending, claimed again once it goes stale
+			break
+		}
+		if export.Attempts > maxExportAttempts {
+			s.failDataExport(ctx, export, errors.New("gave up after too many attempts"))
+			continue
+		}
+		s.buildDataExport(ctx, export)
+	}
+
+	return len(exports), nil
+}
+
+func (s *AccountService) buildDataExport(ctx context.Context, export repository.DataExport) {
+	buildCtx, cancel := context.WithTimeout(ctx, exportTimeout)
+	defer cancel()
+
+	archive, err := s.assembleArchive(buildCtx, export.UserID)
+	if err == nil {
+		var data []byte
+		data, err = json.Marshal(archive)
+		if err == nil {
+			err = s.queries.CompleteDataExport(buildCtx, repository.CompleteDataExportParams{ID: export.ID, Archive: data})
+		}
+	}
+
+	if err != nil {
+		if ctx.Err() != nil {
+			// cut short by the shutdown, left pending to be claimed again
+			return
+		}
+		s.failDataExport(ctx, export, err)
+		return
+	}
+
+	s.log.Info().Str("userId", export.UserID.String()).Str("exportId", export.ID.String()).Msg("data export ready")
+}
+
+func (s *AccountService) failDataExport(ctx context.Context, export repository.DataExport, err error) {
+	s.log.Error().Err(err).Str("userId", export.UserID.String()).Str("exportId", export.ID.String()).Int32("attempts", export.Attempts).Msg("data export failed")
+	if err := s.queries.FailDataExport(ctx, repository.FailDataExportParams{ID: export.ID, Error: err.Error()}); err != nil {
+		s.log.Error().Err(err).Str("exportId", export.ID.String()).Msg("failed to mark data export as failed")
+	}
+}
+
+func (s *AccountService) assembleArchive(ctx context.Context, userID uuid.UUID) (*DataExportArchive, error) {
+	user, err := s.queries.GetUserById(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses, err := s.ListAddresses(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the full history rather than just the current state of each consent
+	consents, err := s.queries.ListConsentHistory(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.authClient.ListSessions(ctx, &auth.ListSessionsRequest{UserId: userID.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.queries.ListAuditEntriesByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &DataExportArchive{
+		GeneratedAt: time.Now().UTC(),
+		Profile: ExportedProfile{
+			UserID:    user.ID.String(),
+			Name:      user.Name,
+			Email:     user.Email,
+			Phone:     user.Phone.String,
+			CreatedAt: user.CreatedAt,
+			UpdatedAt: user.UpdatedAt,
+		},
+		Addresses:    addresses,
+		Preferences:  prefs.Values,
+		Consents:     make([]Consent, 0, len(consents)),
+		Sessions:     make([]ExportedSession, 0, len(sessions.Sessions)),
+		AuditEntries: make([]ExportedAuditEntry, 0, len(entries)),
+	}
+	if user.DeleteAfter.Valid {
+		archive.Profile.DeleteAfter = &user.DeleteAfter.Time
+	}
+
+	for _, c := range consents {
+		archive.Consents = append(archive.Consents, Consent{Purpose: c.Purpose, Granted: c.Granted, RecordedAt: c.RecordedAt})
+	}
+
+	for _, session := range sessions.Sessions {
+		archive.Sessions = append(archive.Sessions, ExportedSession{
+			SessionID: session.SessionId,
+			IssuedAt:  session.IssuedAt.AsTime(),
+			ExpiresAt: session.ExpiresAt.AsTime(),
+		})
+	}
+
+	for _, entry := range entries {
+		archive.AuditEntries = append(archive.AuditEntries, ExportedAuditEntry{
+			Action:    entry.Action,
+			Metadata:  entry.Metadata,
+			CreatedAt: entry.CreatedAt,
+		})
+	}
+
+	return archive, nil
+}
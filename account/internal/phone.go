@@ -0,0 +1,331 @@
+package internal
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lmnzx/slopify/account/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/valkey-io/valkey-go"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	otpDigits         = 6
+	otpTTL            = 5 * time.Minute
+	otpMaxAttempts    = 5
+	otpResendCooldown = time.Minute
+)
+
+const (
+	otpPurposeVerifyPhone = "verify"
+	otpPurposeLogin       = "login"
+)
+
+var (
+	phoneFormattingPattern = regexp.MustCompile(`[\s\-().]`)
+	e164Pattern            = regexp.MustCompile(`^\+[1-9]\d{7,14}$`)
+)
+
+// NormalizePhone strips common formatting and returns the number in E.164.
+// Numbers have to carry their country code, either as +CC or 00CC.
+func NormalizePhone(raw string) (string, error) {
+	phone := phoneFormattingPattern.ReplaceAllString(strings.TrimSpace(raw), "")
+	if strings.HasPrefix(phone, "00") {
+		phone = "+" + phone[2:]
+	}
+	if !e164Pattern.MatchString(phone) {
+		return "", ErrInvalidPhone
+	}
+	return phone, nil
+}
+
+// MaskPhone keeps the last four digits, enough for the user to recognise the
+// number without exposing it
+func MaskPhone(phone string) string {
+	if len(phone) <= 4 {
+		return phone
+	}
+	return strings.Repeat("*", len(phone)-4) + phone[len(phone)-4:]
+}
+
+// SetPhone stores an unverified number and texts it a verification code.
+// Changing the number turns two factor authentication off until the new one
+// is verified, so while it is on the password has to be given.
+func (s *AccountService) SetPhone(ctx context.Context, userID uuid.UUID, raw, password string) (repository.User, error) {
+	phone, err := NormalizePhone(raw)
+	if err != nil {
+		return repository.User{}, err
+	}
+
+	user, err := s.queries.GetUserById(ctx, userID)
+	if err != nil {
+		if isNoRows(err) {
+			return repository.User{}, ErrUserNotFound
+		}
+		return repository.User{}, err
+	}
+	if user.Phone.String == phone && user.PhoneVerifiedAt.Valid {
+		return user, nil
+	}
+	if err := confirmTwoFactorOff(&user, password); err != nil {
+		return repository.User{}, err
+	}
+
+	if err := s.sendOTP(ctx, otpPurposeVerifyPhone, userID, "", phone); err != nil {
+		return repository.User{}, err
+	}
+
+	user, err = s.queries.SetUserPhone(ctx, repository.SetUserPhoneParams{
+		ID:    userID,
+		Phone: pgtype.Text{String: phone, Valid: true},
+	})
+	if err != nil {
+		s.log.Error().Err(err).Str("userId", userID.String()).Msg("failed to set phone")
+		return repository.User{}, err
+	}
+
+	s.RecordAudit(ctx, userID, AuditPhoneChanged, map[string]string{"phone": MaskPhone(phone)})
+
+	return user, nil
+}
+
+func (s *AccountService) VerifyPhone(ctx context.Context, userID uuid.UUID, code string) (repository.User, error) {
+	phone, err := s.checkOTP(ctx, otpPurposeVerifyPhone, userID, "", code)
+	if err != nil {
+		return repository.User{}, err
+	}
+
+	user, err := s.queries.MarkPhoneVerified(ctx, repository.MarkPhoneVerifiedParams{
+		ID:    userID,
+		Phone: pgtype.Text{String: phone, Valid: true},
+	})
+	if err != nil {
+		// the number was changed after the code went out
+		if isNoRows(err) {
+			return repository.User{}, ErrOTPInvalid
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return repository.User{}, ErrPhoneTaken
+		}
+		s.log.Error().Err(err).Str("userId", userID.String()).Msg("failed to verify phone")
+		return repository.User{}, err
+	}
+
+	s.RecordAudit(ctx, userID, AuditPhoneVerified, map[string]string{"phone": MaskPhone(phone)})
+
+	return user, nil
+}
+
+// RemovePhone also turns two factor authentication off, so while it is on the
+// password has to be given
+func (s *AccountService) RemovePhone(ctx context.Context, userID uuid.UUID, password string) (repository.User, error) {
+	user, err := s.queries.GetUserById(ctx, userID)
+	if err != nil {
+		if isNoRows(err) {
+			return repository.User{}, ErrUserNotFound
+		}
+		return repository.User{}, err
+	}
+	if err := confirmTwoFactorOff(&user, password); err != nil {
+		return repository.User{}, err
+	}
+
+	user, err = s.queries.ClearUserPhone(ctx, userID)
+	if err != nil {
+		if isNoRows(err) {
+			return repository.User{}, ErrUserNotFound
+		}
+		s.log.Error().Err(err).Str("userId", userID.String()).Msg("failed to remove phone")
+		return repository.User{}, err
+	}
+
+	s.RecordAudit(ctx, userID, AuditPhoneRemoved, nil)
+
+	return user, nil
+}
+
+// SetTwoFactor turns the SMS second factor on or off. Turning it on needs a
+// verified phone, turning it off needs the password so a stolen session
+// can't strip the second factor.
+func (s *AccountService) SetTwoFactor(ctx context.Context, userID uuid.UUID, enabled bool, password string) (repository.User, error) {
+	if !enabled {
+		user, err := s.queries.GetUserById(ctx, userID)
+		if err != nil {
+			if isNoRows(err) {
+				return repository.User{}, ErrUserNotFound
+			}
+			return repository.User{}, err
+		}
+		if err := confirmTwoFactorOff(&user, password); err != nil {
+			return repository.User{}, err
+		}
+	}
+
+	user, err := s.queries.SetTwoFactorEnabled(ctx, repository.SetTwoFactorEnabledParams{ID: userID, Enabled: enabled})
+	if err != nil {
+		if isNoRows(err) {
+			return repository.User{}, ErrPhoneNotVerified
+		}
+		s.log.Error().Err(err).Str("userId", userID.String()).Msg("failed to update two factor setting")
+		return repository.User{}, err
+	}
+
+	s.RecordAudit(ctx, userID, AuditTwoFactorChanged, map[string]string{"enabled": strconv.FormatBool(enabled)})
+
+	return user, nil
+}
+
+// SendLoginChallenge texts a login code to the user's verified phone and
+// returns the masked number it went to. The code is only good for the
+// pending login identified by loginToken.
+func (s *AccountService) SendLoginChallenge(ctx context.Context, userID uuid.UUID, loginToken string) (string, error) {
+	user, err := s.queries.GetUserById(ctx, userID)
+	if err != nil {
+		if isNoRows(err) {
+			return "", ErrUserNotFound
+		}
+		return "", err
+	}
+	if !user.TwoFactorEnabled {
+		return "", ErrTwoFactorDisabled
+	}
+	if !user.Phone.Valid || !user.PhoneVerifiedAt.Valid {
+		return "", ErrPhoneNotVerified
+	}
+
+	if err := s.sendOTP(ctx, otpPurposeLogin, userID, loginToken, user.Phone.String); err != nil {
+		return "", err
+	}
+
+	return MaskPhone(user.Phone.String), nil
+}
+
+func (s *AccountService) VerifyLoginChallenge(ctx context.Context, userID uuid.UUID, loginToken, code string) error {
+	_, err := s.checkOTP(ctx, otpPurposeLogin, userID, loginToken, code)
+	return err
+}
+
+// confirmTwoFactorOff checks the password before a change that leaves two
+// factor authentication off, a session alone is not enough while it is on
+func confirmTwoFactorOff(user *repository.User, password string) error {
+	if !user.TwoFactorEnabled {
+		return nil
+	}
+	if password == "" || bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		return ErrInvalidPassword
+	}
+	return nil
+}
+
+// otpKey is where a code is kept, scope tells apart codes of the same purpose
+// that are in flight at once, like the logins of a user
+func otpKey(purpose string, userID uuid.UUID, scope string) string {
+	key := "otp:" + purpose + ":" + userID.String()
+	if scope != "" {
+		key += ":" + scope
+	}
+	return key
+}
+
+// otpCooldownKey limits how often a user is texted for a purpose, whatever
+// the scope
+func otpCooldownKey(purpose string, userID uuid.UUID) string {
+	return "otp:" + purpose + ":" + userID.String() + ":cooldown"
+}
+
+func hashOTP(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// sendOTP replaces any outstanding code for the purpose and scope with a
+// fresh one.
+// Only the hash is kept, alongside the number it was sent to.
+func (s *AccountService) sendOTP(ctx context.Context, purpose string, userID uuid.UUID, scope, phone string) error {
+	key := otpKey(purpose, userID, scope)
+
+	err := s.kv.Do(ctx, s.kv.B().Set().Key(otpCooldownKey(purpose, userID)).Value("1").Nx().Ex(otpResendCooldown).Build()).Error()
+	if valkey.IsValkeyNil(err) {
+		return ErrOTPResendTooSoon
+	}
+	if err != nil {
+		return err
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return err
+	}
+	code := fmt.Sprintf("%0*d", otpDigits, n.Int64())
+
+	for _, res := range s.kv.DoMulti(ctx,
+		s.kv.B().Del().Key(key).Build(),
+		s.kv.B().Hset().Key(key).FieldValue().
+			FieldValue("code", hashOTP(code)).
+			FieldValue("phone", phone).
+			FieldValue("attempts", "0").
+			Build(),
+		s.kv.B().Expire().Key(key).Seconds(int64(otpTTL.Seconds())).Build(),
+	) {
+		if err := res.Error(); err != nil {
+			s.log.Error().Err(err).Str("userId", userID.String()).Msg("failed to store otp")
+			return err
+		}
+	}
+
+	message := fmt.Sprintf("Your slopify code is %s. It expires in %d minutes.", code, int(otpTTL.Minutes()))
+	if err := s.sms.Send(ctx, phone, message); err != nil {
+		s.log.Error().Err(err).Str("userId", userID.String()).Msg("failed to send sms")
+		return err
+	}
+
+	return nil
+}
+
+// checkOTP returns the number the code was sent to. Every check counts as an
+// attempt, and the code is burnt once it matches or the attempts run out.
+func (s *AccountService) checkOTP(ctx context.Context, purpose string, userID uuid.UUID, scope, code string) (string, error) {
+	key := otpKey(purpose, userID, scope)
+
+	attempts, err := s.kv.Do(ctx, s.kv.B().Hincrby().Key(key).Field("attempts").Increment(1).Build()).AsInt64()
+	if err != nil {
+		return "", err
+	}
+	// HINCRBY creates the hash when the code has already expired
+	stored, err := s.kv.Do(ctx, s.kv.B().Hgetall().Key(key).Build()).AsStrMap()
+	if err != nil {
+		return "", err
+	}
+	if stored["code"] == "" {
+		s.kv.Do(ctx, s.kv.B().Del().Key(key).Build())
+		return "", ErrOTPInvalid
+	}
+
+	if attempts > otpMaxAttempts {
+		s.kv.Do(ctx, s.kv.B().Del().Key(key).Build())
+		return "", ErrOTPTooManyAttempts
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashOTP(strings.TrimSpace(code))), []byte(stored["code"])) != 1 {
+		return "", ErrOTPInvalid
+	}
+
+	s.kv.Do(ctx, s.kv.B().Del().Key(key).Build())
+
+	return stored["phone"], nil
+}
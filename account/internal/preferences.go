@@ -0,0 +1,230 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/lmnzx/slopify/account/repository"
+
+	"github.com/google/uuid"
+)
+
+type PreferenceValidationError struct {
+	Key    string
+	Reason string
+}
+
+func (e *PreferenceValidationError) Error() string {
+	return fmt.Sprintf("invalid preference %s: %s", e.Key, e.Reason)
+}
+
+// preferenceSpec describes one allowed preference key. decode checks a raw
+// JSON value and returns it in canonical form.
+type preferenceSpec struct {
+	Default any
+	decode  func(raw json.RawMessage) (any, error)
+	// consent marks keys that record a legal consent, changes to them are
+	// written to the consent log
+	consent bool
+}
+
+var (
+	supportedCurrencies   = []string{"AUD", "BRL", "CAD", "EUR", "GBP", "INR", "JPY", "SEK", "SGD", "USD"}
+	notificationChannels  = []string{"email", "sms", "push"}
+	localePattern         = regexp.MustCompile(`^[a-z]{2}(-[A-Z]{2})?$`)
+	errExpectedBool       = errors.New("expected true or false")
+	errExpectedString     = errors.New("expected a string")
+	errExpectedStringList = errors.New("expected a list of strings")
+)
+
+var preferenceRegistry = map[string]preferenceSpec{
+	"currency": {
+		Default: "USD",
+		decode:  decodeEnum(supportedCurrencies),
+	},
+	"locale": {
+		Default: "en-US",
+		decode: func(raw json.RawMessage) (any, error) {
+			var v string
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return nil, errExpectedString
+			}
+			if !localePattern.MatchString(v) {
+				return nil, errors.New("expected a locale such as en or en-US")
+			}
+			return v, nil
+		},
+	},
+	"marketing_emails": {
+		Default: false,
+		decode:  decodeBool,
+		consent: true,
+	},
+	"marketing_sms": {
+		Default: false,
+		decode:  decodeBool,
+		consent: true,
+	},
+	"order_updates": {
+		Default: true,
+		decode:  decodeBool,
+	},
+	"notification_channels": {
+		Default: []string{"email"},
+		decode: func(raw json.RawMessage) (any, error) {
+			var v []string
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return nil, errExpectedStringList
+			}
+			channels := make([]string, 0, len(v))
+			for _, c := range v {
+				if !slices.Contains(notificationChannels, c) {
+					return nil, fmt.Errorf("unknown channel %q, expected one of %v", c, notificationChannels)
+				}
+				if !slices.Contains(channels, c) {
+					channels = append(channels, c)
+				}
+			}
+			sort.Strings(channels)
+			return channels, nil
+		},
+	},
+}
+
+func decodeBool(raw json.RawMessage) (any, error) {
+	var v bool
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, errExpectedBool
+	}
+	return v, nil
+}
+
+func decodeEnum(allowed []string) func(raw json.RawMessage) (any, error) {
+	return func(raw json.RawMessage) (any, error) {
+		var v string
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, errExpectedString
+		}
+		if !slices.Contains(allowed, v) {
+			return nil, fmt.Errorf("expected one of %v", allowed)
+		}
+		return v, nil
+	}
+}
+
+type Consent struct {
+	Purpose    string    `json:"purpose"`
+	Granted    bool      `json:"granted"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+type Preferences struct {
+	Values   map[string]any `json:"values"`
+	Consents []Consent      `json:"consents"`
+}
+
+// GetPreferences returns every registered key, falling back to its default
+// when the user never set it
+func (s *AccountService) GetPreferences(ctx context.Context, userID uuid.UUID) (*Preferences, error) {
+	return s.loadPreferences(ctx, s.queries, userID)
+}
+
+func (s *AccountService) loadPreferences(ctx context.Context, q *repository.Queries, userID uuid.UUID) (*Preferences, error) {
+	stored, err := q.ListPreferencesByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs := &Preferences{Values: make(map[string]any, len(preferenceRegistry))}
+	for key, spec := range preferenceRegistry {
+		prefs.Values[key] = spec.Default
+	}
+	for _, p := range stored {
+		spec, ok := preferenceRegistry[p.Key]
+		if !ok {
+			// a key that has since been removed from the registry
+			continue
+		}
+		v, err := spec.decode(p.Value)
+		if err != nil {
+			s.log.Warn().Err(err).Str("userId", userID.String()).Str("key", p.Key).Msg("stored preference no longer valid, using default")
+			continue
+		}
+		prefs.Values[p.Key] = v
+	}
+
+	consents, err := q.ListCurrentConsents(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	prefs.Consents = make([]Consent, 0, len(consents))
+	for _, c := range consents {
+		prefs.Consents = append(prefs.Consents, Consent{Purpose: c.Purpose, Granted: c.Granted, RecordedAt: c.RecordedAt})
+	}
+
+	return prefs, nil
+}
+
+// UpdatePreferences validates and stores a partial set of preferences. Either
+// all of them are written or none. Updates for the same user are serialised,
+// so a consent change is compared against the value it actually replaces.
+func (s *AccountService) UpdatePreferences(ctx context.Context, userID uuid.UUID, changes map[string]json.RawMessage) (*Preferences, error) {
+	decoded := make(map[string]any, len(changes))
+	for key, raw := range changes {
+		spec, ok := preferenceRegistry[key]
+		if !ok {
+			return nil, &PreferenceValidationError{Key: key, Reason: "unknown preference"}
+		}
+		v, err := spec.decode(raw)
+		if err != nil {
+			return nil, &PreferenceValidationError{Key: key, Reason: err.Error()}
+		}
+		decoded[key] = v
+	}
+
+	err := s.withTx(ctx, func(q *repository.Queries) error {
+		if err := q.LockUserPreferences(ctx, userID); err != nil {
+			return err
+		}
+		current, err := s.loadPreferences(ctx, q, userID)
+		if err != nil {
+			return err
+		}
+
+		for key, v := range decoded {
+			data, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			if err := q.UpsertPreference(ctx, repository.UpsertPreferenceParams{UserID: userID, Key: key, Value: data}); err != nil {
+				return err
+			}
+
+			if granted, ok := v.(bool); ok && preferenceRegistry[key].consent && current.Values[key] != granted {
+				if err := q.RecordConsent(ctx, repository.RecordConsentParams{UserID: userID, Purpose: key, Granted: granted}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.log.Error().Err(err).Str("userId", userID.String()).Msg("failed to update preferences")
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(decoded))
+	for key := range decoded {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	s.RecordAudit(ctx, userID, AuditPreferencesUpdated, map[string]string{"keys": fmt.Sprint(keys)})
+
+	return s.GetPreferences(ctx, userID)
+}
@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lmnzx/slopify/pkg/logger"
+
+	"github.com/rs/zerolog"
+)
+
+// SMSSender delivers a text message to an E.164 number
+type SMSSender interface {
+	Send(ctx context.Context, to, message string) error
+}
+
+// LogSMSSender writes messages to the service log instead of sending them.
+// Only meant for local development.
+type LogSMSSender struct {
+	log zerolog.Logger
+}
+
+func NewLogSMSSender() *LogSMSSender {
+	return &LogSMSSender{log: logger.GetLogger()}
+}
+
+func (s *LogSMSSender) Send(ctx context.Context, to, message string) error {
+	s.log.Info().Str("to", to).Str("message", message).Msg("sms")
+	return nil
+}
+
+// FileSMSSender appends messages to a file, one per line, so tests and local
+// setups can pick the codes up without a real provider
+type FileSMSSender struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFileSMSSender(path string) (*FileSMSSender, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSMSSender{file: f}, nil
+}
+
+func (s *FileSMSSender) Send(ctx context.Context, to, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := fmt.Fprintf(s.file, "%s\t%s\t%s\n", time.Now().UTC().Format(time.RFC3339), to, message)
+	return err
+}
+
+func (s *FileSMSSender) Close() error {
+	return s.file.Close()
+}
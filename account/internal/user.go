@@ -0,0 +1,85 @@
+package internal
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lmnzx/slopify/account/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// CreateUser signs a user up, address is optional and becomes their default
+// shipping and billing address
+func (s *AccountService) CreateUser(ctx context.Context, name, email, password string, address *AddressInput) (repository.User, error) {
+	if address != nil {
+		address.normalize()
+		if err := address.validate(); err != nil {
+			return repository.User{}, err
+		}
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return repository.User{}, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return repository.User{}, err
+	}
+
+	var user repository.User
+	err = s.withTx(ctx, func(q *repository.Queries) error {
+		user, err = q.CreateUser(ctx, repository.CreateUserParams{
+			ID:       id,
+			Name:     name,
+			Email:    email,
+			Password: string(hashedPassword),
+		})
+		if err != nil {
+			return err
+		}
+		if address != nil {
+			if err := createFirstAddress(ctx, q, user.ID, *address); err != nil {
+				return err
+			}
+		}
+		return enqueueEvent(ctx, q, EventUserCreated, user.ID, newUserEventPayload(&user))
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return repository.User{}, ErrUserAlreadyExists
+		}
+		s.log.Error().Err(err).Str("email", email).Msg("failed to create user")
+		return repository.User{}, err
+	}
+
+	return user, nil
+}
+
+func (s *AccountService) UpdateProfile(ctx context.Context, userID uuid.UUID, name string) (repository.User, error) {
+	var user repository.User
+	err := s.withTx(ctx, func(q *repository.Queries) error {
+		var err error
+		user, err = q.UpdateUser(ctx, repository.UpdateUserParams{ID: userID, Name: name})
+		if err != nil {
+			return err
+		}
+		return enqueueEvent(ctx, q, EventUserUpdated, user.ID, newUserEventPayload(&user))
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return repository.User{}, ErrUserNotFound
+		}
+		s.log.Error().Err(err).Str("userId", userID.String()).Msg("failed to update user")
+		return repository.User{}, err
+	}
+
+	s.RecordAudit(ctx, userID, AuditProfileUpdated, nil)
+
+	return user, nil
+}
@@ -0,0 +1,19 @@
+ALTER TABLE users ADD COLUMN address TEXT NOT NULL DEFAULT '';
+
+UPDATE users u
+SET address = concat_ws(', ',
+    NULLIF(a.line1, ''),
+    NULLIF(a.line2, ''),
+    NULLIF(a.city, ''),
+    NULLIF(a.region, ''),
+    NULLIF(a.postal_code, ''),
+    NULLIF(a.country, ''))
+FROM addresses a
+WHERE a.user_id = u.id AND a.is_default_shipping;
+
+ALTER TABLE users ALTER COLUMN address DROP DEFAULT;
+
+DROP INDEX IF EXISTS idx_addresses_default_billing;
+DROP INDEX IF EXISTS idx_addresses_default_shipping;
+DROP INDEX IF EXISTS idx_addresses_user_id;
+DROP TABLE IF EXISTS addresses;
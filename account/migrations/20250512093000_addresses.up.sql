@@ -0,0 +1,38 @@
+CREATE TABLE addresses (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    label TEXT NOT NULL DEFAULT '',
+    line1 TEXT NOT NULL,
+    line2 TEXT NOT NULL DEFAULT '',
+    city TEXT NOT NULL DEFAULT '',
+    region TEXT NOT NULL DEFAULT '',
+    postal_code TEXT NOT NULL DEFAULT '',
+    country TEXT NOT NULL DEFAULT '',
+    is_default_shipping BOOLEAN NOT NULL DEFAULT FALSE,
+    is_default_billing BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_addresses_user_id ON addresses(user_id);
+CREATE UNIQUE INDEX idx_addresses_default_shipping ON addresses(user_id) WHERE is_default_shipping;
+CREATE UNIQUE INDEX idx_addresses_default_billing ON addresses(user_id) WHERE is_default_billing;
+
+-- the old free-text address can't be split reliably, so it is kept verbatim in
+-- line1 and the user is asked to complete the rest on the next edit. The ids
+-- are v7 like the ones the service makes: a random v4 with the millisecond
+-- timestamp written over its first 48 bits and the version bits set to 7.
+INSERT INTO addresses (id, user_id, label, line1, is_default_shipping, is_default_billing)
+SELECT
+    encode(
+        set_bit(set_bit(
+            overlay(uuid_send(gen_random_uuid())
+                placing substring(int8send(floor(extract(epoch FROM clock_timestamp()) * 1000)::bigint) FROM 3)
+                FROM 1 FOR 6),
+            52, 1), 53, 1),
+        'hex')::uuid,
+    id, 'migrated', address, TRUE, TRUE
+FROM users
+WHERE address <> '';
+
+ALTER TABLE users DROP COLUMN address;
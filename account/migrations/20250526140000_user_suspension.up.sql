@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN suspended_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN suspension_reason TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_users_created_at ON users(created_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_consent_log_user_id;
+DROP TABLE IF EXISTS consent_log;
+DROP TABLE IF EXISTS user_preferences;
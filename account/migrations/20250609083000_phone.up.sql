@@ -0,0 +1,6 @@
+ALTER TABLE users ADD COLUMN phone TEXT;
+ALTER TABLE users ADD COLUMN phone_verified_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN two_factor_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- a number can only be verified on one account, unverified claims may overlap
+CREATE UNIQUE INDEX idx_users_verified_phone ON users(phone) WHERE phone_verified_at IS NOT NULL;
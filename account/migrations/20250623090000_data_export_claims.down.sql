@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_data_exports_pending;
+
+ALTER TABLE data_exports DROP COLUMN attempts;
+ALTER TABLE data_exports DROP COLUMN started_at;
@@ -0,0 +1,6 @@
+-- exports are built by a worker that claims pending rows, started_at is when
+-- the current attempt began so rows left behind by a crash can be reclaimed
+ALTER TABLE data_exports ADD COLUMN started_at TIMESTAMPTZ;
+ALTER TABLE data_exports ADD COLUMN attempts INT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_data_exports_pending ON data_exports(created_at) WHERE status = 'pending';
@@ -1,15 +1,15 @@
 -- name: CreateUser :one
 INSERT INTO users (
-    id, name, email, address, password
+    id, name, email, password
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4
 )
 RETURNING *;
 
 -- name: UpdateUser :one
 UPDATE users
-SET name = $1, address = $2
-WHERE id = $3
+SET name = $1
+WHERE id = $2
 RETURNING *;
 
 -- name: GetUserByEmail :one
@@ -19,3 +19,285 @@ WHERE email = $1;
 -- name: GetUserById :one
 SELECT * FROM users
 WHERE id = $1;
+
+-- name: GetUsersByIds :many
+SELECT * FROM users
+WHERE id = ANY(sqlc.arg('ids')::uuid[]);
+
+-- name: ListAddressesByUser :many
+SELECT * FROM addresses
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: GetAddress :one
+SELECT * FROM addresses
+WHERE id = $1 AND user_id = $2;
+
+-- name: CountAddressesByUser :one
+SELECT COUNT(*) FROM addresses
+WHERE user_id = $1;
+
+-- name: CreateAddress :one
+INSERT INTO addresses (
+    id, user_id, label, line1, line2, city, region, postal_code, country, is_default_shipping, is_default_billing
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+)
+RETURNING *;
+
+-- name: UpdateAddress :one
+UPDATE addresses
+SET label = $3,
+    line1 = $4,
+    line2 = $5,
+    city = $6,
+    region = $7,
+    postal_code = $8,
+    country = $9,
+    is_default_shipping = $10,
+    is_default_billing = $11,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: DeleteAddress :one
+DELETE FROM addresses
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: PromoteDefaultAddress :exec
+UPDATE addresses
+SET is_default_shipping = is_default_shipping OR sqlc.arg('shipping')::bool,
+    is_default_billing = is_default_billing OR sqlc.arg('billing')::bool,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = (
+    SELECT a.id FROM addresses a
+    WHERE a.user_id = sqlc.arg('user_id')
+    ORDER BY a.id = sqlc.arg('except_id'), a.created_at, a.id
+    LIMIT 1
+);
+
+-- name: ClearDefaultShippingAddress :exec
+UPDATE addresses
+SET is_default_shipping = FALSE
+WHERE user_id = $1 AND is_default_shipping;
+
+-- name: ClearDefaultBillingAddress :exec
+UPDATE addresses
+SET is_default_billing = FALSE
+WHERE user_id = $1 AND is_default_billing;
+
+-- name: ScheduleUserDeletion :one
+UPDATE users
+SET delete_after = $2
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: CancelUserDeletion :execrows
+UPDATE users
+SET delete_after = NULL
+WHERE id = $1 AND deleted_at IS NULL AND delete_after IS NOT NULL;
+
+-- name: ListUsersDueForDeletion :many
+SELECT id FROM users
+WHERE delete_after <= CURRENT_TIMESTAMP AND deleted_at IS NULL
+ORDER BY delete_after
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: AnonymiseUser :exec
+UPDATE users
+SET name = 'deleted user',
+    email = 'deleted+' || id::text || '@invalid',
+    password = '',
+    phone = NULL,
+    phone_verified_at = NULL,
+    two_factor_enabled = FALSE,
+    delete_after = NULL,
+    deleted_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: DeleteUser :exec
+DELETE FROM users
+WHERE id = $1;
+
+-- name: DeleteAddressesByUser :exec
+DELETE FROM addresses
+WHERE user_id = $1;
+
+-- name: CreateAuditEntry :exec
+INSERT INTO audit_log (
+    user_id, action, metadata
+) VALUES (
+    $1, $2, $3
+);
+
+-- name: ListAuditEntriesByUser :many
+SELECT * FROM audit_log
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: DeleteAuditEntriesByUser :exec
+DELETE FROM audit_log
+WHERE user_id = $1;
+
+-- name: CreateDataExport :one
+INSERT INTO data_exports (
+    id, user_id
+) VALUES (
+    $1, $2
+)
+RETURNING *;
+
+-- name: GetDataExport :one
+SELECT * FROM data_exports
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetPendingDataExport :one
+SELECT * FROM data_exports
+WHERE user_id = $1 AND status = 'pending'
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: ClaimDataExports :many
+UPDATE data_exports
+SET started_at = CURRENT_TIMESTAMP,
+    attempts = attempts + 1
+WHERE id IN (
+    SELECT d.id FROM data_exports d
+    WHERE d.status = 'pending'
+      AND (d.started_at IS NULL OR d.started_at < sqlc.arg('stale_before'))
+    ORDER BY d.created_at
+    LIMIT sqlc.arg('max_results')
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: CompleteDataExport :exec
+UPDATE data_exports
+SET status = 'ready', archive = $2, completed_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: FailDataExport :exec
+UPDATE data_exports
+SET status = 'failed', error = $2, completed_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: DeleteDataExportsByUser :exec
+DELETE FROM data_exports
+WHERE user_id = $1;
+
+-- name: ListUsers :many
+SELECT * FROM users
+WHERE (sqlc.narg('after')::uuid IS NULL OR id > sqlc.narg('after')::uuid)
+  AND (sqlc.narg('email_prefix')::text IS NULL OR email ILIKE sqlc.narg('email_prefix')::text || '%')
+  AND (sqlc.narg('created_from')::timestamptz IS NULL OR created_at >= sqlc.narg('created_from')::timestamptz)
+  AND (sqlc.narg('created_to')::timestamptz IS NULL OR created_at < sqlc.narg('created_to')::timestamptz)
+ORDER BY id
+LIMIT sqlc.arg('page_size');
+
+-- name: SuspendUser :one
+UPDATE users
+SET suspended_at = CURRENT_TIMESTAMP, suspension_reason = $2
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: UnsuspendUser :one
+UPDATE users
+SET suspended_at = NULL, suspension_reason = ''
+WHERE id = $1
+RETURNING *;
+
+-- name: ListPreferencesByUser :many
+SELECT * FROM user_preferences
+WHERE user_id = $1;
+
+-- name: LockUserPreferences :exec
+-- the user row stands in for their preferences, rows that don't exist yet
+-- can't be locked
+SELECT id FROM users
+WHERE id = $1
+FOR NO KEY UPDATE;
+
+-- name: UpsertPreference :exec
+INSERT INTO user_preferences (
+    user_id, key, value
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (user_id, key) DO UPDATE
+SET value = EXCLUDED.value, updated_at = CURRENT_TIMESTAMP;
+
+-- name: DeletePreferencesByUser :exec
+DELETE FROM user_preferences
+WHERE user_id = $1;
+
+-- name: RecordConsent :exec
+INSERT INTO consent_log (
+    user_id, purpose, granted
+) VALUES (
+    $1, $2, $3
+);
+
+-- name: ListCurrentConsents :many
+SELECT DISTINCT ON (purpose) * FROM consent_log
+WHERE user_id = $1
+ORDER BY purpose, recorded_at DESC;
+
+-- name: ListConsentHistory :many
+SELECT * FROM consent_log
+WHERE user_id = $1
+ORDER BY recorded_at;
+
+-- name: DeleteConsentLogByUser :exec
+DELETE FROM consent_log
+WHERE user_id = $1;
+
+-- name: SetUserPhone :one
+UPDATE users
+SET phone = $2, phone_verified_at = NULL, two_factor_enabled = FALSE, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: MarkPhoneVerified :one
+UPDATE users
+SET phone_verified_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND phone = $2
+RETURNING *;
+
+-- name: ClearUserPhone :one
+UPDATE users
+SET phone = NULL, phone_verified_at = NULL, two_factor_enabled = FALSE, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: SetTwoFactorEnabled :one
+UPDATE users
+SET two_factor_enabled = sqlc.arg(enabled)::boolean, updated_at = CURRENT_TIMESTAMP
+-- only a verified phone can be used as a second factor
+WHERE id = sqlc.arg(id) AND (NOT sqlc.arg(enabled)::boolean OR phone_verified_at IS NOT NULL)
+RETURNING *;
+
+-- name: InsertOutboxEvent :exec
+INSERT INTO outbox (
+    event_id, event_type, aggregate_id, payload
+) VALUES (
+    $1, $2, $3, $4
+);
+
+-- name: ListUnpublishedOutboxEvents :many
+SELECT * FROM outbox
+WHERE published_at IS NULL
+ORDER BY id
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkOutboxEventsPublished :exec
+UPDATE outbox
+SET published_at = CURRENT_TIMESTAMP
+WHERE id = ANY(sqlc.arg('ids')::bigint[]);
+
+-- name: DeletePublishedOutboxEvents :execrows
+DELETE FROM outbox
+WHERE published_at < $1;
@@ -8,14 +8,82 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
-type User struct {
-	ID        uuid.UUID `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Address   string    `json:"address"`
-	Password  string    `json:"password"`
+type Address struct {
+	ID                uuid.UUID `json:"id"`
+	UserID            uuid.UUID `json:"user_id"`
+	Label             string    `json:"label"`
+	Line1             string    `json:"line1"`
+	Line2             string    `json:"line2"`
+	City              string    `json:"city"`
+	Region            string    `json:"region"`
+	PostalCode        string    `json:"postal_code"`
+	Country           string    `json:"country"`
+	IsDefaultShipping bool      `json:"is_default_shipping"`
+	IsDefaultBilling  bool      `json:"is_default_billing"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+type AuditLog struct {
+	ID        int64     `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Action    string    `json:"action"`
+	Metadata  []byte    `json:"metadata"`
 	CreatedAt time.Time `json:"created_at"`
+}
+
+type ConsentLog struct {
+	ID         int64     `json:"id"`
+	UserID     uuid.UUID `json:"user_id"`
+	Purpose    string    `json:"purpose"`
+	Granted    bool      `json:"granted"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+type DataExport struct {
+	ID          uuid.UUID          `json:"id"`
+	UserID      uuid.UUID          `json:"user_id"`
+	Status      string             `json:"status"`
+	Archive     []byte             `json:"archive"`
+	Error       string             `json:"error"`
+	CreatedAt   time.Time          `json:"created_at"`
+	CompletedAt pgtype.Timestamptz `json:"completed_at"`
+	StartedAt   pgtype.Timestamptz `json:"started_at"`
+	Attempts    int32              `json:"attempts"`
+}
+
+type Outbox struct {
+	ID          int64              `json:"id"`
+	EventID     uuid.UUID          `json:"event_id"`
+	EventType   string             `json:"event_type"`
+	AggregateID uuid.UUID          `json:"aggregate_id"`
+	Payload     []byte             `json:"payload"`
+	CreatedAt   time.Time          `json:"created_at"`
+	PublishedAt pgtype.Timestamptz `json:"published_at"`
+}
+
+type User struct {
+	ID               uuid.UUID          `json:"id"`
+	Name             string             `json:"name"`
+	Email            string             `json:"email"`
+	Password         string             `json:"password"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+	DeleteAfter      pgtype.Timestamptz `json:"delete_after"`
+	DeletedAt        pgtype.Timestamptz `json:"deleted_at"`
+	SuspendedAt      pgtype.Timestamptz `json:"suspended_at"`
+	SuspensionReason string             `json:"suspension_reason"`
+	Phone            pgtype.Text        `json:"phone"`
+	PhoneVerifiedAt  pgtype.Timestamptz `json:"phone_verified_at"`
+	TwoFactorEnabled bool               `json:"two_factor_enabled"`
+}
+
+type UserPreference struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Key       string    `json:"key"`
+	Value     []byte    `json:"value"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -9,22 +9,289 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const anonymiseUser = `-- name: AnonymiseUser :exec
+UPDATE users
+SET name = 'deleted user',
+    email = 'deleted+' || id::text || '@invalid',
+    password = '',
+    phone = NULL,
+    phone_verified_at = NULL,
+    two_factor_enabled = FALSE,
+    delete_after = NULL,
+    deleted_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+func (q *Queries) AnonymiseUser(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, anonymiseUser, id)
+	return err
+}
+
+const cancelUserDeletion = `-- name: CancelUserDeletion :execrows
+UPDATE users
+SET delete_after = NULL
+WHERE id = $1 AND deleted_at IS NULL AND delete_after IS NOT NULL
+`
+
+func (q *Queries) CancelUserDeletion(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, cancelUserDeletion, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const claimDataExports = `-- name: ClaimDataExports :many
+UPDATE data_exports
+SET started_at = CURRENT_TIMESTAMP,
+    attempts = attempts + 1
+WHERE id IN (
+    SELECT d.id FROM data_exports d
+    WHERE d.status = 'pending'
+      AND (d.started_at IS NULL OR d.started_at < $1)
+    ORDER BY d.created_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, user_id, status, archive, error, created_at, completed_at, started_at, attempts
+`
+
+type ClaimDataExportsParams struct {
+	StaleBefore pgtype.Timestamptz `json:"stale_before"`
+	MaxResults  int32              `json:"max_results"`
+}
+
+func (q *Queries) ClaimDataExports(ctx context.Context, arg ClaimDataExportsParams) ([]DataExport, error) {
+	rows, err := q.db.Query(ctx, claimDataExports, arg.StaleBefore, arg.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DataExport
+	for rows.Next() {
+		var i DataExport
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Status,
+			&i.Archive,
+			&i.Error,
+			&i.CreatedAt,
+			&i.CompletedAt,
+			&i.StartedAt,
+			&i.Attempts,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const clearDefaultBillingAddress = `-- name: ClearDefaultBillingAddress :exec
+UPDATE addresses
+SET is_default_billing = FALSE
+WHERE user_id = $1 AND is_default_billing
+`
+
+func (q *Queries) ClearDefaultBillingAddress(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, clearDefaultBillingAddress, userID)
+	return err
+}
+
+const clearDefaultShippingAddress = `-- name: ClearDefaultShippingAddress :exec
+UPDATE addresses
+SET is_default_shipping = FALSE
+WHERE user_id = $1 AND is_default_shipping
+`
+
+func (q *Queries) ClearDefaultShippingAddress(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, clearDefaultShippingAddress, userID)
+	return err
+}
+
+const clearUserPhone = `-- name: ClearUserPhone :one
+UPDATE users
+SET phone = NULL, phone_verified_at = NULL, two_factor_enabled = FALSE, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, name, email, password, created_at, updated_at, delete_after, deleted_at, suspended_at, suspension_reason, phone, phone_verified_at, two_factor_enabled
+`
+
+func (q *Queries) ClearUserPhone(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRow(ctx, clearUserPhone, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeleteAfter,
+		&i.DeletedAt,
+		&i.SuspendedAt,
+		&i.SuspensionReason,
+		&i.Phone,
+		&i.PhoneVerifiedAt,
+		&i.TwoFactorEnabled,
+	)
+	return i, err
+}
+
+const completeDataExport = `-- name: CompleteDataExport :exec
+UPDATE data_exports
+SET status = 'ready', archive = $2, completed_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+type CompleteDataExportParams struct {
+	ID      uuid.UUID `json:"id"`
+	Archive []byte    `json:"archive"`
+}
+
+func (q *Queries) CompleteDataExport(ctx context.Context, arg CompleteDataExportParams) error {
+	_, err := q.db.Exec(ctx, completeDataExport, arg.ID, arg.Archive)
+	return err
+}
+
+const countAddressesByUser = `-- name: CountAddressesByUser :one
+SELECT COUNT(*) FROM addresses
+WHERE user_id = $1
+`
+
+func (q *Queries) CountAddressesByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countAddressesByUser, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAddress = `-- name: CreateAddress :one
+INSERT INTO addresses (
+    id, user_id, label, line1, line2, city, region, postal_code, country, is_default_shipping, is_default_billing
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+)
+RETURNING id, user_id, label, line1, line2, city, region, postal_code, country, is_default_shipping, is_default_billing, created_at, updated_at
+`
+
+type CreateAddressParams struct {
+	ID                uuid.UUID `json:"id"`
+	UserID            uuid.UUID `json:"user_id"`
+	Label             string    `json:"label"`
+	Line1             string    `json:"line1"`
+	Line2             string    `json:"line2"`
+	City              string    `json:"city"`
+	Region            string    `json:"region"`
+	PostalCode        string    `json:"postal_code"`
+	Country           string    `json:"country"`
+	IsDefaultShipping bool      `json:"is_default_shipping"`
+	IsDefaultBilling  bool      `json:"is_default_billing"`
+}
+
+func (q *Queries) CreateAddress(ctx context.Context, arg CreateAddressParams) (Address, error) {
+	row := q.db.QueryRow(ctx, createAddress,
+		arg.ID,
+		arg.UserID,
+		arg.Label,
+		arg.Line1,
+		arg.Line2,
+		arg.City,
+		arg.Region,
+		arg.PostalCode,
+		arg.Country,
+		arg.IsDefaultShipping,
+		arg.IsDefaultBilling,
+	)
+	var i Address
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Label,
+		&i.Line1,
+		&i.Line2,
+		&i.City,
+		&i.Region,
+		&i.PostalCode,
+		&i.Country,
+		&i.IsDefaultShipping,
+		&i.IsDefaultBilling,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createAuditEntry = `-- name: CreateAuditEntry :exec
+INSERT INTO audit_log (
+    user_id, action, metadata
+) VALUES (
+    $1, $2, $3
+)
+`
+
+type CreateAuditEntryParams struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Action   string    `json:"action"`
+	Metadata []byte    `json:"metadata"`
+}
+
+func (q *Queries) CreateAuditEntry(ctx context.Context, arg CreateAuditEntryParams) error {
+	_, err := q.db.Exec(ctx, createAuditEntry, arg.UserID, arg.Action, arg.Metadata)
+	return err
+}
+
+const createDataExport = `-- name: CreateDataExport :one
+INSERT INTO data_exports (
+    id, user_id
+) VALUES (
+    $1, $2
+)
+RETURNING id, user_id, status, archive, error, created_at, completed_at, started_at, attempts
+`
+
+type CreateDataExportParams struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) CreateDataExport(ctx context.Context, arg CreateDataExportParams) (DataExport, error) {
+	row := q.db.QueryRow(ctx, createDataExport, arg.ID, arg.UserID)
+	var i DataExport
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.Archive,
+		&i.Error,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.StartedAt,
+		&i.Attempts,
+	)
+	return i, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (
-    id, name, email, address, password
+    id, name, email, password
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4
 )
-RETURNING id, name, email, address, password, created_at, updated_at
+RETURNING id, name, email, password, created_at, updated_at, delete_after, deleted_at, suspended_at, suspension_reason, phone, phone_verified_at, two_factor_enabled
 `
 
 type CreateUserParams struct {
 	ID       uuid.UUID `json:"id"`
 	Name     string    `json:"name"`
 	Email    string    `json:"email"`
-	Address  string    `json:"address"`
 	Password string    `json:"password"`
 }
 
@@ -33,7 +300,6 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.ID,
 		arg.Name,
 		arg.Email,
-		arg.Address,
 		arg.Password,
 	)
 	var i User
@@ -41,16 +307,225 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.ID,
 		&i.Name,
 		&i.Email,
-		&i.Address,
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeleteAfter,
+		&i.DeletedAt,
+		&i.SuspendedAt,
+		&i.SuspensionReason,
+		&i.Phone,
+		&i.PhoneVerifiedAt,
+		&i.TwoFactorEnabled,
+	)
+	return i, err
+}
+
+const deleteAddress = `-- name: DeleteAddress :one
+DELETE FROM addresses
+WHERE id = $1 AND user_id = $2
+RETURNING id, user_id, label, line1, line2, city, region, postal_code, country, is_default_shipping, is_default_billing, created_at, updated_at
+`
+
+type DeleteAddressParams struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) DeleteAddress(ctx context.Context, arg DeleteAddressParams) (Address, error) {
+	row := q.db.QueryRow(ctx, deleteAddress, arg.ID, arg.UserID)
+	var i Address
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Label,
+		&i.Line1,
+		&i.Line2,
+		&i.City,
+		&i.Region,
+		&i.PostalCode,
+		&i.Country,
+		&i.IsDefaultShipping,
+		&i.IsDefaultBilling,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteAddressesByUser = `-- name: DeleteAddressesByUser :exec
+DELETE FROM addresses
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteAddressesByUser(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteAddressesByUser, userID)
+	return err
+}
+
+const deleteAuditEntriesByUser = `-- name: DeleteAuditEntriesByUser :exec
+DELETE FROM audit_log
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteAuditEntriesByUser(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteAuditEntriesByUser, userID)
+	return err
+}
+
+const deleteConsentLogByUser = `-- name: DeleteConsentLogByUser :exec
+DELETE FROM consent_log
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteConsentLogByUser(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteConsentLogByUser, userID)
+	return err
+}
+
+const deleteDataExportsByUser = `-- name: DeleteDataExportsByUser :exec
+DELETE FROM data_exports
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteDataExportsByUser(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteDataExportsByUser, userID)
+	return err
+}
+
+const deletePreferencesByUser = `-- name: DeletePreferencesByUser :exec
+DELETE FROM user_preferences
+WHERE user_id = $1
+`
+
+func (q *Queries) DeletePreferencesByUser(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deletePreferencesByUser, userID)
+	return err
+}
+
+const deletePublishedOutboxEvents = `-- name: DeletePublishedOutboxEvents :execrows
+DELETE FROM outbox
+WHERE published_at < $1
+`
+
+func (q *Queries) DeletePublishedOutboxEvents(ctx context.Context, publishedAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deletePublishedOutboxEvents, publishedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteUser = `-- name: DeleteUser :exec
+DELETE FROM users
+WHERE id = $1
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteUser, id)
+	return err
+}
+
+const failDataExport = `-- name: FailDataExport :exec
+UPDATE data_exports
+SET status = 'failed', error = $2, completed_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+type FailDataExportParams struct {
+	ID    uuid.UUID `json:"id"`
+	Error string    `json:"error"`
+}
+
+func (q *Queries) FailDataExport(ctx context.Context, arg FailDataExportParams) error {
+	_, err := q.db.Exec(ctx, failDataExport, arg.ID, arg.Error)
+	return err
+}
+
+const getAddress = `-- name: GetAddress :one
+SELECT id, user_id, label, line1, line2, city, region, postal_code, country, is_default_shipping, is_default_billing, created_at, updated_at FROM addresses
+WHERE id = $1 AND user_id = $2
+`
+
+type GetAddressParams struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) GetAddress(ctx context.Context, arg GetAddressParams) (Address, error) {
+	row := q.db.QueryRow(ctx, getAddress, arg.ID, arg.UserID)
+	var i Address
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Label,
+		&i.Line1,
+		&i.Line2,
+		&i.City,
+		&i.Region,
+		&i.PostalCode,
+		&i.Country,
+		&i.IsDefaultShipping,
+		&i.IsDefaultBilling,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getDataExport = `-- name: GetDataExport :one
+SELECT id, user_id, status, archive, error, created_at, completed_at, started_at, attempts FROM data_exports
+WHERE id = $1 AND user_id = $2
+`
+
+type GetDataExportParams struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) GetDataExport(ctx context.Context, arg GetDataExportParams) (DataExport, error) {
+	row := q.db.QueryRow(ctx, getDataExport, arg.ID, arg.UserID)
+	var i DataExport
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.Archive,
+		&i.Error,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.StartedAt,
+		&i.Attempts,
+	)
+	return i, err
+}
+
+const getPendingDataExport = `-- name: GetPendingDataExport :one
+SELECT id, user_id, status, archive, error, created_at, completed_at, started_at, attempts FROM data_exports
+WHERE user_id = $1 AND status = 'pending'
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetPendingDataExport(ctx context.Context, userID uuid.UUID) (DataExport, error) {
+	row := q.db.QueryRow(ctx, getPendingDataExport, userID)
+	var i DataExport
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.Archive,
+		&i.Error,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.StartedAt,
+		&i.Attempts,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, name, email, address, password, created_at, updated_at FROM users
+SELECT id, name, email, password, created_at, updated_at, delete_after, deleted_at, suspended_at, suspension_reason, phone, phone_verified_at, two_factor_enabled FROM users
 WHERE email = $1
 `
 
@@ -61,16 +536,22 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.ID,
 		&i.Name,
 		&i.Email,
-		&i.Address,
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeleteAfter,
+		&i.DeletedAt,
+		&i.SuspendedAt,
+		&i.SuspensionReason,
+		&i.Phone,
+		&i.PhoneVerifiedAt,
+		&i.TwoFactorEnabled,
 	)
 	return i, err
 }
 
 const getUserById = `-- name: GetUserById :one
-SELECT id, name, email, address, password, created_at, updated_at FROM users
+SELECT id, name, email, password, created_at, updated_at, delete_after, deleted_at, suspended_at, suspension_reason, phone, phone_verified_at, two_factor_enabled FROM users
 WHERE id = $1
 `
 
@@ -81,38 +562,752 @@ func (q *Queries) GetUserById(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.ID,
 		&i.Name,
 		&i.Email,
-		&i.Address,
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeleteAfter,
+		&i.DeletedAt,
+		&i.SuspendedAt,
+		&i.SuspensionReason,
+		&i.Phone,
+		&i.PhoneVerifiedAt,
+		&i.TwoFactorEnabled,
+	)
+	return i, err
+}
+
+const getUsersByIds = `-- name: GetUsersByIds :many
+SELECT id, name, email, password, created_at, updated_at, delete_after, deleted_at, suspended_at, suspension_reason, phone, phone_verified_at, two_factor_enabled FROM users
+WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) GetUsersByIds(ctx context.Context, ids []uuid.UUID) ([]User, error) {
+	rows, err := q.db.Query(ctx, getUsersByIds, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.Password,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeleteAfter,
+			&i.DeletedAt,
+			&i.SuspendedAt,
+			&i.SuspensionReason,
+			&i.Phone,
+			&i.PhoneVerifiedAt,
+			&i.TwoFactorEnabled,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertOutboxEvent = `-- name: InsertOutboxEvent :exec
+INSERT INTO outbox (
+    event_id, event_type, aggregate_id, payload
+) VALUES (
+    $1, $2, $3, $4
+)
+`
+
+type InsertOutboxEventParams struct {
+	EventID     uuid.UUID `json:"event_id"`
+	EventType   string    `json:"event_type"`
+	AggregateID uuid.UUID `json:"aggregate_id"`
+	Payload     []byte    `json:"payload"`
+}
+
+func (q *Queries) InsertOutboxEvent(ctx context.Context, arg InsertOutboxEventParams) error {
+	_, err := q.db.Exec(ctx, insertOutboxEvent,
+		arg.EventID,
+		arg.EventType,
+		arg.AggregateID,
+		arg.Payload,
+	)
+	return err
+}
+
+const listAddressesByUser = `-- name: ListAddressesByUser :many
+SELECT id, user_id, label, line1, line2, city, region, postal_code, country, is_default_shipping, is_default_billing, created_at, updated_at FROM addresses
+WHERE user_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) ListAddressesByUser(ctx context.Context, userID uuid.UUID) ([]Address, error) {
+	rows, err := q.db.Query(ctx, listAddressesByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Address
+	for rows.Next() {
+		var i Address
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Label,
+			&i.Line1,
+			&i.Line2,
+			&i.City,
+			&i.Region,
+			&i.PostalCode,
+			&i.Country,
+			&i.IsDefaultShipping,
+			&i.IsDefaultBilling,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditEntriesByUser = `-- name: ListAuditEntriesByUser :many
+SELECT id, user_id, action, metadata, created_at FROM audit_log
+WHERE user_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) ListAuditEntriesByUser(ctx context.Context, userID uuid.UUID) ([]AuditLog, error) {
+	rows, err := q.db.Query(ctx, listAuditEntriesByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Action,
+			&i.Metadata,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listConsentHistory = `-- name: ListConsentHistory :many
+SELECT id, user_id, purpose, granted, recorded_at FROM consent_log
+WHERE user_id = $1
+ORDER BY recorded_at
+`
+
+func (q *Queries) ListConsentHistory(ctx context.Context, userID uuid.UUID) ([]ConsentLog, error) {
+	rows, err := q.db.Query(ctx, listConsentHistory, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ConsentLog
+	for rows.Next() {
+		var i ConsentLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Purpose,
+			&i.Granted,
+			&i.RecordedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCurrentConsents = `-- name: ListCurrentConsents :many
+SELECT DISTINCT ON (purpose) id, user_id, purpose, granted, recorded_at FROM consent_log
+WHERE user_id = $1
+ORDER BY purpose, recorded_at DESC
+`
+
+func (q *Queries) ListCurrentConsents(ctx context.Context, userID uuid.UUID) ([]ConsentLog, error) {
+	rows, err := q.db.Query(ctx, listCurrentConsents, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ConsentLog
+	for rows.Next() {
+		var i ConsentLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Purpose,
+			&i.Granted,
+			&i.RecordedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPreferencesByUser = `-- name: ListPreferencesByUser :many
+SELECT user_id, key, value, updated_at FROM user_preferences
+WHERE user_id = $1
+`
+
+func (q *Queries) ListPreferencesByUser(ctx context.Context, userID uuid.UUID) ([]UserPreference, error) {
+	rows, err := q.db.Query(ctx, listPreferencesByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UserPreference
+	for rows.Next() {
+		var i UserPreference
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Key,
+			&i.Value,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnpublishedOutboxEvents = `-- name: ListUnpublishedOutboxEvents :many
+SELECT id, event_id, event_type, aggregate_id, payload, created_at, published_at FROM outbox
+WHERE published_at IS NULL
+ORDER BY id
+LIMIT $1
+FOR UPDATE SKIP LOCKED
+`
+
+func (q *Queries) ListUnpublishedOutboxEvents(ctx context.Context, limit int32) ([]Outbox, error) {
+	rows, err := q.db.Query(ctx, listUnpublishedOutboxEvents, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Outbox
+	for rows.Next() {
+		var i Outbox
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventID,
+			&i.EventType,
+			&i.AggregateID,
+			&i.Payload,
+			&i.CreatedAt,
+			&i.PublishedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT id, name, email, password, created_at, updated_at, delete_after, deleted_at, suspended_at, suspension_reason, phone, phone_verified_at, two_factor_enabled FROM users
+WHERE ($1::uuid IS NULL OR id > $1::uuid)
+  AND ($2::text IS NULL OR email ILIKE $2::text || '%')
+  AND ($3::timestamptz IS NULL OR created_at >= $3::timestamptz)
+  AND ($4::timestamptz IS NULL OR created_at < $4::timestamptz)
+ORDER BY id
+LIMIT $5
+`
+
+type ListUsersParams struct {
+	After       uuid.NullUUID      `json:"after"`
+	EmailPrefix pgtype.Text        `json:"email_prefix"`
+	CreatedFrom pgtype.Timestamptz `json:"created_from"`
+	CreatedTo   pgtype.Timestamptz `json:"created_to"`
+	PageSize    int32              `json:"page_size"`
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
+	rows, err := q.db.Query(ctx, listUsers,
+		arg.After,
+		arg.EmailPrefix,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.Password,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeleteAfter,
+			&i.DeletedAt,
+			&i.SuspendedAt,
+			&i.SuspensionReason,
+			&i.Phone,
+			&i.PhoneVerifiedAt,
+			&i.TwoFactorEnabled,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersDueForDeletion = `-- name: ListUsersDueForDeletion :many
+SELECT id FROM users
+WHERE delete_after <= CURRENT_TIMESTAMP AND deleted_at IS NULL
+ORDER BY delete_after
+LIMIT $1
+FOR UPDATE SKIP LOCKED
+`
+
+func (q *Queries) ListUsersDueForDeletion(ctx context.Context, limit int32) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, listUsersDueForDeletion, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockUserPreferences = `-- name: LockUserPreferences :exec
+SELECT id FROM users
+WHERE id = $1
+FOR NO KEY UPDATE
+`
+
+// the user row stands in for their preferences, rows that don't exist yet
+// can't be locked
+func (q *Queries) LockUserPreferences(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, lockUserPreferences, id)
+	return err
+}
+
+const markOutboxEventsPublished = `-- name: MarkOutboxEventsPublished :exec
+UPDATE outbox
+SET published_at = CURRENT_TIMESTAMP
+WHERE id = ANY($1::bigint[])
+`
+
+func (q *Queries) MarkOutboxEventsPublished(ctx context.Context, ids []int64) error {
+	_, err := q.db.Exec(ctx, markOutboxEventsPublished, ids)
+	return err
+}
+
+const markPhoneVerified = `-- name: MarkPhoneVerified :one
+UPDATE users
+SET phone_verified_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND phone = $2
+RETURNING id, name, email, password, created_at, updated_at, delete_after, deleted_at, suspended_at, suspension_reason, phone, phone_verified_at, two_factor_enabled
+`
+
+type MarkPhoneVerifiedParams struct {
+	ID    uuid.UUID   `json:"id"`
+	Phone pgtype.Text `json:"phone"`
+}
+
+func (q *Queries) MarkPhoneVerified(ctx context.Context, arg MarkPhoneVerifiedParams) (User, error) {
+	row := q.db.QueryRow(ctx, markPhoneVerified, arg.ID, arg.Phone)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeleteAfter,
+		&i.DeletedAt,
+		&i.SuspendedAt,
+		&i.SuspensionReason,
+		&i.Phone,
+		&i.PhoneVerifiedAt,
+		&i.TwoFactorEnabled,
+	)
+	return i, err
+}
+
+const promoteDefaultAddress = `-- name: PromoteDefaultAddress :exec
+UPDATE addresses
+SET is_default_shipping = is_default_shipping OR $1::bool,
+    is_default_billing = is_default_billing OR $2::bool,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = (
+    SELECT a.id FROM addresses a
+    WHERE a.user_id = $3
+    ORDER BY a.id = $4, a.created_at, a.id
+    LIMIT 1
+)
+`
+
+type PromoteDefaultAddressParams struct {
+	Shipping bool      `json:"shipping"`
+	Billing  bool      `json:"billing"`
+	UserID   uuid.UUID `json:"user_id"`
+	ExceptID uuid.UUID `json:"except_id"`
+}
+
+func (q *Queries) PromoteDefaultAddress(ctx context.Context, arg PromoteDefaultAddressParams) error {
+	_, err := q.db.Exec(ctx, promoteDefaultAddress,
+		arg.Shipping,
+		arg.Billing,
+		arg.UserID,
+		arg.ExceptID,
+	)
+	return err
+}
+
+const recordConsent = `-- name: RecordConsent :exec
+INSERT INTO consent_log (
+    user_id, purpose, granted
+) VALUES (
+    $1, $2, $3
+)
+`
+
+type RecordConsentParams struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Purpose string    `json:"purpose"`
+	Granted bool      `json:"granted"`
+}
+
+func (q *Queries) RecordConsent(ctx context.Context, arg RecordConsentParams) error {
+	_, err := q.db.Exec(ctx, recordConsent, arg.UserID, arg.Purpose, arg.Granted)
+	return err
+}
+
+const scheduleUserDeletion = `-- name: ScheduleUserDeletion :one
+UPDATE users
+SET delete_after = $2
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, name, email, password, created_at, updated_at, delete_after, deleted_at, suspended_at, suspension_reason, phone, phone_verified_at, two_factor_enabled
+`
+
+type ScheduleUserDeletionParams struct {
+	ID          uuid.UUID          `json:"id"`
+	DeleteAfter pgtype.Timestamptz `json:"delete_after"`
+}
+
+func (q *Queries) ScheduleUserDeletion(ctx context.Context, arg ScheduleUserDeletionParams) (User, error) {
+	row := q.db.QueryRow(ctx, scheduleUserDeletion, arg.ID, arg.DeleteAfter)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeleteAfter,
+		&i.DeletedAt,
+		&i.SuspendedAt,
+		&i.SuspensionReason,
+		&i.Phone,
+		&i.PhoneVerifiedAt,
+		&i.TwoFactorEnabled,
+	)
+	return i, err
+}
+
+const setTwoFactorEnabled = `-- name: SetTwoFactorEnabled :one
+UPDATE users
+SET two_factor_enabled = $1::boolean, updated_at = CURRENT_TIMESTAMP
+WHERE id = $2 AND (NOT $1::boolean OR phone_verified_at IS NOT NULL)
+RETURNING id, name, email, password, created_at, updated_at, delete_after, deleted_at, suspended_at, suspension_reason, phone, phone_verified_at, two_factor_enabled
+`
+
+type SetTwoFactorEnabledParams struct {
+	Enabled bool      `json:"enabled"`
+	ID      uuid.UUID `json:"id"`
+}
+
+// only a verified phone can be used as a second factor
+func (q *Queries) SetTwoFactorEnabled(ctx context.Context, arg SetTwoFactorEnabledParams) (User, error) {
+	row := q.db.QueryRow(ctx, setTwoFactorEnabled, arg.Enabled, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeleteAfter,
+		&i.DeletedAt,
+		&i.SuspendedAt,
+		&i.SuspensionReason,
+		&i.Phone,
+		&i.PhoneVerifiedAt,
+		&i.TwoFactorEnabled,
+	)
+	return i, err
+}
+
+const setUserPhone = `-- name: SetUserPhone :one
+UPDATE users
+SET phone = $2, phone_verified_at = NULL, two_factor_enabled = FALSE, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, name, email, password, created_at, updated_at, delete_after, deleted_at, suspended_at, suspension_reason, phone, phone_verified_at, two_factor_enabled
+`
+
+type SetUserPhoneParams struct {
+	ID    uuid.UUID   `json:"id"`
+	Phone pgtype.Text `json:"phone"`
+}
+
+func (q *Queries) SetUserPhone(ctx context.Context, arg SetUserPhoneParams) (User, error) {
+	row := q.db.QueryRow(ctx, setUserPhone, arg.ID, arg.Phone)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeleteAfter,
+		&i.DeletedAt,
+		&i.SuspendedAt,
+		&i.SuspensionReason,
+		&i.Phone,
+		&i.PhoneVerifiedAt,
+		&i.TwoFactorEnabled,
+	)
+	return i, err
+}
+
+const suspendUser = `-- name: SuspendUser :one
+UPDATE users
+SET suspended_at = CURRENT_TIMESTAMP, suspension_reason = $2
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, name, email, password, created_at, updated_at, delete_after, deleted_at, suspended_at, suspension_reason, phone, phone_verified_at, two_factor_enabled
+`
+
+type SuspendUserParams struct {
+	ID               uuid.UUID `json:"id"`
+	SuspensionReason string    `json:"suspension_reason"`
+}
+
+func (q *Queries) SuspendUser(ctx context.Context, arg SuspendUserParams) (User, error) {
+	row := q.db.QueryRow(ctx, suspendUser, arg.ID, arg.SuspensionReason)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeleteAfter,
+		&i.DeletedAt,
+		&i.SuspendedAt,
+		&i.SuspensionReason,
+		&i.Phone,
+		&i.PhoneVerifiedAt,
+		&i.TwoFactorEnabled,
+	)
+	return i, err
+}
+
+const unsuspendUser = `-- name: UnsuspendUser :one
+UPDATE users
+SET suspended_at = NULL, suspension_reason = ''
+WHERE id = $1
+RETURNING id, name, email, password, created_at, updated_at, delete_after, deleted_at, suspended_at, suspension_reason, phone, phone_verified_at, two_factor_enabled
+`
+
+func (q *Queries) UnsuspendUser(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRow(ctx, unsuspendUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeleteAfter,
+		&i.DeletedAt,
+		&i.SuspendedAt,
+		&i.SuspensionReason,
+		&i.Phone,
+		&i.PhoneVerifiedAt,
+		&i.TwoFactorEnabled,
+	)
+	return i, err
+}
+
+const updateAddress = `-- name: UpdateAddress :one
+UPDATE addresses
+SET label = $3,
+    line1 = $4,
+    line2 = $5,
+    city = $6,
+    region = $7,
+    postal_code = $8,
+    country = $9,
+    is_default_shipping = $10,
+    is_default_billing = $11,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND user_id = $2
+RETURNING id, user_id, label, line1, line2, city, region, postal_code, country, is_default_shipping, is_default_billing, created_at, updated_at
+`
+
+type UpdateAddressParams struct {
+	ID                uuid.UUID `json:"id"`
+	UserID            uuid.UUID `json:"user_id"`
+	Label             string    `json:"label"`
+	Line1             string    `json:"line1"`
+	Line2             string    `json:"line2"`
+	City              string    `json:"city"`
+	Region            string    `json:"region"`
+	PostalCode        string    `json:"postal_code"`
+	Country           string    `json:"country"`
+	IsDefaultShipping bool      `json:"is_default_shipping"`
+	IsDefaultBilling  bool      `json:"is_default_billing"`
+}
+
+func (q *Queries) UpdateAddress(ctx context.Context, arg UpdateAddressParams) (Address, error) {
+	row := q.db.QueryRow(ctx, updateAddress,
+		arg.ID,
+		arg.UserID,
+		arg.Label,
+		arg.Line1,
+		arg.Line2,
+		arg.City,
+		arg.Region,
+		arg.PostalCode,
+		arg.Country,
+		arg.IsDefaultShipping,
+		arg.IsDefaultBilling,
+	)
+	var i Address
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Label,
+		&i.Line1,
+		&i.Line2,
+		&i.City,
+		&i.Region,
+		&i.PostalCode,
+		&i.Country,
+		&i.IsDefaultShipping,
+		&i.IsDefaultBilling,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
-SET name = $1, address = $2
-WHERE id = $3
-RETURNING id, name, email, address, password, created_at, updated_at
+SET name = $1
+WHERE id = $2
+RETURNING id, name, email, password, created_at, updated_at, delete_after, deleted_at, suspended_at, suspension_reason, phone, phone_verified_at, two_factor_enabled
 `
 
 type UpdateUserParams struct {
-	Name    string    `json:"name"`
-	Address string    `json:"address"`
-	ID      uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	ID   uuid.UUID `json:"id"`
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
-	row := q.db.QueryRow(ctx, updateUser, arg.Name, arg.Address, arg.ID)
+	row := q.db.QueryRow(ctx, updateUser, arg.Name, arg.ID)
 	var i User
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
 		&i.Email,
-		&i.Address,
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeleteAfter,
+		&i.DeletedAt,
+		&i.SuspendedAt,
+		&i.SuspensionReason,
+		&i.Phone,
+		&i.PhoneVerifiedAt,
+		&i.TwoFactorEnabled,
 	)
 	return i, err
 }
+
+const upsertPreference = `-- name: UpsertPreference :exec
+INSERT INTO user_preferences (
+    user_id, key, value
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (user_id, key) DO UPDATE
+SET value = EXCLUDED.value, updated_at = CURRENT_TIMESTAMP
+`
+
+type UpsertPreferenceParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	Key    string    `json:"key"`
+	Value  []byte    `json:"value"`
+}
+
+func (q *Queries) UpsertPreference(ctx context.Context, arg UpsertPreferenceParams) error {
+	_, err := q.db.Exec(ctx, upsertPreference, arg.UserID, arg.Key, arg.Value)
+	return err
+}
@@ -2,30 +2,64 @@ package client
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	account "github.com/lmnzx/slopify/account/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrUserNotFound      = errors.New("user not found")
+	ErrUserAlreadyExists = errors.New("user already exists")
+	ErrTooManyRequests   = errors.New("too many requests")
 )
 
+// InvalidArgumentError is a request the account service rejected, Reason is
+// safe to show to the user
+type InvalidArgumentError struct {
+	Reason string
+}
+
+func (e *InvalidArgumentError) Error() string {
+	return e.Reason
+}
+
 func GetUser(ctx context.Context, c account.AccountServiceClient, email string) (*account.User, error) {
 	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
 	defer cancel()
 
 	r, err := c.GetUserByEmail(ctx, &account.GetUserByEmailRequest{Email: email})
 	if err != nil {
-		return nil, err
+		return nil, mapError(err)
 	}
 
 	return r, nil
 }
 
+func UserExists(ctx context.Context, c account.AccountServiceClient, email string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	r, err := c.UserExists(ctx, &account.UserExistsRequest{
+		Lookup: &account.UserExistsRequest_Email{Email: email},
+	})
+	if err != nil {
+		return false, mapError(err)
+	}
+
+	return r.Exists, nil
+}
+
 func CreateUser(ctx context.Context, c account.AccountServiceClient, req *account.CreateUserRequest) (*account.User, error) {
 	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
 	defer cancel()
 
 	r, err := c.CreateUser(ctx, req)
 	if err != nil {
-		return nil, err
+		return nil, mapError(err)
 	}
 
 	return r, nil
@@ -42,3 +76,46 @@ func CheckPassword(ctx context.Context, c account.AccountServiceClient, req *acc
 
 	return r.IsValid
 }
+
+// SendLoginChallenge texts the user a code for the pending login and returns
+// the masked phone number it was sent to
+func SendLoginChallenge(ctx context.Context, c account.AccountServiceClient, userID, loginToken string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	r, err := c.SendLoginChallenge(ctx, &account.SendLoginChallengeRequest{UserId: userID, LoginToken: loginToken})
+	if err != nil {
+		return "", mapError(err)
+	}
+
+	return r.PhoneHint, nil
+}
+
+func VerifyLoginChallenge(ctx context.Context, c account.AccountServiceClient, userID, loginToken, code string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	r, err := c.VerifyLoginChallenge(ctx, &account.VerifyLoginChallengeRequest{UserId: userID, LoginToken: loginToken, Code: code})
+	if err != nil {
+		return false, mapError(err)
+	}
+
+	return r.IsValid, nil
+}
+
+// mapError turns the account service status codes callers branch on into
+// typed errors and passes everything else through untouched
+func mapError(err error) error {
+	switch status.Code(err) {
+	case codes.NotFound:
+		return ErrUserNotFound
+	case codes.AlreadyExists:
+		return ErrUserAlreadyExists
+	case codes.ResourceExhausted:
+		return ErrTooManyRequests
+	case codes.InvalidArgument:
+		return &InvalidArgumentError{Reason: status.Convert(err).Message()}
+	default:
+		return err
+	}
+}
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMapError(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+
+	tests := []struct {
+		name       string
+		err        error
+		want       error
+		wantReason string
+	}{
+		{name: "not found", err: status.Error(codes.NotFound, "user not found"), want: ErrUserNotFound},
+		{name: "already exists", err: status.Error(codes.AlreadyExists, "user already exists"), want: ErrUserAlreadyExists},
+		{name: "resource exhausted", err: status.Error(codes.ResourceExhausted, "slow down"), want: ErrTooManyRequests},
+		{name: "invalid argument", err: status.Error(codes.InvalidArgument, "invalid city: is required"), wantReason: "invalid city: is required"},
+		{name: "passed through", err: unavailable, want: unavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mapError(tt.err)
+
+			if tt.wantReason != "" {
+				var invalidErr *InvalidArgumentError
+				if !errors.As(err, &invalidErr) {
+					t.Fatalf("mapError = %v, want an InvalidArgumentError", err)
+				}
+				if invalidErr.Reason != tt.wantReason {
+					t.Errorf("Reason = %q, want %q", invalidErr.Reason, tt.wantReason)
+				}
+				return
+			}
+
+			if !errors.Is(err, tt.want) {
+				t.Errorf("mapError = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"net"
 	"sync"
 
@@ -18,6 +19,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type GrpcHandler struct {
@@ -134,12 +136,16 @@ func (h *GrpcHandler) GenerateToken(ctx context.Context, req *proto.GenerateToke
 
 	user, err := client.GetUser(ctx, h.accountService, req.Email)
 	if err != nil {
+		if errors.Is(err, client.ErrUserNotFound) {
+			h.log.Error().Str("userId", req.UserId).Str("email", req.Email).Msg("GenerateToken called with unregistred user")
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
 		return nil, status.Errorf(codes.Internal, "failed to get the user from account service: %v", err)
 	}
 
 	if user.Email != req.Email || user.UserId != req.UserId {
 		h.log.Error().Str("userId", user.UserId).Str("email", user.Email).Msg("GenerateToken called with unregistred user")
-		return nil, status.Errorf(codes.PermissionDenied, "user not found: %v", err)
+		return nil, status.Error(codes.PermissionDenied, "user not found")
 	}
 
 	tokenPair, err := h.authService.GenerateTokenPair(ctx, req.UserId, req.Email)
@@ -192,3 +198,26 @@ func (h *GrpcHandler) RevokeTokens(ctx context.Context, req *proto.RevokeTokensR
 		Success: true,
 	}, nil
 }
+
+func (h *GrpcHandler) ListSessions(ctx context.Context, req *proto.ListSessionsRequest) (*proto.ListSessionsResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user ID is required")
+	}
+
+	sessions, err := h.authService.ListSessions(ctx, req.UserId)
+	if err != nil {
+		h.log.Error().Err(err).Msg("Failed to list sessions")
+		return nil, status.Error(codes.Internal, "failed to list sessions")
+	}
+
+	res := &proto.ListSessionsResponse{Sessions: make([]*proto.Session, 0, len(sessions))}
+	for _, session := range sessions {
+		res.Sessions = append(res.Sessions, &proto.Session{
+			SessionId: session.ID,
+			IssuedAt:  timestamppb.New(session.IssuedAt),
+			ExpiresAt: timestamppb.New(session.ExpiresAt),
+		})
+	}
+
+	return res, nil
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	account "github.com/lmnzx/slopify/account/proto"
+	"github.com/lmnzx/slopify/auth/internal"
+	"github.com/lmnzx/slopify/auth/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGenerateToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      *proto.GenerateTokenRequest
+		client   *fakeAccountClient
+		wantCode codes.Code
+	}{
+		{
+			name:     "missing arguments",
+			req:      &proto.GenerateTokenRequest{Email: testEmail},
+			client:   &fakeAccountClient{},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "user not found",
+			req:      &proto.GenerateTokenRequest{UserId: testUserID, Email: testEmail},
+			client:   &fakeAccountClient{userErr: status.Error(codes.NotFound, "user not found")},
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "lookup fails",
+			req:      &proto.GenerateTokenRequest{UserId: testUserID, Email: testEmail},
+			client:   &fakeAccountClient{userErr: status.Error(codes.Unavailable, "connection refused")},
+			wantCode: codes.Internal,
+		},
+		{
+			name:     "user id does not match",
+			req:      &proto.GenerateTokenRequest{UserId: testUserID, Email: testEmail},
+			client:   &fakeAccountClient{user: &account.User{UserId: "someone-else", Email: testEmail}},
+			wantCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewGrpcHandler(nil, tt.client, internal.Secrets{})
+			_, err := h.GenerateToken(context.Background(), tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("code = %s, want %s, err %v", got, tt.wantCode, err)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"sync"
 
 	account "github.com/lmnzx/slopify/account/proto"
@@ -49,6 +50,7 @@ func StartRestServer(ctx context.Context, port string, valkeyClient valkey.Clien
 	r.GET("/metrics", fasthttpadaptor.NewFastHTTPHandler(promhttp.Handler()))
 	r.POST("/signup", handler.SignUp)
 	r.POST("/login", handler.LogIn)
+	r.POST("/login/otp", handler.LogInOTP)
 	r.GET("/validate", handler.ValidateSession)
 	r.POST("/refresh", handler.RefreshTokens)
 	r.GET("/logout", handler.LogOut)
@@ -95,7 +97,19 @@ type SignUpRequest struct {
 	Name     string `json:"name"`
 	Email    string `json:"email"`
 	Password string `json:"password"`
-	Address  string `json:"address"`
+	// Address is optional, it becomes the default shipping and billing
+	// address
+	Address *SignUpAddress `json:"address"`
+}
+
+type SignUpAddress struct {
+	Label      string `json:"label"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2"`
+	City       string `json:"city"`
+	Region     string `json:"region"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
 }
 
 func (h *RestHandler) SignUp(ctx *fasthttp.RequestCtx) {
@@ -116,13 +130,13 @@ func (h *RestHandler) SignUp(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
-	user, err := client.GetUser(ctx, h.accountService, parsedBody.Email)
+	exists, err := client.UserExists(ctx, h.accountService, parsedBody.Email)
 	if err != nil {
 		h.log.Error().Err(err).Str("email", parsedBody.Email).Msg("error checking existing user")
 		h.res.SendError(ctx, fasthttp.StatusInternalServerError, "error checking existing user")
 		return
 	}
-	if user.UserId != "" {
+	if exists {
 		h.res.SendError(ctx, fasthttp.StatusConflict, "user already exists")
 		return
 	}
@@ -131,11 +145,31 @@ func (h *RestHandler) SignUp(ctx *fasthttp.RequestCtx) {
 		Name:     parsedBody.Name,
 		Email:    parsedBody.Email,
 		Password: parsedBody.Password,
-		Address:  parsedBody.Address,
+	}
+	if a := parsedBody.Address; a != nil {
+		req.InitialAddress = &account.NewAddress{
+			Label:      a.Label,
+			Line1:      a.Line1,
+			Line2:      a.Line2,
+			City:       a.City,
+			Region:     a.Region,
+			PostalCode: a.PostalCode,
+			Country:    a.Country,
+		}
 	}
 
 	createdUser, err := client.CreateUser(ctx, h.accountService, &req)
 	if err != nil {
+		// lost a race with a concurrent signup for the same email
+		if errors.Is(err, client.ErrUserAlreadyExists) {
+			h.res.SendError(ctx, fasthttp.StatusConflict, "user already exists")
+			return
+		}
+		var invalidErr *client.InvalidArgumentError
+		if errors.As(err, &invalidErr) {
+			h.res.SendError(ctx, fasthttp.StatusBadRequest, invalidErr.Reason)
+			return
+		}
 		h.log.Error().Err(err).Str("email", parsedBody.Email).Msg("failed to create user")
 		h.res.SendError(ctx, fasthttp.StatusInternalServerError, "failed to create user")
 		return
@@ -195,12 +229,40 @@ func (h *RestHandler) LogIn(ctx *fasthttp.RequestCtx) {
 
 	user, err := client.GetUser(ctx, h.accountService, parsedBody.Email)
 	if err != nil {
+		if errors.Is(err, client.ErrUserNotFound) {
+			h.res.SendError(ctx, fasthttp.StatusNotFound, "user not found")
+			return
+		}
 		h.log.Error().Err(err).Str("email", parsedBody.Email).Msg("failed to fetch user")
 		h.res.SendError(ctx, fasthttp.StatusInternalServerError, "failed to fetch user details")
 		return
 	}
-	if user == nil || user.UserId == "" {
-		h.res.SendError(ctx, fasthttp.StatusNotFound, "user not found")
+
+	// with two factor on, the password alone only gets a pending login that
+	// is finished at /login/otp
+	if user.TwoFactorEnabled {
+		loginToken, err := h.authService.StartPendingLogin(ctx, user.UserId, user.Email)
+		if err != nil {
+			h.res.SendError(ctx, fasthttp.StatusInternalServerError, "failed to start the login")
+			return
+		}
+
+		phoneHint, err := client.SendLoginChallenge(ctx, h.accountService, user.UserId, loginToken)
+		if err != nil {
+			h.authService.ClearPendingLogin(ctx, loginToken)
+			if errors.Is(err, client.ErrTooManyRequests) {
+				h.res.SendError(ctx, fasthttp.StatusTooManyRequests, "a code was sent recently, try again later")
+				return
+			}
+			h.log.Error().Err(err).Str("userId", user.UserId).Msg("failed to send login challenge")
+			h.res.SendError(ctx, fasthttp.StatusInternalServerError, "failed to send the login code")
+			return
+		}
+
+		h.res.SendSuccess(ctx, fasthttp.StatusAccepted, map[string]string{
+			"login_token": loginToken,
+			"phone_hint":  phoneHint,
+		})
 		return
 	}
 
@@ -220,6 +282,68 @@ func (h *RestHandler) LogIn(ctx *fasthttp.RequestCtx) {
 	})
 }
 
+type LogInOTPRequest struct {
+	LoginToken string `json:"login_token"`
+	Code       string `json:"code"`
+}
+
+func (h *RestHandler) LogInOTP(ctx *fasthttp.RequestCtx) {
+	var parsedBody LogInOTPRequest
+	if err := json.Unmarshal(ctx.Request.Body(), &parsedBody); err != nil {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	if parsedBody.LoginToken == "" || parsedBody.Code == "" {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "login_token and code are required")
+		return
+	}
+
+	pending, err := h.authService.GetPendingLogin(ctx, parsedBody.LoginToken)
+	if err != nil {
+		if errors.Is(err, internal.ErrLoginNotPending) {
+			h.res.SendError(ctx, fasthttp.StatusUnauthorized, "login expired, log in again")
+			return
+		}
+		h.res.SendError(ctx, fasthttp.StatusInternalServerError, "failed to check the login")
+		return
+	}
+
+	isValid, err := client.VerifyLoginChallenge(ctx, h.accountService, pending.UserID, parsedBody.LoginToken, parsedBody.Code)
+	if err != nil {
+		if errors.Is(err, client.ErrTooManyRequests) {
+			// the code is burnt, the whole login has to start over
+			h.authService.ClearPendingLogin(ctx, parsedBody.LoginToken)
+			h.res.SendError(ctx, fasthttp.StatusTooManyRequests, "too many attempts, log in again")
+			return
+		}
+		h.log.Error().Err(err).Str("userId", pending.UserID).Msg("failed to verify login challenge")
+		h.res.SendError(ctx, fasthttp.StatusInternalServerError, "failed to verify the login code")
+		return
+	}
+	if !isValid {
+		h.res.SendError(ctx, fasthttp.StatusUnauthorized, "invalid or expired code")
+		return
+	}
+
+	h.authService.ClearPendingLogin(ctx, parsedBody.LoginToken)
+
+	tokenPair, err := h.authService.GenerateTokenPair(ctx, pending.UserID, pending.Email)
+	if err != nil {
+		h.log.Error().Err(err).Str("userId", pending.UserID).Msg("failed to generate tokens")
+		h.res.SendError(ctx, fasthttp.StatusInternalServerError, "failed to generate authentication tokens")
+		return
+	}
+
+	cookie.Set(ctx, "access_token", tokenPair.AccessToken, "/", "", internal.AccessTokenExpiry, false, fasthttp.CookieSameSiteDefaultMode)
+	cookie.Set(ctx, "refresh_token", tokenPair.RefreshToken, "/", "", internal.RefreshTokenExpiry, false, fasthttp.CookieSameSiteDefaultMode)
+
+	h.res.SendSuccess(ctx, fasthttp.StatusCreated, map[string]string{
+		"user_id": pending.UserID,
+		"email":   pending.Email,
+	})
+}
+
 func (h *RestHandler) LogOut(ctx *fasthttp.RequestCtx) {
 	accessToken := cookie.Get(ctx, "access_token")
 	refreshToken := cookie.Get(ctx, "refresh_token")
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	account "github.com/lmnzx/slopify/account/proto"
+	"github.com/lmnzx/slopify/auth/internal"
+
+	"github.com/valyala/fasthttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testUserID = "0198b3a4-6c1e-7d2a-9f0b-3c5d7e9f1a2b"
+	testEmail  = "jane@example.com"
+)
+
+// fakeAccountClient answers the lookups the auth handlers make with canned
+// responses, the other calls are not used
+type fakeAccountClient struct {
+	account.AccountServiceClient
+
+	exists    bool
+	existsErr error
+	createErr error
+	valid     bool
+	user      *account.User
+	userErr   error
+
+	createCalls int
+}
+
+func (f *fakeAccountClient) UserExists(ctx context.Context, in *account.UserExistsRequest, opts ...grpc.CallOption) (*account.UserExistsResponse, error) {
+	if f.existsErr != nil {
+		return nil, f.existsErr
+	}
+	return &account.UserExistsResponse{Exists: f.exists}, nil
+}
+
+func (f *fakeAccountClient) CreateUser(ctx context.Context, in *account.CreateUserRequest, opts ...grpc.CallOption) (*account.User, error) {
+	f.createCalls++
+	if f.createErr != nil {
+		return nil, f.createErr
+	}
+	return &account.User{UserId: testUserID, Name: in.Name, Email: in.Email}, nil
+}
+
+func (f *fakeAccountClient) VaildEmailPassword(ctx context.Context, in *account.VaildEmailPasswordRequest, opts ...grpc.CallOption) (*account.ValidResponse, error) {
+	return &account.ValidResponse{IsValid: f.valid}, nil
+}
+
+func (f *fakeAccountClient) GetUserByEmail(ctx context.Context, in *account.GetUserByEmailRequest, opts ...grpc.CallOption) (*account.User, error) {
+	if f.userErr != nil {
+		return nil, f.userErr
+	}
+	return f.user, nil
+}
+
+func post(handler fasthttp.RequestHandler, body string) *fasthttp.RequestCtx {
+	var req fasthttp.Request
+	req.Header.SetMethod(fasthttp.MethodPost)
+	req.SetBodyString(body)
+
+	var ctx fasthttp.RequestCtx
+	// Init gives the context a server, the account calls derive timeouts
+	// from it
+	ctx.Init(&req, nil, nil)
+	handler(&ctx)
+	return &ctx
+}
+
+func TestSignUp(t *testing.T) {
+	const body = `{"name":"Jane","email":"` + testEmail + `","password":"hunter22"}`
+
+	tests := []struct {
+		name        string
+		body        string
+		client      *fakeAccountClient
+		wantStatus  int
+		wantMessage string
+		wantCreates int
+	}{
+		{
+			name:        "missing fields",
+			body:        `{"email":"` + testEmail + `"}`,
+			client:      &fakeAccountClient{},
+			wantStatus:  fasthttp.StatusBadRequest,
+			wantMessage: "name, email, and password are required",
+		},
+		{
+			name:        "user exists",
+			body:        body,
+			client:      &fakeAccountClient{exists: true},
+			wantStatus:  fasthttp.StatusConflict,
+			wantMessage: "user already exists",
+		},
+		{
+			name:        "existence check fails",
+			body:        body,
+			client:      &fakeAccountClient{existsErr: status.Error(codes.Unavailable, "connection refused")},
+			wantStatus:  fasthttp.StatusInternalServerError,
+			wantMessage: "error checking existing user",
+		},
+		{
+			name:        "lost the race to a concurrent signup",
+			body:        body,
+			client:      &fakeAccountClient{createErr: status.Error(codes.AlreadyExists, "user already exists")},
+			wantStatus:  fasthttp.StatusConflict,
+			wantMessage: "user already exists",
+			wantCreates: 1,
+		},
+		{
+			name:        "address rejected",
+			body:        body,
+			client:      &fakeAccountClient{createErr: status.Error(codes.InvalidArgument, "invalid postal_code: is required for US")},
+			wantStatus:  fasthttp.StatusBadRequest,
+			wantMessage: "invalid postal_code: is required for US",
+			wantCreates: 1,
+		},
+		{
+			name:        "create fails",
+			body:        body,
+			client:      &fakeAccountClient{createErr: status.Error(codes.Internal, "insert failed")},
+			wantStatus:  fasthttp.StatusInternalServerError,
+			wantMessage: "failed to create user",
+			wantCreates: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewRestHandler(nil, tt.client, internal.Secrets{})
+			ctx := post(h.SignUp, tt.body)
+
+			if got := ctx.Response.StatusCode(); got != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body %s", got, tt.wantStatus, ctx.Response.Body())
+			}
+			if got := string(ctx.Response.Body()); !strings.Contains(got, tt.wantMessage) {
+				t.Errorf("body = %s, want it to contain %q", got, tt.wantMessage)
+			}
+			if tt.client.createCalls != tt.wantCreates {
+				t.Errorf("CreateUser called %d times, want %d", tt.client.createCalls, tt.wantCreates)
+			}
+		})
+	}
+}
+
+func TestLogIn(t *testing.T) {
+	const body = `{"email":"` + testEmail + `","password":"hunter22"}`
+
+	tests := []struct {
+		name        string
+		client      *fakeAccountClient
+		wantStatus  int
+		wantMessage string
+	}{
+		{
+			name:        "wrong password",
+			client:      &fakeAccountClient{},
+			wantStatus:  fasthttp.StatusUnauthorized,
+			wantMessage: "invalid email or password",
+		},
+		{
+			name:        "user not found",
+			client:      &fakeAccountClient{valid: true, userErr: status.Error(codes.NotFound, "user not found")},
+			wantStatus:  fasthttp.StatusNotFound,
+			wantMessage: "user not found",
+		},
+		{
+			name:        "lookup fails",
+			client:      &fakeAccountClient{valid: true, userErr: status.Error(codes.Unavailable, "connection refused")},
+			wantStatus:  fasthttp.StatusInternalServerError,
+			wantMessage: "failed to fetch user details",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewRestHandler(nil, tt.client, internal.Secrets{})
+			ctx := post(h.LogIn, body)
+
+			if got := ctx.Response.StatusCode(); got != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body %s", got, tt.wantStatus, ctx.Response.Body())
+			}
+			if got := string(ctx.Response.Body()); !strings.Contains(got, tt.wantMessage) {
+				t.Errorf("body = %s, want it to contain %q", got, tt.wantMessage)
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ const (
 	AccessTokenExpiry          = time.Minute * 15
 	RefreshTokenExpiry         = time.Hour * 24 * 7
 	RefreshTokenReuseThreshold = time.Hour * 24
+	PendingLoginExpiry         = time.Minute * 5
 	AccessTokenType            = "access"
 	RefreshTokenType           = "refresh"
 )
@@ -38,6 +39,19 @@ type TokenPair struct {
 	RefreshToken string
 }
 
+// PendingLogin is a login that passed the password check and is waiting on
+// the second factor
+type PendingLogin struct {
+	UserID string
+	Email  string
+}
+
+type Session struct {
+	ID        string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
 type AuthService struct {
 	kv      valkey.Client
 	log     zerolog.Logger
@@ -50,6 +64,7 @@ var (
 	ErrTokenExpired       = errors.New("token expired")
 	ErrTokenNotFound      = errors.New("token not found in storage")
 	ErrTokenMismatch      = errors.New("token does not match stored token")
+	ErrLoginNotPending    = errors.New("login is not pending or has expired")
 )
 
 func NewAuthService(kv valkey.Client, secrets Secrets) *AuthService {
@@ -168,6 +183,77 @@ func (s *AuthService) RevokeTokens(ctx context.Context, userID string) error {
 	return nil
 }
 
+// ListSessions returns the sessions backed by a stored refresh token; a user
+// has at most one at a time
+func (s *AuthService) ListSessions(ctx context.Context, userID string) ([]Session, error) {
+	storedToken, err := s.kv.Do(ctx, s.kv.B().Get().Key(userID).Build()).ToString()
+	if err != nil {
+		if valkey.IsValkeyNil(err) {
+			return []Session{}, nil
+		}
+		s.log.Error().Err(err).Str("userId", userID).Msg("failed to get stored token")
+		return nil, err
+	}
+
+	var claims Claims
+	if _, _, err := jwt.NewParser().ParseUnverified(storedToken, &claims); err != nil {
+		s.log.Error().Err(err).Str("userId", userID).Msg("stored token could not be parsed")
+		return nil, ErrInvalidToken
+	}
+
+	session := Session{ID: claims.ID}
+	if claims.IssuedAt != nil {
+		session.IssuedAt = claims.IssuedAt.Time
+	}
+	if claims.ExpiresAt != nil {
+		session.ExpiresAt = claims.ExpiresAt.Time
+	}
+
+	return []Session{session}, nil
+}
+
+// StartPendingLogin parks a login until the second factor is checked and
+// returns the opaque token the client has to present with the code
+func (s *AuthService) StartPendingLogin(ctx context.Context, userID, email string) (string, error) {
+	token := uuid.New().String()
+	key := pendingLoginKey(token)
+
+	for _, res := range s.kv.DoMulti(ctx,
+		s.kv.B().Hset().Key(key).FieldValue().FieldValue("user_id", userID).FieldValue("email", email).Build(),
+		s.kv.B().Expire().Key(key).Seconds(int64(PendingLoginExpiry.Seconds())).Build(),
+	) {
+		if err := res.Error(); err != nil {
+			s.log.Error().Err(err).Str("userId", userID).Msg("failed to store pending login")
+			return "", err
+		}
+	}
+
+	return token, nil
+}
+
+func (s *AuthService) GetPendingLogin(ctx context.Context, token string) (*PendingLogin, error) {
+	fields, err := s.kv.Do(ctx, s.kv.B().Hgetall().Key(pendingLoginKey(token)).Build()).AsStrMap()
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to get pending login")
+		return nil, err
+	}
+	if fields["user_id"] == "" {
+		return nil, ErrLoginNotPending
+	}
+
+	return &PendingLogin{UserID: fields["user_id"], Email: fields["email"]}, nil
+}
+
+func (s *AuthService) ClearPendingLogin(ctx context.Context, token string) {
+	if err := s.kv.Do(ctx, s.kv.B().Del().Key(pendingLoginKey(token)).Build()).Error(); err != nil {
+		s.log.Error().Err(err).Msg("failed to clear pending login")
+	}
+}
+
+func pendingLoginKey(token string) string {
+	return "login:pending:" + token
+}
+
 func (s *AuthService) generateAccessToken(userID, email string) (string, error) {
 	accessTokenClaims := Claims{
 		UserID: userID,
@@ -9,6 +9,7 @@ package proto
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -366,11 +367,159 @@ func (x *RevokeTokensResponse) GetSuccess() bool {
 	return false
 }
 
+type ListSessionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSessionsRequest) Reset() {
+	*x = ListSessionsRequest{}
+	mi := &file_auth_proto_auth_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionsRequest) ProtoMessage() {}
+
+func (x *ListSessionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_proto_auth_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSessionsRequest.ProtoReflect.Descriptor instead.
+func (*ListSessionsRequest) Descriptor() ([]byte, []int) {
+	return file_auth_proto_auth_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListSessionsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type Session struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	IssuedAt      *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=issued_at,json=issuedAt,proto3" json:"issued_at,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Session) Reset() {
+	*x = Session{}
+	mi := &file_auth_proto_auth_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Session) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Session) ProtoMessage() {}
+
+func (x *Session) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_proto_auth_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Session.ProtoReflect.Descriptor instead.
+func (*Session) Descriptor() ([]byte, []int) {
+	return file_auth_proto_auth_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Session) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *Session) GetIssuedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.IssuedAt
+	}
+	return nil
+}
+
+func (x *Session) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type ListSessionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sessions      []*Session             `protobuf:"bytes,1,rep,name=sessions,proto3" json:"sessions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSessionsResponse) Reset() {
+	*x = ListSessionsResponse{}
+	mi := &file_auth_proto_auth_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionsResponse) ProtoMessage() {}
+
+func (x *ListSessionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_proto_auth_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSessionsResponse.ProtoReflect.Descriptor instead.
+func (*ListSessionsResponse) Descriptor() ([]byte, []int) {
+	return file_auth_proto_auth_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListSessionsResponse) GetSessions() []*Session {
+	if x != nil {
+		return x.Sessions
+	}
+	return nil
+}
+
 var File_auth_proto_auth_proto protoreflect.FileDescriptor
 
 const file_auth_proto_auth_proto_rawDesc = "" +
 	"\n" +
-	"\x15auth/proto/auth.proto\x12\x04auth\"S\n" +
+	"\x15auth/proto/auth.proto\x12\x04auth\x1a\x1fgoogle/protobuf/timestamp.proto\"S\n" +
 	"\tTokenPair\x12!\n" +
 	"\faccess_token\x18\x01 \x01(\tR\vaccessToken\x12#\n" +
 	"\rrefresh_token\x18\x02 \x01(\tR\frefreshToken\"E\n" +
@@ -394,12 +543,23 @@ const file_auth_proto_auth_proto_rawDesc = "" +
 	"\b_user_idB\r\n" +
 	"\v_token_pair\"0\n" +
 	"\x14RevokeTokensResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess2\x97\x02\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\".\n" +
+	"\x13ListSessionsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"\x9c\x01\n" +
+	"\aSession\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x127\n" +
+	"\tissued_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\bissuedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"A\n" +
+	"\x14ListSessionsResponse\x12)\n" +
+	"\bsessions\x18\x01 \x03(\v2\r.auth.SessionR\bsessions2\xde\x02\n" +
 	"\vAuthService\x12C\n" +
 	"\x0fValidateSession\x12\x0f.auth.TokenPair\x1a\x1d.auth.ValidateSessionResponse\"\x00\x12>\n" +
 	"\rGenerateToken\x12\x1a.auth.GenerateTokenRequest\x1a\x0f.auth.TokenPair\"\x00\x12<\n" +
 	"\fRefreshToken\x12\x19.auth.RefreshTokenRequest\x1a\x0f.auth.TokenPair\"\x00\x12E\n" +
-	"\fRevokeTokens\x12\x19.auth.RevokeTokensRequest\x1a\x1a.auth.RevokeTokensResponseB\fZ\n" +
+	"\fRevokeTokens\x12\x19.auth.RevokeTokensRequest\x1a\x1a.auth.RevokeTokensResponse\x12E\n" +
+	"\fListSessions\x12\x19.auth.ListSessionsRequest\x1a\x1a.auth.ListSessionsResponseB\fZ\n" +
 	"auth/protob\x06proto3"
 
 var (
@@ -415,7 +575,7 @@ func file_auth_proto_auth_proto_rawDescGZIP() []byte {
 }
 
 var file_auth_proto_auth_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_auth_proto_auth_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_auth_proto_auth_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_auth_proto_auth_proto_goTypes = []any{
 	(ValidateSessionResponse_Status)(0), // 0: auth.ValidateSessionResponse.Status
 	(*TokenPair)(nil),                   // 1: auth.TokenPair
@@ -424,23 +584,32 @@ var file_auth_proto_auth_proto_goTypes = []any{
 	(*RevokeTokensRequest)(nil),         // 4: auth.RevokeTokensRequest
 	(*ValidateSessionResponse)(nil),     // 5: auth.ValidateSessionResponse
 	(*RevokeTokensResponse)(nil),        // 6: auth.RevokeTokensResponse
+	(*ListSessionsRequest)(nil),         // 7: auth.ListSessionsRequest
+	(*Session)(nil),                     // 8: auth.Session
+	(*ListSessionsResponse)(nil),        // 9: auth.ListSessionsResponse
+	(*timestamppb.Timestamp)(nil),       // 10: google.protobuf.Timestamp
 }
 var file_auth_proto_auth_proto_depIdxs = []int32{
-	0, // 0: auth.ValidateSessionResponse.status:type_name -> auth.ValidateSessionResponse.Status
-	1, // 1: auth.ValidateSessionResponse.token_pair:type_name -> auth.TokenPair
-	1, // 2: auth.AuthService.ValidateSession:input_type -> auth.TokenPair
-	2, // 3: auth.AuthService.GenerateToken:input_type -> auth.GenerateTokenRequest
-	3, // 4: auth.AuthService.RefreshToken:input_type -> auth.RefreshTokenRequest
-	4, // 5: auth.AuthService.RevokeTokens:input_type -> auth.RevokeTokensRequest
-	5, // 6: auth.AuthService.ValidateSession:output_type -> auth.ValidateSessionResponse
-	1, // 7: auth.AuthService.GenerateToken:output_type -> auth.TokenPair
-	1, // 8: auth.AuthService.RefreshToken:output_type -> auth.TokenPair
-	6, // 9: auth.AuthService.RevokeTokens:output_type -> auth.RevokeTokensResponse
-	6, // [6:10] is the sub-list for method output_type
-	2, // [2:6] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	0,  // 0: auth.ValidateSessionResponse.status:type_name -> auth.ValidateSessionResponse.Status
+	1,  // 1: auth.ValidateSessionResponse.token_pair:type_name -> auth.TokenPair
+	10, // 2: auth.Session.issued_at:type_name -> google.protobuf.Timestamp
+	10, // 3: auth.Session.expires_at:type_name -> google.protobuf.Timestamp
+	8,  // 4: auth.ListSessionsResponse.sessions:type_name -> auth.Session
+	1,  // 5: auth.AuthService.ValidateSession:input_type -> auth.TokenPair
+	2,  // 6: auth.AuthService.GenerateToken:input_type -> auth.GenerateTokenRequest
+	3,  // 7: auth.AuthService.RefreshToken:input_type -> auth.RefreshTokenRequest
+	4,  // 8: auth.AuthService.RevokeTokens:input_type -> auth.RevokeTokensRequest
+	7,  // 9: auth.AuthService.ListSessions:input_type -> auth.ListSessionsRequest
+	5,  // 10: auth.AuthService.ValidateSession:output_type -> auth.ValidateSessionResponse
+	1,  // 11: auth.AuthService.GenerateToken:output_type -> auth.TokenPair
+	1,  // 12: auth.AuthService.RefreshToken:output_type -> auth.TokenPair
+	6,  // 13: auth.AuthService.RevokeTokens:output_type -> auth.RevokeTokensResponse
+	9,  // 14: auth.AuthService.ListSessions:output_type -> auth.ListSessionsResponse
+	10, // [10:15] is the sub-list for method output_type
+	5,  // [5:10] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
 }
 
 func init() { file_auth_proto_auth_proto_init() }
@@ -455,7 +624,7 @@ func file_auth_proto_auth_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_auth_proto_auth_proto_rawDesc), len(file_auth_proto_auth_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   6,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
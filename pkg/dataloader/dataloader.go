@@ -0,0 +1,115 @@
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrNotFound = errors.New("dataloader: key not found")
+
+// BatchFunc fetches all keys in one go. Keys missing from the returned map
+// resolve to ErrNotFound for their callers.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader collapses concurrent Load calls that arrive within the wait window
+// into a single BatchFunc call of at most maxBatch keys
+type Loader[K comparable, V any] struct {
+	fetch    BatchFunc[K, V]
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	current *batch[K, V]
+}
+
+type batch[K comparable, V any] struct {
+	ctx     context.Context
+	keys    []K
+	seen    map[K]struct{}
+	timer   *time.Timer
+	done    chan struct{}
+	results map[K]V
+	err     error
+}
+
+func New[K comparable, V any](fetch BatchFunc[K, V], wait time.Duration, maxBatch int) *Loader[K, V] {
+	if maxBatch <= 0 {
+		maxBatch = 100
+	}
+	return &Loader[K, V]{
+		fetch:    fetch,
+		wait:     wait,
+		maxBatch: maxBatch,
+	}
+}
+
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	b := l.enqueue(ctx, key)
+
+	var zero V
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case <-b.done:
+	}
+
+	if b.err != nil {
+		return zero, b.err
+	}
+	v, ok := b.results[key]
+	if !ok {
+		return zero, ErrNotFound
+	}
+	return v, nil
+}
+
+func (l *Loader[K, V]) enqueue(ctx context.Context, key K) *batch[K, V] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.current
+	if b == nil {
+		b = &batch[K, V]{
+			// the batch outlives the caller that opened it, so it keeps the
+			// caller's values for tracing but not its cancellation
+			ctx:  context.WithoutCancel(ctx),
+			seen: make(map[K]struct{}),
+			done: make(chan struct{}),
+		}
+		b.timer = time.AfterFunc(l.wait, func() { l.dispatch(b) })
+		l.current = b
+	}
+
+	if _, ok := b.seen[key]; !ok {
+		b.seen[key] = struct{}{}
+		b.keys = append(b.keys, key)
+	}
+
+	if len(b.keys) >= l.maxBatch {
+		b.timer.Stop()
+		l.current = nil
+		go l.run(b)
+	}
+
+	return b
+}
+
+func (l *Loader[K, V]) dispatch(b *batch[K, V]) {
+	l.mu.Lock()
+	if l.current != b {
+		// already sent off because it filled up
+		l.mu.Unlock()
+		return
+	}
+	l.current = nil
+	l.mu.Unlock()
+
+	l.run(b)
+}
+
+func (l *Loader[K, V]) run(b *batch[K, V]) {
+	b.results, b.err = l.fetch(b.ctx, b.keys)
+	close(b.done)
+}
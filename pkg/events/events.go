@@ -0,0 +1,206 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/lmnzx/slopify/pkg/logger"
+
+	"github.com/rs/zerolog"
+	"github.com/valkey-io/valkey-go"
+)
+
+// Event is one entry on a stream. Delivery is at least once, so consumers
+// should use ID to drop events they have already handled.
+type Event struct {
+	ID          string
+	Type        string
+	AggregateID string
+	OccurredAt  time.Time
+	Payload     json.RawMessage
+
+	// StreamID is the id the stream assigned to the entry
+	StreamID string
+}
+
+// MaxStreamLength is roughly how many entries a stream keeps, older ones are
+// trimmed as new ones are added. A consumer group that falls further behind
+// than this loses the trimmed events.
+const MaxStreamLength = 100_000
+
+// Publish appends the event to the stream, trimming it to about
+// MaxStreamLength entries
+func Publish(ctx context.Context, kv valkey.Client, stream string, e Event) error {
+	// MAXLEN ~ only trims whole macro nodes, which keeps it cheap
+	cmd := kv.B().Xadd().Key(stream).Maxlen().Almost().Threshold(strconv.Itoa(MaxStreamLength)).Id("*").
+		FieldValue().
+		FieldValue("event_id", e.ID).
+		FieldValue("type", e.Type).
+		FieldValue("aggregate_id", e.AggregateID).
+		FieldValue("occurred_at", e.OccurredAt.UTC().Format(time.RFC3339Nano)).
+		FieldValue("payload", string(e.Payload)).
+		Build()
+
+	return kv.Do(ctx, cmd).Error()
+}
+
+func decode(entry valkey.XRangeEntry) Event {
+	e := Event{
+		ID:          entry.FieldValues["event_id"],
+		Type:        entry.FieldValues["type"],
+		AggregateID: entry.FieldValues["aggregate_id"],
+		Payload:     json.RawMessage(entry.FieldValues["payload"]),
+		StreamID:    entry.ID,
+	}
+	e.OccurredAt, _ = time.Parse(time.RFC3339Nano, entry.FieldValues["occurred_at"])
+	return e
+}
+
+// Handler processes one event. Returning an error leaves the event pending
+// so it is delivered again once it has been idle for ClaimIdle.
+type Handler func(ctx context.Context, e Event) error
+
+type ConsumerConfig struct {
+	Stream string
+	Group  string
+	// Name identifies this consumer within the group, it should be stable
+	// across restarts so its pending events are picked up again
+	Name string
+
+	BatchSize int64
+	Block     time.Duration
+	// ClaimIdle is how long an event may sit unacknowledged with another
+	// consumer before this one takes it over
+	ClaimIdle time.Duration
+}
+
+type Consumer struct {
+	kv      valkey.Client
+	cfg     ConsumerConfig
+	handler Handler
+	log     zerolog.Logger
+
+	// claimCursor is where the next XAUTOCLAIM scan of the pending list starts
+	claimCursor string
+}
+
+func NewConsumer(kv valkey.Client, cfg ConsumerConfig, handler Handler) *Consumer {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 10
+	}
+	if cfg.Block <= 0 {
+		cfg.Block = 5 * time.Second
+	}
+	if cfg.ClaimIdle <= 0 {
+		cfg.ClaimIdle = time.Minute
+	}
+	return &Consumer{
+		kv:          kv,
+		cfg:         cfg,
+		handler:     handler,
+		log:         logger.GetLogger(),
+		claimCursor: "0-0",
+	}
+}
+
+// Run reads from the stream as part of the consumer group until ctx is
+// cancelled. The group is created at the end of the stream if it is missing.
+func (c *Consumer) Run(ctx context.Context) error {
+	err := c.kv.Do(ctx, c.kv.B().XgroupCreate().Key(c.cfg.Stream).Group(c.cfg.Group).Id("$").Mkstream().Build()).Error()
+	if err != nil && !valkey.IsValkeyBusyGroup(err) {
+		return err
+	}
+
+	c.log.Info().Str("stream", c.cfg.Stream).Str("group", c.cfg.Group).Str("consumer", c.cfg.Name).Msg("event consumer started")
+
+	for {
+		if ctx.Err() != nil {
+			c.log.Info().Str("stream", c.cfg.Stream).Str("group", c.cfg.Group).Msg("event consumer stopped")
+			return nil
+		}
+
+		if err := c.claimStale(ctx); err != nil && ctx.Err() == nil {
+			c.log.Error().Err(err).Str("stream", c.cfg.Stream).Msg("failed to claim stale events")
+		}
+
+		if err := c.readNew(ctx); err != nil && ctx.Err() == nil {
+			c.log.Error().Err(err).Str("stream", c.cfg.Stream).Msg("failed to read events")
+			// don't spin on a broken connection
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+		}
+	}
+}
+
+func (c *Consumer) readNew(ctx context.Context) error {
+	cmd := c.kv.B().Xreadgroup().Group(c.cfg.Group, c.cfg.Name).
+		Count(c.cfg.BatchSize).
+		Block(c.cfg.Block.Milliseconds()).
+		Streams().Key(c.cfg.Stream).Id(">").
+		Build()
+
+	streams, err := c.kv.Do(ctx, cmd).AsXRead()
+	if err != nil {
+		// nothing arrived before the block timed out
+		if valkey.IsValkeyNil(err) {
+			return nil
+		}
+		return err
+	}
+
+	c.handle(ctx, streams[c.cfg.Stream])
+	return nil
+}
+
+// claimStale takes over events another consumer read but never acknowledged,
+// usually because it crashed or its handler failed
+func (c *Consumer) claimStale(ctx context.Context) error {
+	cmd := c.kv.B().Xautoclaim().Key(c.cfg.Stream).Group(c.cfg.Group).Consumer(c.cfg.Name).
+		MinIdleTime(strconv.FormatInt(c.cfg.ClaimIdle.Milliseconds(), 10)).
+		Start(c.claimCursor).
+		Count(c.cfg.BatchSize).
+		Build()
+
+	reply, err := c.kv.Do(ctx, cmd).ToArray()
+	if err != nil {
+		return err
+	}
+	if len(reply) < 2 {
+		return errors.New("events: unexpected XAUTOCLAIM reply")
+	}
+
+	// the scan wraps around to 0-0 once it reaches the end of the list
+	next, err := reply[0].ToString()
+	if err != nil {
+		return err
+	}
+	c.claimCursor = next
+
+	entries, err := reply[1].AsXRange()
+	if err != nil {
+		return err
+	}
+
+	c.handle(ctx, entries)
+	return nil
+}
+
+func (c *Consumer) handle(ctx context.Context, entries []valkey.XRangeEntry) {
+	for _, entry := range entries {
+		e := decode(entry)
+
+		if err := c.handler(ctx, e); err != nil {
+			c.log.Error().Err(err).Str("stream", c.cfg.Stream).Str("eventId", e.ID).Str("type", e.Type).Msg("event handler failed")
+			continue
+		}
+
+		if err := c.kv.Do(ctx, c.kv.B().Xack().Key(c.cfg.Stream).Group(c.cfg.Group).Id(entry.ID).Build()).Error(); err != nil {
+			c.log.Error().Err(err).Str("stream", c.cfg.Stream).Str("eventId", e.ID).Msg("failed to acknowledge event")
+		}
+	}
+}
@@ -41,6 +41,9 @@ var (
 	grpcRequestCounter  metric.Int64Counter
 	grpcRequestDuration metric.Float64Histogram
 	grpcActiveRequests  metric.Int64UpDownCounter
+	indexSyncLag        metric.Float64Gauge
+	indexSyncPending    metric.Int64Gauge
+	cacheLookups        metric.Int64Counter
 )
 
 func Init(config InstrumentationConfig) (func(), error) {
@@ -167,6 +170,27 @@ func Init(config InstrumentationConfig) (func(), error) {
 		return nil, fmt.Errorf("failed to create metrics: %v, %v, %v", err1, err2, err3)
 	}
 
+	indexSyncLag, err1 = meter.Float64Gauge(
+		"search.index.sync.lag",
+		metric.WithDescription("Age of the oldest change not yet applied to the search index"),
+		metric.WithUnit("s"),
+	)
+
+	indexSyncPending, err2 = meter.Int64Gauge(
+		"search.index.sync.pending",
+		metric.WithDescription("Number of changes waiting to be applied to the search index"),
+		metric.WithUnit("{change}"),
+	)
+
+	cacheLookups, err3 = meter.Int64Counter(
+		"cache.lookups",
+		metric.WithDescription("Number of cache lookups by result"),
+		metric.WithUnit("{lookup}"),
+	)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return nil, fmt.Errorf("failed to create metrics: %v, %v, %v", err1, err2, err3)
+	}
+
 	return func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
@@ -420,3 +444,32 @@ func UnaryClientInstrumentationMiddleware(serviceName string) grpc.UnaryClientIn
 		return err
 	}
 }
+
+// RecordIndexSyncLag reports how far a search index is behind its source of
+// truth. It is a no-op until Init has been called.
+func RecordIndexSyncLag(ctx context.Context, index string, lag time.Duration, pending int64) {
+	if indexSyncLag == nil || indexSyncPending == nil {
+		return
+	}
+
+	attrs := metric.WithAttributes(attribute.String("search.index", index))
+	indexSyncLag.Record(ctx, lag.Seconds(), attrs)
+	indexSyncPending.Record(ctx, pending, attrs)
+}
+
+// RecordCacheLookup counts a lookup in the named cache as a hit or a miss, the
+// hit ratio is hit / (hit + miss). It is a no-op until Init has been called.
+func RecordCacheLookup(ctx context.Context, cache string, hit bool) {
+	if cacheLookups == nil {
+		return
+	}
+
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cacheLookups.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("cache.name", cache),
+		attribute.String("cache.result", result),
+	))
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	auth "github.com/lmnzx/slopify/auth/proto"
+	"github.com/lmnzx/slopify/pkg/logger"
+	"github.com/lmnzx/slopify/pkg/response"
+
+	"github.com/valyala/fasthttp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// AdminMiddleware only lets through users whose id is in adminIDs. It relies on
+// the user id set by RequireAuth, so it has to be wrapped inside it.
+func AdminMiddleware(adminIDs []string) func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	res := response.NewResponseSender()
+
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			log := logger.GetLogger()
+
+			userID := GetUserIDFromCtx(ctx)
+			if userID == "" {
+				res.SendError(ctx, fasthttp.StatusUnauthorized, "user is not logged in")
+				return
+			}
+
+			if !IsAdmin(adminIDs, userID) {
+				log.Warn().Str("user_id", userID).Str("path", string(ctx.Path())).Msg("adminMiddleware: non admin user denied")
+				res.SendError(ctx, fasthttp.StatusForbidden, "admin access required")
+				return
+			}
+
+			next(ctx)
+		}
+	}
+}
+
+func IsAdmin(adminIDs []string, userID string) bool {
+	return userID != "" && slices.Contains(adminIDs, userID)
+}
+
+// AuthorizeAdminRPC is the gRPC counterpart of AdminMiddleware. The caller has
+// to send its access token as "authorization: Bearer <token>" metadata.
+func AuthorizeAdminRPC(ctx context.Context, authService auth.AuthServiceClient, adminIDs []string) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "missing metadata")
+	}
+
+	values := md.Get("authorization")
+	if len(values) == 0 || !strings.HasPrefix(values[0], "Bearer ") {
+		return "", status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+
+	r, err := authService.ValidateSession(ctx, &auth.TokenPair{
+		AccessToken: strings.TrimPrefix(values[0], "Bearer "),
+	})
+	if err != nil || r == nil || r.Status != auth.ValidateSessionResponse_VALID {
+		return "", status.Error(codes.Unauthenticated, "invalid session")
+	}
+
+	if !IsAdmin(adminIDs, r.GetUserId()) {
+		return "", status.Error(codes.PermissionDenied, "admin access required")
+	}
+
+	return r.GetUserId(), nil
+}
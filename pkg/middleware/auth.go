@@ -7,86 +7,135 @@ import (
 	auth "github.com/lmnzx/slopify/auth/proto"
 	"github.com/lmnzx/slopify/pkg/cookie"
 	"github.com/lmnzx/slopify/pkg/logger"
+	"github.com/lmnzx/slopify/pkg/response"
 
 	"github.com/valyala/fasthttp"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const UserIDCtxKey string = "user_id"
 const TracingCtxKey string = "tracing_context"
 
-func AuthMiddleware(authService auth.AuthServiceClient, serviceName string) func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
-	tracer := otel.Tracer(serviceName)
+// OptionalAuth identifies the user when the request has a valid session and
+// lets the request through either way. Anonymous requests get an empty user
+// id, so GetUserIDFromCtx returns "" for them. So do requests whose session
+// couldn't be checked because the auth service is unavailable, browsing
+// carries on without personalisation.
+func OptionalAuth(authService auth.AuthServiceClient, serviceName string) func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	authenticate := newAuthenticator(authService, serviceName)
 
 	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
 		return func(ctx *fasthttp.RequestCtx) {
-			log := logger.GetLogger()
-			log.Info().Msg("auth middleware executing")
-
-			var spanCtx context.Context
-			parentCtxVal := ctx.UserValue(TracingCtxKey)
-			if parentCtxVal != nil {
-				if parentContext, ok := parentCtxVal.(context.Context); ok {
-					spanCtx = parentContext
-				} else {
-					spanCtx = context.Background()
-				}
-			} else {
-				spanCtx = context.Background()
-			}
+			userID, _ := authenticate(ctx)
+			ctx.SetUserValue(UserIDCtxKey, userID)
+			next(ctx)
+		}
+	}
+}
 
-			_, span := tracer.Start(
-				spanCtx,
-				"AuthMiddleware",
-				trace.WithAttributes(
-					attribute.String("middleware", "auth"),
-					attribute.String("http.path", string(ctx.Path())),
-					attribute.String("http.method", string(ctx.Method())),
-				),
-			)
-			defer span.End()
-
-			accessToken := cookie.Get(ctx, "access_token")
-			refreshToken := cookie.Get(ctx, "refresh_token")
-
-			if accessToken == "" && refreshToken == "" {
-				log.Warn().Msg("authMiddleware: access or refresh token missing from cookies")
-				span.SetAttributes(attribute.Bool("auth.success", false))
-				ctx.SetUserValue(UserIDCtxKey, "")
-				next(ctx)
+// RequireAuth only lets through requests with a valid session, anonymous
+// ones are answered with a 401. When the session can't be checked it answers
+// with a 503 rather than signing the user out. Handlers behind it always have
+// a user id.
+func RequireAuth(authService auth.AuthServiceClient, serviceName string) func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	authenticate := newAuthenticator(authService, serviceName)
+	res := response.NewResponseSender()
+
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			userID, err := authenticate(ctx)
+			if err != nil {
+				res.SendError(ctx, fasthttp.StatusServiceUnavailable, "unable to check the session, try again later")
 				return
 			}
-
-			_, authSpan := tracer.Start(
-				trace.ContextWithSpan(spanCtx, span),
-				"ValidateSession",
-			)
-
-			r, err := authService.ValidateSession(ctx, &auth.TokenPair{
-				AccessToken:  accessToken,
-				RefreshToken: refreshToken,
-			})
-			if err != nil || r.Status != auth.ValidateSessionResponse_VALID || r == nil {
-				log.Warn().Msg("authMiddleware: session validation failed")
-				authSpan.SetAttributes(attribute.String("auth.middleware", "session validation failed"))
-				ctx.SetUserValue(UserIDCtxKey, "")
-				next(ctx)
+			if userID == "" {
+				res.SendError(ctx, fasthttp.StatusUnauthorized, "user is not logged in")
 				return
 			}
 
-			cookie.Set(ctx, "access_token", r.TokenPair.AccessToken, "/", "", time.Minute*15, false, fasthttp.CookieSameSiteDefaultMode)
-			if refreshToken != r.TokenPair.RefreshToken {
-				cookie.Set(ctx, "refresh_token", r.TokenPair.RefreshToken, "/", "", time.Hour*24*7, false, fasthttp.CookieSameSiteDefaultMode)
+			ctx.SetUserValue(UserIDCtxKey, userID)
+			next(ctx)
+		}
+	}
+}
+
+// newAuthenticator returns a func that validates the session cookies of a
+// request, refreshing them when the auth service rotated the tokens. It
+// returns the user id, or "" when there is no valid session. The error is set
+// only when the auth service couldn't answer, an expired or invalid session is
+// not an error.
+func newAuthenticator(authService auth.AuthServiceClient, serviceName string) func(ctx *fasthttp.RequestCtx) (string, error) {
+	tracer := otel.Tracer(serviceName)
+
+	return func(ctx *fasthttp.RequestCtx) (string, error) {
+		log := logger.GetLogger()
+
+		var spanCtx context.Context
+		parentCtxVal := ctx.UserValue(TracingCtxKey)
+		if parentCtxVal != nil {
+			if parentContext, ok := parentCtxVal.(context.Context); ok {
+				spanCtx = parentContext
+			} else {
+				spanCtx = context.Background()
 			}
+		} else {
+			spanCtx = context.Background()
+		}
 
-			ctx.SetUserValue(UserIDCtxKey, *r.UserId)
-			authSpan.SetAttributes(attribute.String("auth.user_id", *r.UserId))
-			authSpan.End()
+		_, span := tracer.Start(
+			spanCtx,
+			"AuthMiddleware",
+			trace.WithAttributes(
+				attribute.String("middleware", "auth"),
+				attribute.String("http.path", string(ctx.Path())),
+				attribute.String("http.method", string(ctx.Method())),
+			),
+		)
+		defer span.End()
+
+		accessToken := cookie.Get(ctx, "access_token")
+		refreshToken := cookie.Get(ctx, "refresh_token")
+
+		if accessToken == "" && refreshToken == "" {
+			span.SetAttributes(attribute.Bool("auth.success", false))
+			return "", nil
+		}
 
-			next(ctx)
+		_, authSpan := tracer.Start(
+			trace.ContextWithSpan(spanCtx, span),
+			"ValidateSession",
+		)
+		defer authSpan.End()
+
+		r, err := authService.ValidateSession(ctx, &auth.TokenPair{
+			AccessToken:  accessToken,
+			RefreshToken: refreshToken,
+		})
+		// the auth service rejects sessions with Unauthenticated, any other
+		// error means it wasn't reached or failed
+		if err != nil && status.Code(err) != codes.Unauthenticated {
+			log.Error().Err(err).Msg("authMiddleware: session validation unavailable")
+			authSpan.RecordError(err)
+			authSpan.SetAttributes(attribute.String("auth.middleware", "session validation unavailable"))
+			return "", err
+		}
+		if err != nil || r == nil || r.Status != auth.ValidateSessionResponse_VALID {
+			log.Warn().Msg("authMiddleware: session validation failed")
+			authSpan.SetAttributes(attribute.String("auth.middleware", "session validation failed"))
+			return "", nil
 		}
+
+		cookie.Set(ctx, "access_token", r.TokenPair.AccessToken, "/", "", time.Minute*15, false, fasthttp.CookieSameSiteDefaultMode)
+		if refreshToken != r.TokenPair.RefreshToken {
+			cookie.Set(ctx, "refresh_token", r.TokenPair.RefreshToken, "/", "", time.Hour*24*7, false, fasthttp.CookieSameSiteDefaultMode)
+		}
+
+		authSpan.SetAttributes(attribute.String("auth.user_id", *r.UserId))
+		return *r.UserId, nil
 	}
 }
 
@@ -0,0 +1,262 @@
+// Package money represents prices as integer amounts of a currency's minor
+// unit, so sums and discounts never pass through a float.
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lmnzx/slopify/pkg/money/proto"
+)
+
+var (
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrInvalidPercent   = errors.New("percentage must be between 0 and 100 with at most two decimal places")
+	ErrOverflow         = errors.New("amount out of range")
+)
+
+// Currency is an ISO 4217 code
+type Currency string
+
+const DefaultCurrency Currency = "USD"
+
+// exponents is the number of minor unit digits of the supported currencies
+var exponents = map[Currency]int{
+	"AUD": 2,
+	"CAD": 2,
+	"CHF": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"INR": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"SEK": 2,
+	"USD": 2,
+}
+
+func ParseCurrency(code string) (Currency, error) {
+	c := Currency(strings.ToUpper(strings.TrimSpace(code)))
+	if !c.Valid() {
+		return "", fmt.Errorf("%w: %q", ErrUnknownCurrency, code)
+	}
+	return c, nil
+}
+
+func (c Currency) Valid() bool {
+	_, ok := exponents[c]
+	return ok
+}
+
+// Exponent is the number of minor unit digits, 2 for USD and 0 for JPY
+func (c Currency) Exponent() int {
+	return exponents[c]
+}
+
+// Currencies returns the supported currencies in order
+func Currencies() []Currency {
+	return slices.Sorted(maps.Keys(exponents))
+}
+
+// Minor is an amount in a currency's minor unit, cents for USD
+type Minor int64
+
+type Money struct {
+	Amount   Minor
+	Currency Currency
+}
+
+func New(amount Minor, currency Currency) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// maxMajorDigits keeps parsed amounts well inside int64
+const maxMajorDigits = 15
+
+// Parse reads a decimal amount in major units, "19.99" in USD is 1999. More
+// decimal places than the currency has are rejected rather than rounded.
+func Parse(s string, currency Currency) (Money, error) {
+	if !currency.Valid() {
+		return Money{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, currency)
+	}
+
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	major, frac, _ := strings.Cut(s, ".")
+	exp := currency.Exponent()
+	if major == "" || len(major) > maxMajorDigits || len(frac) > exp || !isDigits(major) || !isDigits(frac) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+
+	amount, err := strconv.ParseInt(major+frac+strings.Repeat("0", exp-len(frac)), 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	if negative {
+		amount = -amount
+	}
+	return Money{Amount: Minor(amount), Currency: currency}, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Decimal formats the amount in major units, 1999 USD is "19.99"
+func (m Money) Decimal() string {
+	amount := int64(m.Amount)
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	exp := m.Currency.Exponent()
+	digits := strconv.FormatInt(amount, 10)
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + string(m.Currency)
+}
+
+// Float64 is the amount in major units. It is meant for search filters and
+// sorting, never for arithmetic.
+func (m Money) Float64() float64 {
+	return float64(m.Amount) / math.Pow10(m.Currency.Exponent())
+}
+
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	sum := m.Amount + o.Amount
+	if (o.Amount > 0 && sum < m.Amount) || (o.Amount < 0 && sum > m.Amount) {
+		return Money{}, fmt.Errorf("%w: %s + %s", ErrOverflow, m, o)
+	}
+	return Money{Amount: sum, Currency: m.Currency}, nil
+}
+
+// Mul returns the amount times n, a quantity for line totals
+func (m Money) Mul(n int64) (Money, error) {
+	a := int64(m.Amount)
+	product := a * n
+	if a != 0 && (product/a != n || (a == -1 && n == math.MinInt64) || (n == -1 && a == math.MinInt64)) {
+		return Money{}, fmt.Errorf("%w: %s * %d", ErrOverflow, m, n)
+	}
+	return Money{Amount: Minor(product), Currency: m.Currency}, nil
+}
+
+// ApplyDiscount returns the price after taking d off, rounded half up to the
+// minor unit. The amount is split into whole and fractional multiples of
+// MaxBasisPoints so that scaling it cannot overflow, the result is never
+// larger than the amount.
+func (m Money) ApplyDiscount(d BasisPoints) Money {
+	d = min(max(d, 0), MaxBasisPoints)
+	kept := int64(MaxBasisPoints - d)
+	whole, rest := int64(m.Amount)/int64(MaxBasisPoints), int64(m.Amount)%int64(MaxBasisPoints)
+
+	// rest has the sign of the amount, so rounding it rounds the total
+	remaining := rest * kept
+	half := int64(MaxBasisPoints / 2)
+	if remaining < 0 {
+		half = -half
+	}
+	return Money{Amount: Minor(whole*kept + (remaining+half)/int64(MaxBasisPoints)), Currency: m.Currency}
+}
+
+type moneyJSON struct {
+	Amount   *int64 `json:"amount"`
+	Currency string `json:"currency"`
+	// Decimal is only written, for display
+	Decimal string `json:"decimal,omitempty"`
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	amount := int64(m.Amount)
+	return json.Marshal(moneyJSON{Amount: &amount, Currency: string(m.Currency), Decimal: m.Decimal()})
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var v moneyJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Amount == nil {
+		return fmt.Errorf("%w: amount is required", ErrInvalidAmount)
+	}
+
+	currency, err := ParseCurrency(v.Currency)
+	if err != nil {
+		return err
+	}
+
+	*m = Money{Amount: Minor(*v.Amount), Currency: currency}
+	return nil
+}
+
+func (m Money) Proto() *proto.Money {
+	return &proto.Money{Amount: int64(m.Amount), Currency: string(m.Currency)}
+}
+
+func FromProto(p *proto.Money) (Money, error) {
+	if p == nil {
+		return Money{}, fmt.Errorf("%w: missing", ErrInvalidAmount)
+	}
+
+	currency, err := ParseCurrency(p.Currency)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: Minor(p.Amount), Currency: currency}, nil
+}
+
+// BasisPoints are hundredths of a percent, 1250 is 12.5%
+type BasisPoints int32
+
+const MaxBasisPoints BasisPoints = 10_000
+
+// ParsePercent reads a percentage like "12.5" exactly
+func ParsePercent(s string) (BasisPoints, error) {
+	s = strings.TrimSpace(s)
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" || len(whole) > 3 || len(frac) > 2 || !isDigits(whole) || !isDigits(frac) {
+		return 0, ErrInvalidPercent
+	}
+
+	n, err := strconv.Atoi(whole + frac + strings.Repeat("0", 2-len(frac)))
+	if err != nil || BasisPoints(n) > MaxBasisPoints {
+		return 0, ErrInvalidPercent
+	}
+	return BasisPoints(n), nil
+}
+
+// Percent is meant for display and search filters
+func (b BasisPoints) Percent() float64 {
+	return float64(b) / 100
+}
+
+func (b BasisPoints) String() string {
+	return strconv.FormatFloat(b.Percent(), 'f', -1, 64)
+}
@@ -0,0 +1,140 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestApplyDiscount(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   Minor
+		currency Currency
+		discount BasisPoints
+		want     Minor
+	}{
+		{name: "no discount", amount: 1999, currency: "USD", discount: 0, want: 1999},
+		{name: "exact", amount: 2000, currency: "USD", discount: 2500, want: 1500},
+		{name: "rounds half up", amount: 1999, currency: "USD", discount: 5000, want: 1000},
+		{name: "rounds down below half", amount: 1001, currency: "USD", discount: 3333, want: 667},
+		{name: "rounds up above half", amount: 1003, currency: "USD", discount: 3333, want: 669},
+		{name: "negative rounds away from zero", amount: -1999, currency: "USD", discount: 5000, want: -1000},
+		{name: "zero exponent", amount: 999, currency: "JPY", discount: 1250, want: 874},
+		{name: "three digit exponent", amount: 12345, currency: "KWD", discount: 750, want: 11419},
+		{name: "full discount", amount: 1999, currency: "USD", discount: MaxBasisPoints, want: 0},
+		{name: "clamped above 100%", amount: 1999, currency: "USD", discount: MaxBasisPoints + 1, want: 0},
+		{name: "clamped below 0%", amount: 1999, currency: "USD", discount: -100, want: 1999},
+		{name: "largest amount", amount: math.MaxInt64, currency: "USD", discount: 2500, want: 6917529027641081855},
+		{name: "largest amount undiscounted", amount: math.MaxInt64, currency: "USD", discount: 0, want: math.MaxInt64},
+		{name: "smallest amount", amount: math.MinInt64, currency: "USD", discount: 5000, want: math.MinInt64 / 2},
+		{name: "fifteen digit amount", amount: 999999999999999, currency: "JPY", discount: 1, want: 999899999999999},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := New(tt.amount, tt.currency).ApplyDiscount(tt.discount)
+			if got.Amount != tt.want || got.Currency != tt.currency {
+				t.Errorf("ApplyDiscount(%d) = %s, want %d %s", tt.discount, got, tt.want, tt.currency)
+			}
+		})
+	}
+}
+
+func TestAdd(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    Money
+		want    Money
+		wantErr error
+	}{
+		{name: "same currency", a: New(1999, "USD"), b: New(1, "USD"), want: New(2000, "USD")},
+		{name: "negative", a: New(500, "EUR"), b: New(-700, "EUR"), want: New(-200, "EUR")},
+		{name: "currency mismatch", a: New(1999, "USD"), b: New(1999, "EUR"), wantErr: ErrCurrencyMismatch},
+		{name: "overflow", a: New(math.MaxInt64, "USD"), b: New(1, "USD"), wantErr: ErrOverflow},
+		{name: "underflow", a: New(math.MinInt64, "USD"), b: New(-1, "USD"), wantErr: ErrOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.a.Add(tt.b)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Add error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Add = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMul(t *testing.T) {
+	tests := []struct {
+		name    string
+		m       Money
+		n       int64
+		want    Money
+		wantErr error
+	}{
+		{name: "quantity", m: New(1999, "USD"), n: 3, want: New(5997, "USD")},
+		{name: "zero", m: New(1999, "USD"), n: 0, want: New(0, "USD")},
+		{name: "zero amount", m: New(0, "USD"), n: math.MaxInt64, want: New(0, "USD")},
+		{name: "negative", m: New(-250, "GBP"), n: 4, want: New(-1000, "GBP")},
+		{name: "largest", m: New(math.MaxInt64/2, "USD"), n: 2, want: New(math.MaxInt64-1, "USD")},
+		{name: "overflow", m: New(math.MaxInt64/2+1, "USD"), n: 2, wantErr: ErrOverflow},
+		{name: "negative overflow", m: New(math.MinInt64, "USD"), n: 2, wantErr: ErrOverflow},
+		{name: "negating the minimum", m: New(math.MinInt64, "USD"), n: -1, wantErr: ErrOverflow},
+		{name: "minimum times minus one", m: New(-1, "USD"), n: math.MinInt64, wantErr: ErrOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.m.Mul(tt.n)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Mul error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Mul = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in       string
+		currency Currency
+		want     Minor
+		decimal  string
+		wantErr  error
+	}{
+		{in: "19.99", currency: "USD", want: 1999, decimal: "19.99"},
+		{in: "19.9", currency: "USD", want: 1990, decimal: "19.90"},
+		{in: "0.05", currency: "USD", want: 5, decimal: "0.05"},
+		{in: "-3.5", currency: "EUR", want: -350, decimal: "-3.50"},
+		{in: "1200", currency: "JPY", want: 1200, decimal: "1200"},
+		{in: "1.234", currency: "KWD", want: 1234, decimal: "1.234"},
+		{in: "19.999", currency: "USD", wantErr: ErrInvalidAmount},
+		{in: "1.5", currency: "JPY", wantErr: ErrInvalidAmount},
+		{in: "abc", currency: "USD", wantErr: ErrInvalidAmount},
+		{in: "1", currency: "XXX", wantErr: ErrUnknownCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in+" "+string(tt.currency), func(t *testing.T) {
+			got, err := Parse(tt.in, tt.currency)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Amount != tt.want {
+				t.Errorf("Parse = %d, want %d", got.Amount, tt.want)
+			}
+			if d := got.Decimal(); d != tt.decimal {
+				t.Errorf("Decimal = %q, want %q", d, tt.decimal)
+			}
+		})
+	}
+}
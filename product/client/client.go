@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lmnzx/slopify/pkg/dataloader"
+	product "github.com/lmnzx/slopify/product/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// MaxBatchSize matches the limit enforced by BatchGetProducts on the server
+	MaxBatchSize = 100
+	// BatchWait is how long a loader waits for more lookups before sending
+	BatchWait = 2 * time.Millisecond
+)
+
+var ErrProductNotFound = errors.New("product not found")
+
+func GetProduct(ctx context.Context, c product.ProductServiceClient, id int32) (*product.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	r, err := c.GetProduct(ctx, &product.GetProductRequest{ProductId: id})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func BatchGetProducts(ctx context.Context, c product.ProductServiceClient, ids []int32) (*product.BatchGetProductsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	r, err := c.BatchGetProducts(ctx, &product.BatchGetProductsRequest{ProductIds: ids})
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func ListProducts(ctx context.Context, c product.ProductServiceClient, req *product.ListProductsRequest) (*product.ListProductsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	r, err := c.ListProducts(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func SearchProducts(ctx context.Context, c product.ProductServiceClient, req *product.SearchProductsRequest) (*product.SearchProductsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	r, err := c.SearchProducts(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// NewProductLoader returns a loader that turns concurrent single product
+// lookups into BatchGetProducts calls. Unknown ids resolve to
+// dataloader.ErrNotFound.
+func NewProductLoader(c product.ProductServiceClient) *dataloader.Loader[int32, *product.Product] {
+	return dataloader.New(func(ctx context.Context, ids []int32) (map[int32]*product.Product, error) {
+		r, err := BatchGetProducts(ctx, c, ids)
+		if err != nil {
+			return nil, err
+		}
+		return r.Products, nil
+	}, BatchWait, MaxBatchSize)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+
+	"github.com/lmnzx/slopify/pkg/logger"
+	"github.com/lmnzx/slopify/product/internal"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/meilisearch/meilisearch-go"
+)
+
+// importProducts upserts products from local files, matching them by sku.
+// Categories the products refer to have to exist, -categories creates the
+// missing ones first.
+//
+//	go run ./product/cmd import -categories product/fixtures/categories.json product/fixtures/products.json
+func importProducts(ctx context.Context, dbpool *pgxpool.Pool, client meilisearch.ServiceManager, args []string) {
+	log := logger.GetLogger()
+
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	batchSize := fs.Int("batch-size", internal.DefaultImportBatchSize, "rows per transaction")
+	categories := fs.String("categories", "", "json file of categories to create before the products")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 && *categories == "" {
+		log.Fatal().Msg("usage: import [-batch-size n] [-categories file.json] <file.json|file.ndjson|file.csv>...")
+	}
+
+	catalog := internal.NewCatalogService(dbpool, client.Index(internal.ProductIndex))
+
+	if *categories != "" {
+		created, err := catalog.ImportCategories(ctx, *categories)
+		if err != nil {
+			log.Fatal().Err(err).Str("file", *categories).Msg("category import failed")
+		}
+		log.Info().Str("file", *categories).Int("created", created).Msg("categories imported")
+	}
+
+	failed := false
+	for _, path := range fs.Args() {
+		rows, err := internal.ReadImportFile(path)
+		if err != nil {
+			log.Fatal().Err(err).Str("file", path).Msg("failed to read import file")
+		}
+
+		report, err := catalog.Import(ctx, rows, *batchSize)
+		for _, rowErr := range report.Errors {
+			log.Warn().Str("file", path).Int("row", rowErr.Row).Str("sku", rowErr.SKU).Msg(rowErr.Reason)
+		}
+		if err != nil {
+			log.Fatal().Err(err).Str("file", path).Msg("import failed")
+		}
+
+		log.Info().
+			Str("file", path).
+			Int("created", report.Created).
+			Int("updated", report.Updated).
+			Int("unchanged", report.Unchanged).
+			Int("invalid", len(report.Errors)).
+			Msg("import complete, the indexer will pick the changes up")
+		failed = failed || len(report.Errors) > 0
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+
+	"github.com/lmnzx/slopify/pkg/logger"
+	"github.com/lmnzx/slopify/product/internal"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/meilisearch/meilisearch-go"
+)
+
+// reindex rebuilds the products index, run it after changing index settings
+//
+//	go run ./product/cmd reindex
+func reindex(ctx context.Context, dbpool *pgxpool.Pool, client meilisearch.ServiceManager) {
+	log := logger.GetLogger()
+
+	res, err := internal.Reindex(ctx, dbpool, client)
+	if err != nil {
+		log.Fatal().Err(err).Msg("reindex failed")
+	}
+
+	log.Info().
+		Int64("documents", res.Documents).
+		Str("previousIndex", res.PreviousIndex).
+		Msg("reindex complete, delete the previous index once the new one looks right")
+}
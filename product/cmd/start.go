@@ -14,11 +14,13 @@ import (
 	"github.com/lmnzx/slopify/pkg/logger"
 	"github.com/lmnzx/slopify/product/config"
 	"github.com/lmnzx/slopify/product/handler"
+	"github.com/lmnzx/slopify/product/internal"
 	"github.com/lmnzx/slopify/product/repository"
-	scrips "github.com/lmnzx/slopify/product/scripts"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/meilisearch/meilisearch-go"
+	"github.com/valkey-io/valkey-go"
+	"github.com/valkey-io/valkey-go/valkeyotel"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -61,7 +63,19 @@ func main() {
 	client := meilisearch.New(config.Meilisearch.Url, meilisearch.WithAPIKey(config.Meilisearch.Key))
 	defer client.Close()
 
-	index := client.Index("products")
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "reindex":
+			reindex(ctx, dbpool, client)
+		case "import":
+			importProducts(ctx, dbpool, client, os.Args[2:])
+		default:
+			log.Fatal().Str("command", os.Args[1]).Msg("unknown command")
+		}
+		return
+	}
+
+	index := client.Index(internal.ProductIndex)
 
 	queries := repository.New(dbpool)
 
@@ -76,12 +90,58 @@ func main() {
 
 	c := auth.NewAuthServiceClient(conn)
 
-	scrips.Seed(queries, index)
+	valkeyOpts, err := valkey.ParseURL(config.GetValkeyConnectionString())
+	if err != nil {
+		log.Fatal().Err(err).Msg("unable to parse valkey url")
+	}
+
+	valkeyClient, err := valkeyotel.NewClient(valkeyOpts)
+	if err != nil {
+		log.Fatal().Err(err).Msg("unable to connect to valkey database")
+	}
+	if err := valkeyClient.Do(ctx, valkeyClient.B().Ping().Build()).Error(); err != nil {
+		log.Fatal().Err(err).Msg("unable to ping to valkey database")
+	}
+	defer valkeyClient.Close()
+
+	catalog := internal.NewCatalogService(dbpool, index)
+	if err := catalog.ConfigureIndex(ctx); err != nil {
+		log.Fatal().Err(err).Msg("failed to configure the search index")
+	}
+	// an apply that failed halfway may have left the index on other settings
+	// than the active version's
+	if changes, err := catalog.ReconcileSearchSettings(ctx); err != nil {
+		log.Error().Err(err).Msg("failed to reconcile the search settings")
+	} else if len(changes) > 0 {
+		log.Warn().Int("changes", len(changes)).Msg("search settings reconciled with the active version")
+	}
+	cache := internal.NewProductCache(valkeyClient, catalog, config.Cache.ProductTTL)
+	suggester := internal.NewSuggester(valkeyClient, index)
+	inventory := internal.NewInventoryService(dbpool, config.Inventory.ReservationTTL)
+	analytics := internal.NewSearchAnalytics(dbpool, valkeyClient, config.Analytics.SearchSampleRate)
 
 	var wg sync.WaitGroup
 
 	wg.Add(1)
-	go handler.StartRestServer(ctx, config.RestServerAddress, queries, index, c, &wg)
+	go catalog.StartIndexer(ctx, &wg)
+
+	wg.Add(1)
+	go cache.StartInvalidator(ctx, dbpool, &wg)
+
+	wg.Add(1)
+	go inventory.StartReaper(ctx, config.Inventory.ReaperInterval, &wg)
+
+	wg.Add(1)
+	go analytics.Start(ctx, &wg)
+
+	wg.Add(1)
+	go suggester.Start(ctx, &wg)
+
+	wg.Add(1)
+	go handler.StartGrpcServer(ctx, config.GrpcServerAddress, catalog, cache, inventory, &wg)
+
+	wg.Add(1)
+	go handler.StartRestServer(ctx, config.RestServerAddress, queries, index, catalog, cache, suggester, analytics, c, config.Admins, &wg)
 
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
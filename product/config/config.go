@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/lmnzx/slopify/pkg/logger"
 
@@ -11,10 +12,12 @@ import (
 )
 
 type ProductServiceConfig struct {
-	Name               string `mapstructure:"name"`
-	Version            string `mapstructure:"version"`
-	RestServerAddress  string `mapstructure:"restserveraddress"`
-	AuthServiceAddress string `mapstructure:"authserviceaddress"`
+	Name               string   `mapstructure:"name"`
+	Version            string   `mapstructure:"version"`
+	RestServerAddress  string   `mapstructure:"restserveraddress"`
+	GrpcServerAddress  string   `mapstructure:"grpcserveraddress"`
+	AuthServiceAddress string   `mapstructure:"authserviceaddress"`
+	Admins             []string `mapstructure:"admins"`
 	Postgres           struct {
 		User     string `mapstructure:"user"`
 		Password string `mapstructure:"password"`
@@ -27,6 +30,24 @@ type ProductServiceConfig struct {
 		Url string `mapstructure:"url"`
 		Key string `mapstructure:"key"`
 	}
+	Valkey struct {
+		User     string `mapstructure:"user"`
+		Password string `mapstructure:"password"`
+		Host     string `mapstructure:"host"`
+		Port     string `mapstructure:"port"`
+		DBNumber string `mapstructure:"dbnumber"`
+	}
+	Cache struct {
+		ProductTTL time.Duration `mapstructure:"productttl"`
+	}
+	Inventory struct {
+		ReservationTTL time.Duration `mapstructure:"reservationttl"`
+		ReaperInterval time.Duration `mapstructure:"reaperinterval"`
+	}
+	Analytics struct {
+		// SearchSampleRate is the share of searches recorded, 0 to 1
+		SearchSampleRate float64 `mapstructure:"searchsamplerate"`
+	}
 	OtelCollectorURL string `mapstructure:"otelcollectorurl"`
 }
 
@@ -69,3 +90,12 @@ func (c *ProductServiceConfig) GetDBConnectionString() string {
 		c.Postgres.DBName,
 		sslMode)
 }
+
+func (c *ProductServiceConfig) GetValkeyConnectionString() string {
+	return fmt.Sprintf("valkey://%s:%s@%s:%s/%s",
+		c.Valkey.User,
+		c.Valkey.Password,
+		c.Valkey.Host,
+		c.Valkey.Port,
+		c.Valkey.DBNumber)
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/lmnzx/slopify/pkg/cookie"
+	"github.com/lmnzx/slopify/pkg/middleware"
+	"github.com/lmnzx/slopify/product/internal"
+
+	"github.com/google/uuid"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	guestCookie       = "guest_id"
+	guestCookieExpiry = 365 * 24 * time.Hour
+	// reports cover at most the retained events
+	maxReportDays = int(internal.AnalyticsRetention / (24 * time.Hour))
+)
+
+// searcherIDs returns the signed in user, or for guests an id kept in a
+// cookie so their searches can be told apart. The cookie is only set once a
+// guest's search is sampled.
+func (h *RestHandler) searcherIDs(ctx *fasthttp.RequestCtx, userID string) (uuid.UUID, uuid.UUID) {
+	if id, err := uuid.Parse(userID); err == nil {
+		return id, uuid.Nil
+	}
+
+	if id, err := uuid.Parse(cookie.Get(ctx, guestCookie)); err == nil {
+		return uuid.Nil, id
+	}
+	id := uuid.New()
+	cookie.Set(ctx, guestCookie, id.String(), "/", "", guestCookieExpiry, false, fasthttp.CookieSameSiteDefaultMode)
+	return uuid.Nil, id
+}
+
+type SearchClickRequest struct {
+	SearchID  string `json:"search_id"`
+	ProductID int32  `json:"product_id"`
+	// Position is where the hit was in the search's hits, 0 is the top hit
+	Position int32 `json:"position"`
+}
+
+// trackSearchClick records a click on a result of a sampled search, the
+// search_id comes from the search response. Only the user or guest who ran
+// the search can click its hits.
+func (h *RestHandler) trackSearchClick(ctx *fasthttp.RequestCtx) {
+	var parsedBody SearchClickRequest
+	if err := json.Unmarshal(ctx.Request.Body(), &parsedBody); err != nil {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	searchID, err := uuid.Parse(parsedBody.SearchID)
+	if err != nil {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid search id")
+		return
+	}
+	if parsedBody.ProductID <= 0 {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid product id")
+		return
+	}
+	if parsedBody.Position < 0 {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "position cannot be negative")
+		return
+	}
+
+	// the guest cookie is only read, a click never starts a new guest
+	userID, _ := uuid.Parse(middleware.GetUserIDFromCtx(ctx))
+	guestID, _ := uuid.Parse(cookie.Get(ctx, guestCookie))
+	err = h.analytics.CheckClick(ctx, searchID, userID, guestID, parsedBody.ProductID, parsedBody.Position)
+	switch {
+	case errors.Is(err, internal.ErrSearchNotFound):
+		h.res.SendError(ctx, fasthttp.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, internal.ErrHitNotInSearch):
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		h.log.Error().Err(err).Str("search_id", searchID.String()).Msg("failed to check search click")
+		h.res.SendError(ctx, fasthttp.StatusServiceUnavailable, "failed to record click")
+		return
+	}
+
+	h.analytics.RecordClick(searchID, parsedBody.ProductID, parsedBody.Position)
+
+	h.res.SendSuccess(ctx, fasthttp.StatusAccepted, "click recorded")
+}
+
+func (h *RestHandler) topSearchQueries(ctx *fasthttp.RequestCtx) {
+	report, ok := h.analyticsReportFromArgs(ctx)
+	if !ok {
+		return
+	}
+
+	rows, err := h.analytics.TopQueries(ctx, report)
+	if err != nil {
+		h.res.SendError(ctx, fasthttp.StatusInternalServerError, "failed to get top search queries")
+		return
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, rows)
+}
+
+func (h *RestHandler) zeroResultSearchQueries(ctx *fasthttp.RequestCtx) {
+	report, ok := h.analyticsReportFromArgs(ctx)
+	if !ok {
+		return
+	}
+
+	rows, err := h.analytics.ZeroResultQueries(ctx, report)
+	if err != nil {
+		h.res.SendError(ctx, fasthttp.StatusInternalServerError, "failed to get zero result search queries")
+		return
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, rows)
+}
+
+func (h *RestHandler) searchClickThrough(ctx *fasthttp.RequestCtx) {
+	report, ok := h.analyticsReportFromArgs(ctx)
+	if !ok {
+		return
+	}
+
+	rows, err := h.analytics.ClickThrough(ctx, report)
+	if err != nil {
+		h.res.SendError(ctx, fasthttp.StatusInternalServerError, "failed to get search click through")
+		return
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, rows)
+}
+
+// analyticsReportFromArgs reads ?days=<n>&limit=<n>, the counts in reports
+// are of the sampled searches only
+func (h *RestHandler) analyticsReportFromArgs(ctx *fasthttp.RequestCtx) (internal.AnalyticsReport, bool) {
+	args := ctx.QueryArgs()
+
+	var report internal.AnalyticsReport
+	if args.Has("days") {
+		days, err := strconv.Atoi(string(args.Peek("days")))
+		if err != nil || days <= 0 || days > maxReportDays {
+			h.res.SendError(ctx, fasthttp.StatusBadRequest, "days must be between 1 and "+strconv.Itoa(maxReportDays))
+			return internal.AnalyticsReport{}, false
+		}
+		report.Since = time.Now().AddDate(0, 0, -days)
+	}
+	if args.Has("limit") {
+		limit, err := strconv.Atoi(string(args.Peek("limit")))
+		if err != nil || limit < 0 {
+			h.res.SendError(ctx, fasthttp.StatusBadRequest, "limit must be a non-negative integer")
+			return internal.AnalyticsReport{}, false
+		}
+		report.Limit = limit
+	}
+
+	return report, true
+}
@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/lmnzx/slopify/pkg/instrumentation"
+	"github.com/lmnzx/slopify/pkg/logger"
+	"github.com/lmnzx/slopify/product/internal"
+	"github.com/lmnzx/slopify/product/proto"
+	"github.com/lmnzx/slopify/product/repository"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxBatchGetProducts caps a single BatchGetProducts call
+const maxBatchGetProducts = 100
+
+type GrpcHandler struct {
+	proto.UnimplementedProductServiceServer
+	catalog *internal.CatalogService
+	cache   *internal.ProductCache
+}
+
+func NewGrpcHandler(catalog *internal.CatalogService, cache *internal.ProductCache) *GrpcHandler {
+	return &GrpcHandler{
+		catalog: catalog,
+		cache:   cache,
+	}
+}
+
+func StartGrpcServer(ctx context.Context, port string, catalog *internal.CatalogService, cache *internal.ProductCache, inventory *internal.InventoryService, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	log := logger.GetLogger()
+
+	lis, err := net.Listen("tcp", port)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to setup tcp listener")
+		return
+	}
+
+	s := grpc.NewServer(
+		grpc.UnaryInterceptor(
+			instrumentation.UnaryServerInstrumentationMiddleware("product"),
+		),
+	)
+
+	h := NewGrpcHandler(catalog, cache)
+	proto.RegisterProductServiceServer(s, h)
+	proto.RegisterInventoryServiceServer(s, NewInventoryGrpcHandler(inventory))
+	reflection.Register(s)
+
+	serveErrCh := make(chan error, 1)
+	go func() {
+		log.Info().Str("port", port).Msg("grpc server stared")
+		if err := s.Serve(lis); err != nil {
+			if err != grpc.ErrServerStopped {
+				serveErrCh <- err
+			} else {
+				close(serveErrCh)
+			}
+		} else {
+			close(serveErrCh)
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		s.GracefulStop()
+		if err := <-serveErrCh; err != nil {
+			log.Error().Err(err).Msg("error during server run after shutdown initiated")
+		}
+	case err := <-serveErrCh:
+		log.Error().Err(err).Msg("grpc server failed")
+	}
+}
+
+// GetProduct also returns archived products, callers holding an old id (a
+// cart or an order) still need to resolve it
+func (h *GrpcHandler) GetProduct(ctx context.Context, req *proto.GetProductRequest) (*proto.Product, error) {
+	if req.ProductId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "product id is required")
+	}
+
+	product, err := h.cache.GetProduct(ctx, req.ProductId)
+	if err != nil {
+		if errors.Is(err, internal.ErrProductNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get product: %v", err)
+	}
+
+	return dbProductToProtoProduct(&product.Product, product.Variants), nil
+}
+
+func (h *GrpcHandler) BatchGetProducts(ctx context.Context, req *proto.BatchGetProductsRequest) (*proto.BatchGetProductsResponse, error) {
+	if len(req.ProductIds) > maxBatchGetProducts {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d product ids per request", maxBatchGetProducts)
+	}
+
+	ids := make([]int32, 0, len(req.ProductIds))
+	seen := make(map[int32]struct{}, len(req.ProductIds))
+	for _, id := range req.ProductIds {
+		if id <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid product id %d", id)
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+
+	res := &proto.BatchGetProductsResponse{Products: make(map[int32]*proto.Product, len(ids))}
+	if len(ids) == 0 {
+		return res, nil
+	}
+
+	found, err := h.catalog.GetProducts(ctx, ids)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get products: %v", err)
+	}
+	variants, err := h.catalog.GetVariants(ctx, ids)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get variants: %v", err)
+	}
+
+	for _, id := range ids {
+		p, ok := found[id]
+		if !ok {
+			res.MissingIds = append(res.MissingIds, id)
+			continue
+		}
+		res.Products[id] = dbProductToProtoProduct(&p, variants[id])
+	}
+	return res, nil
+}
+
+func (h *GrpcHandler) ListProducts(ctx context.Context, req *proto.ListProductsRequest) (*proto.ListProductsResponse, error) {
+	products, nextCursor, err := h.catalog.ListProducts(ctx, internal.ProductFilter{
+		Cursor:          req.PageToken,
+		PageSize:        int(req.PageSize),
+		Category:        req.Category,
+		IncludeArchived: req.IncludeArchived,
+	})
+	if err != nil {
+		if errors.Is(err, internal.ErrInvalidCursor) {
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to list products: %v", err)
+	}
+
+	protoProducts, err := h.withVariants(ctx, products)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.ListProductsResponse{
+		Products:      protoProducts,
+		NextPageToken: nextCursor,
+	}, nil
+}
+
+func (h *GrpcHandler) SearchProducts(ctx context.Context, req *proto.SearchProductsRequest) (*proto.SearchProductsResponse, error) {
+	if req.Query == "" {
+		return nil, status.Error(codes.InvalidArgument, "query is required")
+	}
+
+	products, total, err := h.catalog.SearchProducts(ctx, internal.SearchParams{
+		Query:       req.Query,
+		Categories:  req.Categories,
+		MinPrice:    req.MinPrice,
+		MaxPrice:    req.MaxPrice,
+		MinDiscount: req.MinDiscount,
+		MinRating:   req.MinRating,
+		InStock:     req.InStock,
+		Options:     req.Options,
+		Sort:        req.Sort,
+		Offset:      int(req.Offset),
+		Limit:       int(req.Limit),
+	})
+	if err != nil {
+		if errors.Is(err, internal.ErrInvalidSort) || errors.Is(err, internal.ErrInvalidOption) || errors.Is(err, internal.ErrTooManyOptions) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to search products: %v", err)
+	}
+
+	protoProducts, err := h.withVariants(ctx, products)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.SearchProductsResponse{
+		Products:           protoProducts,
+		EstimatedTotalHits: total,
+	}, nil
+}
+
+// withVariants converts products to their proto form along with their
+// variants, loaded in one query
+func (h *GrpcHandler) withVariants(ctx context.Context, products []repository.Product) ([]*proto.Product, error) {
+	ids := make([]int32, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+
+	variants, err := h.catalog.GetVariants(ctx, ids)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get variants: %v", err)
+	}
+
+	res := make([]*proto.Product, 0, len(products))
+	for i := range products {
+		res = append(res, dbProductToProtoProduct(&products[i], variants[products[i].ID]))
+	}
+	return res, nil
+}
+
+func dbProductToProtoProduct(product *repository.Product, variants []repository.ProductVariant) *proto.Product {
+	p := &proto.Product{
+		ProductId:         product.ID,
+		Title:             product.Title,
+		Description:       product.Description,
+		Category:          product.Category,
+		Price:             internal.ProductPrice(product).Proto(),
+		DiscountBps:       int32(product.DiscountBps),
+		SalePrice:         internal.ProductSalePrice(product).Proto(),
+		QuantityInStock:   product.QuantityInStock,
+		CreatedAt:         timestamppb.New(product.CreatedAt),
+		UpdatedAt:         timestamppb.New(product.UpdatedAt),
+		Rating:            internal.ProductRating(product),
+		RatingCount:       product.RatingCount,
+		AvailableQuantity: internal.ProductAvailable(product),
+	}
+	if product.ArchivedAt.Valid {
+		p.ArchivedAt = timestamppb.New(product.ArchivedAt.Time)
+	}
+	for i := range variants {
+		v := &variants[i]
+		p.Variants = append(p.Variants, &proto.Variant{
+			VariantId:         v.ID,
+			Sku:               v.Sku,
+			Attributes:        internal.VariantAttributes(v),
+			Price:             internal.VariantPrice(product, v).Proto(),
+			SalePrice:         internal.VariantSalePrice(product, v).Proto(),
+			QuantityInStock:   v.QuantityInStock,
+			AvailableQuantity: internal.VariantAvailable(v),
+		})
+	}
+	return p
+}
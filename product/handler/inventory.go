@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lmnzx/slopify/product/internal"
+	"github.com/lmnzx/slopify/product/proto"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type InventoryGrpcHandler struct {
+	proto.UnimplementedInventoryServiceServer
+	inventory *internal.InventoryService
+}
+
+func NewInventoryGrpcHandler(inventory *internal.InventoryService) *InventoryGrpcHandler {
+	return &InventoryGrpcHandler{inventory: inventory}
+}
+
+func (h *InventoryGrpcHandler) ReserveStock(ctx context.Context, req *proto.ReserveStockRequest) (*proto.Reservation, error) {
+	if req.TtlSeconds < 0 {
+		return nil, status.Error(codes.InvalidArgument, "ttl_seconds cannot be negative")
+	}
+
+	items := make([]internal.ReservationItemInput, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, internal.ReservationItemInput{
+			ProductID: item.ProductId,
+			VariantID: item.VariantId,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	reservation, err := h.inventory.Reserve(ctx, internal.ReserveInput{
+		Reference: req.Reference,
+		Items:     items,
+		TTL:       time.Duration(req.TtlSeconds) * time.Second,
+	})
+	if err != nil {
+		return nil, reservationError(err)
+	}
+
+	return reservationToProto(&reservation), nil
+}
+
+func (h *InventoryGrpcHandler) CommitReservation(ctx context.Context, req *proto.ReservationRequest) (*proto.Reservation, error) {
+	id, err := uuid.Parse(req.ReservationId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid reservation id")
+	}
+
+	reservation, err := h.inventory.Commit(ctx, id)
+	if err != nil {
+		return nil, reservationError(err)
+	}
+
+	return reservationToProto(&reservation), nil
+}
+
+func (h *InventoryGrpcHandler) ReleaseReservation(ctx context.Context, req *proto.ReservationRequest) (*proto.Reservation, error) {
+	id, err := uuid.Parse(req.ReservationId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid reservation id")
+	}
+
+	reservation, err := h.inventory.Release(ctx, id)
+	if err != nil {
+		return nil, reservationError(err)
+	}
+
+	return reservationToProto(&reservation), nil
+}
+
+func (h *InventoryGrpcHandler) GetReservation(ctx context.Context, req *proto.ReservationRequest) (*proto.Reservation, error) {
+	id, err := uuid.Parse(req.ReservationId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid reservation id")
+	}
+
+	reservation, err := h.inventory.GetReservation(ctx, id)
+	if err != nil {
+		return nil, reservationError(err)
+	}
+
+	return reservationToProto(&reservation), nil
+}
+
+func reservationError(err error) error {
+	var validationErr *internal.ProductValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return status.Error(codes.InvalidArgument, validationErr.Error())
+	case errors.Is(err, internal.ErrReservationNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, internal.ErrInsufficientStock),
+		errors.Is(err, internal.ErrReservationExpired),
+		errors.Is(err, internal.ErrReservationReleased),
+		errors.Is(err, internal.ErrReservationCommitted):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, internal.ErrReferenceInUse):
+		return status.Error(codes.AlreadyExists, err.Error())
+	default:
+		return status.Errorf(codes.Internal, "failed to process the reservation: %v", err)
+	}
+}
+
+var reservationStatuses = map[string]proto.ReservationStatus{
+	internal.ReservationPending:   proto.ReservationStatus_RESERVATION_STATUS_PENDING,
+	internal.ReservationCommitted: proto.ReservationStatus_RESERVATION_STATUS_COMMITTED,
+	internal.ReservationReleased:  proto.ReservationStatus_RESERVATION_STATUS_RELEASED,
+	internal.ReservationExpired:   proto.ReservationStatus_RESERVATION_STATUS_EXPIRED,
+}
+
+func reservationToProto(r *internal.Reservation) *proto.Reservation {
+	p := &proto.Reservation{
+		ReservationId: r.ID.String(),
+		Reference:     r.Reference,
+		Status:        reservationStatuses[r.Status],
+		Items:         make([]*proto.ReservationItem, 0, len(r.Items)),
+		ExpiresAt:     timestamppb.New(r.ExpiresAt),
+		CreatedAt:     timestamppb.New(r.CreatedAt),
+	}
+	for _, item := range r.Items {
+		p.Items = append(p.Items, &proto.ReservationItem{
+			ProductId: item.ProductID,
+			VariantId: item.VariantID.Int32,
+			Quantity:  item.Quantity,
+		})
+	}
+	return p
+}
@@ -2,16 +2,24 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	auth "github.com/lmnzx/slopify/auth/proto"
 	"github.com/lmnzx/slopify/pkg/instrumentation"
 	"github.com/lmnzx/slopify/pkg/logger"
 	"github.com/lmnzx/slopify/pkg/middleware"
+	"github.com/lmnzx/slopify/pkg/money"
 	"github.com/lmnzx/slopify/pkg/response"
+	"github.com/lmnzx/slopify/product/internal"
 	"github.com/lmnzx/slopify/product/repository"
 
 	"github.com/fasthttp/router"
+	"github.com/google/uuid"
 	"github.com/meilisearch/meilisearch-go"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog"
@@ -24,34 +32,74 @@ import (
 )
 
 type RestHandler struct {
-	index   meilisearch.IndexManager
-	queries *repository.Queries
-	res     *response.ResponseSender
-	log     zerolog.Logger
-	tracer  trace.Tracer
+	index     meilisearch.IndexManager
+	queries   *repository.Queries
+	catalog   *internal.CatalogService
+	cache     *internal.ProductCache
+	suggester *internal.Suggester
+	analytics *internal.SearchAnalytics
+	res       *response.ResponseSender
+	log       zerolog.Logger
+	tracer    trace.Tracer
 }
 
-func NewRestHandler(queries *repository.Queries, index meilisearch.IndexManager) *RestHandler {
+func NewRestHandler(queries *repository.Queries, index meilisearch.IndexManager, catalog *internal.CatalogService, cache *internal.ProductCache, suggester *internal.Suggester, analytics *internal.SearchAnalytics) *RestHandler {
 	return &RestHandler{
-		index:   index,
-		queries: queries,
-		log:     logger.GetLogger(),
-		res:     response.NewResponseSender(),
-		tracer:  otel.Tracer("product-rest-service"),
+		index:     index,
+		queries:   queries,
+		catalog:   catalog,
+		cache:     cache,
+		suggester: suggester,
+		analytics: analytics,
+		log:       logger.GetLogger(),
+		res:       response.NewResponseSender(),
+		tracer:    otel.Tracer("product-rest-service"),
 	}
 }
 
-func StartRestServer(ctx context.Context, port string, queries *repository.Queries, index meilisearch.IndexManager, authClient auth.AuthServiceClient, wg *sync.WaitGroup) {
+func StartRestServer(ctx context.Context, port string, queries *repository.Queries, index meilisearch.IndexManager, catalog *internal.CatalogService, cache *internal.ProductCache, suggester *internal.Suggester, analytics *internal.SearchAnalytics, authClient auth.AuthServiceClient, admins []string, wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	r := router.New()
 
-	handler := NewRestHandler(queries, index)
-	authMw := middleware.AuthMiddleware(authClient, "product")
+	handler := NewRestHandler(queries, index, catalog, cache, suggester, analytics)
+	authMw := middleware.RequireAuth(authClient, "product")
+	// browsing works signed out, the user only personalises the response
+	optionalAuthMw := middleware.OptionalAuth(authClient, "product")
+	adminMw := middleware.AdminMiddleware(admins)
 
 	r.GET("/health", handler.healthCheck)
 	r.GET("/metrics", fasthttpadaptor.NewFastHTTPHandler(promhttp.Handler()))
-	r.GET("/get", authMw(handler.getProduct))
+	r.GET("/get", optionalAuthMw(handler.getProduct))
+	// no auth, so responses are the same for everyone and can be cached
+	r.GET("/suggest", handler.suggest)
+	r.POST("/search/clicks", optionalAuthMw(handler.trackSearchClick))
+	r.GET("/products/{id}", optionalAuthMw(handler.getProductByID))
+	r.GET("/products/{id}/reviews", optionalAuthMw(handler.listProductReviews))
+	r.POST("/products/{id}/reviews", authMw(handler.createReview))
+	r.GET("/products/{id}/reviews/mine", authMw(handler.getMyReview))
+	r.PUT("/products/{id}/reviews/mine", authMw(handler.updateReview))
+	r.DELETE("/products/{id}/reviews/mine", authMw(handler.deleteReview))
+	r.GET("/categories", optionalAuthMw(handler.listCategories))
+	r.GET("/categories/{slug}", optionalAuthMw(handler.getCategory))
+	r.POST("/admin/categories", authMw(adminMw(handler.createCategory)))
+	r.GET("/admin/reviews", authMw(adminMw(handler.adminListReviews)))
+	r.POST("/admin/reviews/{reviewId}/status", authMw(adminMw(handler.setReviewStatus)))
+	r.GET("/admin/search/top-queries", authMw(adminMw(handler.topSearchQueries)))
+	r.GET("/admin/search/zero-results", authMw(adminMw(handler.zeroResultSearchQueries)))
+	r.GET("/admin/search/click-through", authMw(adminMw(handler.searchClickThrough)))
+	r.GET("/admin/search/settings", authMw(adminMw(handler.listSearchSettings)))
+	r.POST("/admin/search/settings", authMw(adminMw(handler.createSearchSettings)))
+	r.GET("/admin/search/settings/{version}", authMw(adminMw(handler.getSearchSettings)))
+	r.GET("/admin/search/settings/{version}/diff", authMw(adminMw(handler.diffSearchSettings)))
+	r.POST("/admin/search/settings/{version}/apply", authMw(adminMw(handler.applySearchSettings)))
+	r.POST("/admin/products", authMw(adminMw(handler.createProduct)))
+	r.GET("/admin/products/{id}", authMw(adminMw(handler.adminGetProduct)))
+	r.PUT("/admin/products/{id}", authMw(adminMw(handler.updateProduct)))
+	r.POST("/admin/products/{id}/archive", authMw(adminMw(handler.archiveProduct)))
+	r.POST("/admin/products/{id}/variants", authMw(adminMw(handler.createVariant)))
+	r.PUT("/admin/products/{id}/variants/{variantId}", authMw(adminMw(handler.updateVariant)))
+	r.POST("/admin/products/{id}/variants/{variantId}/archive", authMw(adminMw(handler.archiveVariant)))
 
 	server := &fasthttp.Server{
 		Handler: instrumentation.RequestInstrumentationMiddleware(r.Handler, "product"),
@@ -92,38 +140,579 @@ func (h *RestHandler) healthCheck(ctx *fasthttp.RequestCtx) {
 	h.res.SendSuccess(ctx, fasthttp.StatusOK, "all ok")
 }
 
+// getProduct searches the catalog. An empty query is browse-all on purpose: it
+// matches every product, so the filters alone narrow it down, and with no
+// filters either it pages through the whole catalog in relevance or the
+// requested sort order. Like any search it pages through at most the first
+// 1000 hits. Browsing doesn't feed suggestions.
 func (h *RestHandler) getProduct(ctx *fasthttp.RequestCtx) {
 	spanCtx, ok := ctx.UserValue("tracing_context").(context.Context)
 	if !ok {
 		spanCtx = context.Background()
 	}
 
-	query := ctx.QueryArgs().Peek("query")
-	if len(query) == 0 {
-		h.res.SendError(ctx, fasthttp.StatusBadRequest, "nothing to search")
+	params, err := searchParamsFromArgs(ctx.QueryArgs())
+	if err != nil {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, err.Error())
 		return
 	}
 
 	user_id := middleware.GetUserIDFromCtx(ctx)
-	if user_id == "" {
-		h.log.Warn().Msg("no user was found")
-	} else {
-		h.log.Info().Str("user_id", user_id).Msg("user found")
-	}
 
-	_, searchSpan := h.tracer.Start(spanCtx, "meilisearch.Search", trace.WithAttributes(attribute.String("query", string(query))))
-	searchRes, err := h.index.Search(string(query), &meilisearch.SearchRequest{
-		Limit: 10,
-	})
+	searchCtx, searchSpan := h.tracer.Start(spanCtx, "meilisearch.Search", trace.WithAttributes(attribute.String("query", params.Query)))
+	start := time.Now()
+	result, err := h.catalog.Search(searchCtx, params)
+	latency := time.Since(start)
 	if err != nil {
 		searchSpan.RecordError(err)
 		searchSpan.SetStatus(codes.Error, "search failed")
 		searchSpan.End()
+		if errors.Is(err, internal.ErrInvalidSort) || errors.Is(err, internal.ErrInvalidOption) || errors.Is(err, internal.ErrTooManyOptions) {
+			h.res.SendError(ctx, fasthttp.StatusBadRequest, err.Error())
+			return
+		}
 		h.res.SendError(ctx, fasthttp.StatusInternalServerError, "failed to get products from the search")
 		return
 	}
-	searchSpan.SetAttributes(attribute.Int("hits_count", len(searchRes.Hits)))
+	searchSpan.SetAttributes(attribute.Int("hits_count", len(result.Hits)), attribute.Int64("total_hits", result.TotalHits), attribute.Bool("degraded", result.Degraded))
 	searchSpan.End()
 
-	h.res.SendSuccess(ctx, fasthttp.StatusOK, searchRes.Hits)
+	// only first pages of searches that found something feed suggestions,
+	// paging through results isn't searching again
+	if result.TotalHits > 0 && params.Page <= 1 && params.Offset == 0 {
+		h.suggester.Record(params.Query, suggestClient(ctx, user_id))
+	}
+
+	if userID, err := uuid.Parse(user_id); err == nil {
+		h.personalizeHits(ctx, userID, result.Hits)
+	}
+
+	if h.analytics.Sample() {
+		userID, guestID := h.searcherIDs(ctx, user_id)
+		if id := h.analytics.RecordSearch(ctx, params, result, latency, userID, guestID); id != uuid.Nil {
+			result.SearchID = id.String()
+		}
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, result)
+}
+
+// suggestClient tells searchers apart for suggestion popularity. Guests go by
+// their address rather than the guest cookie, a client can drop the cookie to
+// count again.
+func suggestClient(ctx *fasthttp.RequestCtx, userID string) string {
+	if userID != "" {
+		return "user:" + userID
+	}
+	return "ip:" + ctx.RemoteIP().String()
+}
+
+// suggestMaxAge is how long clients and proxies may reuse a suggestion
+// response, popularity doesn't move quickly enough for it to matter
+const suggestMaxAge = "public, max-age=60"
+
+// suggest completes the search box input, ?q=<prefix>&limit=<n>
+func (h *RestHandler) suggest(ctx *fasthttp.RequestCtx) {
+	args := ctx.QueryArgs()
+
+	var limit int
+	if args.Has("limit") {
+		n, err := strconv.Atoi(string(args.Peek("limit")))
+		if err != nil || n < 0 {
+			h.res.SendError(ctx, fasthttp.StatusBadRequest, "limit must be a non-negative integer")
+			return
+		}
+		limit = n
+	}
+
+	suggestions := h.suggester.Suggest(ctx, string(args.Peek("q")), limit)
+
+	ctx.Response.Header.Set("Cache-Control", suggestMaxAge)
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, suggestions)
+}
+
+// getProductByID serves a single product from the cache, archived products
+// are not found here
+func (h *RestHandler) getProductByID(ctx *fasthttp.RequestCtx) {
+	id, ok := h.productIDFromPath(ctx)
+	if !ok {
+		return
+	}
+
+	product, err := h.cache.GetProduct(ctx, id)
+	if err == nil && product.ArchivedAt.Valid {
+		err = internal.ErrProductNotFound
+	}
+	if err != nil {
+		h.sendProductError(ctx, err)
+		return
+	}
+
+	view := newProductView(&product.Product, product.Variants)
+	if userID, err := uuid.Parse(middleware.GetUserIDFromCtx(ctx)); err == nil {
+		// personalisation is best effort, the product is still worth sending
+		if ratings, err := h.catalog.UserRatings(ctx, userID, []int32{id}); err == nil {
+			view.MyRating = ratings[id]
+		}
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, view)
+}
+
+// personalizeHits sets the user's own fields on search hits. It is best
+// effort, the results are still worth sending without them.
+func (h *RestHandler) personalizeHits(ctx *fasthttp.RequestCtx, userID uuid.UUID, hits []internal.ProductDocument) {
+	ids := make([]int32, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+
+	ratings, err := h.catalog.UserRatings(ctx, userID, ids)
+	if err != nil {
+		return
+	}
+	for i := range hits {
+		hits[i].MyRating = ratings[hits[i].ID]
+	}
+}
+
+// searchParamsFromArgs reads the search query string, variant options are
+// given as option.<name>=<value>
+func searchParamsFromArgs(args *fasthttp.Args) (internal.SearchParams, error) {
+	params := internal.SearchParams{
+		Query: string(args.Peek("query")),
+		Sort:  string(args.Peek("sort")),
+	}
+	for _, c := range args.PeekMulti("category") {
+		params.Categories = append(params.Categories, string(c))
+	}
+	args.VisitAll(func(key, value []byte) {
+		name, ok := strings.CutPrefix(string(key), "option.")
+		if !ok {
+			return
+		}
+		if params.Options == nil {
+			params.Options = make(map[string]string)
+		}
+		params.Options[name] = string(value)
+	})
+
+	var err error
+	if params.MinPrice, err = floatArg(args, "min_price"); err != nil {
+		return params, err
+	}
+	if params.MaxPrice, err = floatArg(args, "max_price"); err != nil {
+		return params, err
+	}
+	if params.MinDiscount, err = floatArg(args, "min_discount"); err != nil {
+		return params, err
+	}
+	if params.MinRating, err = floatArg(args, "min_rating"); err != nil {
+		return params, err
+	}
+	if args.Has("in_stock") {
+		if params.InStock, err = strconv.ParseBool(string(args.Peek("in_stock"))); err != nil {
+			return params, errors.New("in_stock must be true or false")
+		}
+	}
+
+	for name, dst := range map[string]*int{
+		"page":          &params.Page,
+		"hits_per_page": &params.HitsPerPage,
+		"offset":        &params.Offset,
+		"limit":         &params.Limit,
+	} {
+		if !args.Has(name) {
+			continue
+		}
+		n, err := strconv.Atoi(string(args.Peek(name)))
+		if err != nil || n < 0 {
+			return params, errors.New(name + " must be a non-negative integer")
+		}
+		*dst = n
+	}
+
+	return params, nil
+}
+
+func floatArg(args *fasthttp.Args, name string) (*float32, error) {
+	if !args.Has(name) {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(string(args.Peek(name)), 32)
+	if err != nil || f < 0 {
+		return nil, errors.New(name + " must be a non-negative number")
+	}
+	v := float32(f)
+	return &v, nil
+}
+
+type ProductView struct {
+	ID          int32       `json:"id"`
+	SKU         string      `json:"sku,omitempty"`
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	Category    string      `json:"category"`
+	Price       money.Money `json:"price"`
+	// DiscountBps is in hundredths of a percent, 1250 is 12.5% off
+	DiscountBps money.BasisPoints `json:"discount_bps"`
+	SalePrice   money.Money       `json:"sale_price"`
+	// QuantityInStock is the stock on hand, including what pending
+	// reservations hold
+	QuantityInStock int32 `json:"quantity_in_stock"`
+	// Available is the stock that can still be reserved
+	Available int32 `json:"available"`
+	// Rating is the average of the published reviews, 0 when there are none
+	Rating      float64 `json:"rating"`
+	RatingCount int32   `json:"rating_count"`
+	// MyRating is the signed in user's rating, when they reviewed the product
+	MyRating   int32      `json:"my_rating,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	// Variants are the live variants in display order, empty when the product
+	// has none
+	Variants []VariantView `json:"variants"`
+}
+
+type VariantView struct {
+	ID         int32             `json:"id"`
+	SKU        string            `json:"sku"`
+	Attributes map[string]string `json:"attributes"`
+	// Price is the variant's own price or the product's when it doesn't
+	// override it
+	Price           money.Money `json:"price"`
+	SalePrice       money.Money `json:"sale_price"`
+	QuantityInStock int32       `json:"quantity_in_stock"`
+	Available       int32       `json:"available"`
+	Position        int32       `json:"position"`
+}
+
+func newProductView(product *repository.Product, variants []repository.ProductVariant) ProductView {
+	v := ProductView{
+		ID:              product.ID,
+		SKU:             product.Sku.String,
+		Title:           product.Title,
+		Description:     product.Description,
+		Category:        product.Category,
+		Price:           internal.ProductPrice(product),
+		DiscountBps:     product.DiscountBps,
+		SalePrice:       internal.ProductSalePrice(product),
+		QuantityInStock: product.QuantityInStock,
+		Available:       internal.ProductAvailable(product),
+		Rating:          internal.ProductRating(product),
+		RatingCount:     product.RatingCount,
+		CreatedAt:       product.CreatedAt,
+		UpdatedAt:       product.UpdatedAt,
+	}
+	if product.ArchivedAt.Valid {
+		v.ArchivedAt = &product.ArchivedAt.Time
+	}
+
+	v.Variants = make([]VariantView, 0, len(variants))
+	for i := range variants {
+		variant := &variants[i]
+		v.Variants = append(v.Variants, VariantView{
+			ID:              variant.ID,
+			SKU:             variant.Sku,
+			Attributes:      internal.VariantAttributes(variant),
+			Price:           internal.VariantPrice(product, variant),
+			SalePrice:       internal.VariantSalePrice(product, variant),
+			QuantityInStock: variant.QuantityInStock,
+			Available:       internal.VariantAvailable(variant),
+			Position:        variant.Position,
+		})
+	}
+	return v
+}
+
+type ProductRequest struct {
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	Category    string      `json:"category"`
+	Price       money.Money `json:"price"`
+	// DiscountBps is in hundredths of a percent, 1250 is 12.5% off
+	DiscountBps     money.BasisPoints `json:"discount_bps"`
+	QuantityInStock int32             `json:"quantity_in_stock"`
+}
+
+func (r *ProductRequest) toInput() internal.ProductInput {
+	return internal.ProductInput{
+		Title:           r.Title,
+		Description:     r.Description,
+		Category:        r.Category,
+		Price:           r.Price,
+		Discount:        r.DiscountBps,
+		QuantityInStock: r.QuantityInStock,
+	}
+}
+
+func (h *RestHandler) createProduct(ctx *fasthttp.RequestCtx) {
+	var parsedBody ProductRequest
+	if err := json.Unmarshal(ctx.Request.Body(), &parsedBody); err != nil {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	product, err := h.catalog.CreateProduct(ctx, parsedBody.toInput())
+	if err != nil {
+		h.sendProductError(ctx, err)
+		return
+	}
+
+	h.log.Info().Str("admin_id", middleware.GetUserIDFromCtx(ctx)).Int32("product_id", product.ID).Msg("product created")
+	h.res.SendSuccess(ctx, fasthttp.StatusCreated, newProductView(&product, nil))
+}
+
+func (h *RestHandler) adminGetProduct(ctx *fasthttp.RequestCtx) {
+	id, ok := h.productIDFromPath(ctx)
+	if !ok {
+		return
+	}
+
+	product, err := h.catalog.GetProductWithVariants(ctx, id)
+	if err != nil {
+		h.sendProductError(ctx, err)
+		return
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, newProductView(&product.Product, product.Variants))
+}
+
+func (h *RestHandler) updateProduct(ctx *fasthttp.RequestCtx) {
+	id, ok := h.productIDFromPath(ctx)
+	if !ok {
+		return
+	}
+
+	var parsedBody ProductRequest
+	if err := json.Unmarshal(ctx.Request.Body(), &parsedBody); err != nil {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	product, err := h.catalog.UpdateProduct(ctx, id, parsedBody.toInput())
+	if err != nil {
+		h.sendProductError(ctx, err)
+		return
+	}
+
+	h.log.Info().Str("admin_id", middleware.GetUserIDFromCtx(ctx)).Int32("product_id", product.ID).Msg("product updated")
+	h.sendProductWithVariants(ctx, &product)
+}
+
+func (h *RestHandler) archiveProduct(ctx *fasthttp.RequestCtx) {
+	id, ok := h.productIDFromPath(ctx)
+	if !ok {
+		return
+	}
+
+	product, err := h.catalog.ArchiveProduct(ctx, id)
+	if err != nil {
+		h.sendProductError(ctx, err)
+		return
+	}
+
+	h.log.Info().Str("admin_id", middleware.GetUserIDFromCtx(ctx)).Int32("product_id", product.ID).Msg("product archived")
+	h.sendProductWithVariants(ctx, &product)
+}
+
+// sendProductWithVariants responds with the product after loading its
+// variants
+func (h *RestHandler) sendProductWithVariants(ctx *fasthttp.RequestCtx, product *repository.Product) {
+	variants, err := h.catalog.GetVariants(ctx, []int32{product.ID})
+	if err != nil {
+		h.sendProductError(ctx, err)
+		return
+	}
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, newProductView(product, variants[product.ID]))
+}
+
+type VariantRequest struct {
+	SKU        string            `json:"sku"`
+	Attributes map[string]string `json:"attributes"`
+	// Price overrides the product's price, leave it out to use the product's
+	Price           *money.Money `json:"price"`
+	QuantityInStock int32        `json:"quantity_in_stock"`
+	Position        int32        `json:"position"`
+}
+
+func (r *VariantRequest) toInput() internal.VariantInput {
+	return internal.VariantInput{
+		SKU:             r.SKU,
+		Attributes:      r.Attributes,
+		Price:           r.Price,
+		QuantityInStock: r.QuantityInStock,
+		Position:        r.Position,
+	}
+}
+
+// createVariant responds with the parent product and all of its variants
+func (h *RestHandler) createVariant(ctx *fasthttp.RequestCtx) {
+	id, ok := h.productIDFromPath(ctx)
+	if !ok {
+		return
+	}
+
+	var parsedBody VariantRequest
+	if err := json.Unmarshal(ctx.Request.Body(), &parsedBody); err != nil {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	variant, err := h.catalog.CreateVariant(ctx, id, parsedBody.toInput())
+	if err != nil {
+		h.sendProductError(ctx, err)
+		return
+	}
+
+	h.log.Info().Str("admin_id", middleware.GetUserIDFromCtx(ctx)).Int32("product_id", id).Int32("variant_id", variant.ID).Msg("variant created")
+	h.sendParentProduct(ctx, id, fasthttp.StatusCreated)
+}
+
+func (h *RestHandler) updateVariant(ctx *fasthttp.RequestCtx) {
+	id, variantID, ok := h.variantIDFromPath(ctx)
+	if !ok {
+		return
+	}
+
+	var parsedBody VariantRequest
+	if err := json.Unmarshal(ctx.Request.Body(), &parsedBody); err != nil {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	if _, err := h.catalog.UpdateVariant(ctx, id, variantID, parsedBody.toInput()); err != nil {
+		h.sendProductError(ctx, err)
+		return
+	}
+
+	h.log.Info().Str("admin_id", middleware.GetUserIDFromCtx(ctx)).Int32("product_id", id).Int32("variant_id", variantID).Msg("variant updated")
+	h.sendParentProduct(ctx, id, fasthttp.StatusOK)
+}
+
+func (h *RestHandler) archiveVariant(ctx *fasthttp.RequestCtx) {
+	id, variantID, ok := h.variantIDFromPath(ctx)
+	if !ok {
+		return
+	}
+
+	if _, err := h.catalog.ArchiveVariant(ctx, id, variantID); err != nil {
+		h.sendProductError(ctx, err)
+		return
+	}
+
+	h.log.Info().Str("admin_id", middleware.GetUserIDFromCtx(ctx)).Int32("product_id", id).Int32("variant_id", variantID).Msg("variant archived")
+	h.sendParentProduct(ctx, id, fasthttp.StatusOK)
+}
+
+func (h *RestHandler) sendParentProduct(ctx *fasthttp.RequestCtx, id int32, statusCode int) {
+	product, err := h.catalog.GetProductWithVariants(ctx, id)
+	if err != nil {
+		h.sendProductError(ctx, err)
+		return
+	}
+	h.res.SendSuccess(ctx, statusCode, newProductView(&product.Product, product.Variants))
+}
+
+// listCategories returns the whole category tree
+func (h *RestHandler) listCategories(ctx *fasthttp.RequestCtx) {
+	tree, err := h.catalog.CategoryTree(ctx)
+	if err != nil {
+		h.sendProductError(ctx, err)
+		return
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, tree)
+}
+
+// getCategory returns the category with its breadcrumb and subcategories
+func (h *RestHandler) getCategory(ctx *fasthttp.RequestCtx) {
+	slug, _ := ctx.UserValue("slug").(string)
+
+	category, err := h.catalog.GetCategory(ctx, slug)
+	if err != nil {
+		h.sendProductError(ctx, err)
+		return
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, category)
+}
+
+type CategoryRequest struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+	// Parent is the parent's slug, leave it out for a top level category
+	Parent   string `json:"parent"`
+	Position int32  `json:"position"`
+}
+
+func (h *RestHandler) createCategory(ctx *fasthttp.RequestCtx) {
+	var parsedBody CategoryRequest
+	if err := json.Unmarshal(ctx.Request.Body(), &parsedBody); err != nil {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	category, err := h.catalog.CreateCategory(ctx, internal.CategoryInput{
+		Slug:     parsedBody.Slug,
+		Name:     parsedBody.Name,
+		Parent:   parsedBody.Parent,
+		Position: parsedBody.Position,
+	})
+	if err != nil {
+		h.sendProductError(ctx, err)
+		return
+	}
+
+	detail, err := h.catalog.GetCategory(ctx, category.Slug)
+	if err != nil {
+		h.sendProductError(ctx, err)
+		return
+	}
+
+	h.log.Info().Str("admin_id", middleware.GetUserIDFromCtx(ctx)).Str("slug", category.Slug).Msg("category created")
+	h.res.SendSuccess(ctx, fasthttp.StatusCreated, detail)
+}
+
+func (h *RestHandler) productIDFromPath(ctx *fasthttp.RequestCtx) (int32, bool) {
+	raw, _ := ctx.UserValue("id").(string)
+	id, err := strconv.ParseInt(raw, 10, 32)
+	if err != nil || id <= 0 {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid product id")
+		return 0, false
+	}
+	return int32(id), true
+}
+
+func (h *RestHandler) variantIDFromPath(ctx *fasthttp.RequestCtx) (int32, int32, bool) {
+	id, ok := h.productIDFromPath(ctx)
+	if !ok {
+		return 0, 0, false
+	}
+
+	raw, _ := ctx.UserValue("variantId").(string)
+	variantID, err := strconv.ParseInt(raw, 10, 32)
+	if err != nil || variantID <= 0 {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid variant id")
+		return 0, 0, false
+	}
+	return id, int32(variantID), true
+}
+
+func (h *RestHandler) sendProductError(ctx *fasthttp.RequestCtx, err error) {
+	var validationErr *internal.ProductValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, validationErr.Error())
+	case errors.Is(err, internal.ErrInvalidCursor), errors.Is(err, internal.ErrInvalidReviewSort), errors.Is(err, internal.ErrInvalidReviewStatus):
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, err.Error())
+	case errors.Is(err, internal.ErrProductNotFound), errors.Is(err, internal.ErrVariantNotFound), errors.Is(err, internal.ErrCategoryNotFound), errors.Is(err, internal.ErrReviewNotFound), errors.Is(err, internal.ErrSearchSettingsNotFound):
+		h.res.SendError(ctx, fasthttp.StatusNotFound, err.Error())
+	case errors.Is(err, internal.ErrVariantSKUTaken), errors.Is(err, internal.ErrVariantExists), errors.Is(err, internal.ErrCategoryExists), errors.Is(err, internal.ErrReviewExists):
+		h.res.SendError(ctx, fasthttp.StatusConflict, err.Error())
+	default:
+		h.log.Error().Err(err).Msg("product operation failed")
+		h.res.SendError(ctx, fasthttp.StatusInternalServerError, "could not process the product")
+	}
 }
@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/lmnzx/slopify/pkg/middleware"
+	"github.com/lmnzx/slopify/product/internal"
+	"github.com/lmnzx/slopify/product/repository"
+
+	"github.com/google/uuid"
+	"github.com/valyala/fasthttp"
+)
+
+type ReviewView struct {
+	ID        int32 `json:"id"`
+	ProductID int32 `json:"product_id"`
+	// UserID is only shown to admins
+	UserID    string    `json:"user_id,omitempty"`
+	Rating    int32     `json:"rating"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func newReviewView(review *repository.ProductReview, withUser bool) ReviewView {
+	v := ReviewView{
+		ID:        review.ID,
+		ProductID: review.ProductID,
+		Rating:    review.Rating,
+		Title:     review.Title,
+		Body:      review.Body,
+		Status:    review.Status,
+		CreatedAt: review.CreatedAt,
+		UpdatedAt: review.UpdatedAt,
+	}
+	if withUser {
+		v.UserID = review.UserID.String()
+	}
+	return v
+}
+
+type ReviewPage struct {
+	Reviews []ReviewView `json:"reviews"`
+	// NextPageToken is empty on the last page
+	NextPageToken string `json:"next_page_token,omitempty"`
+}
+
+type ReviewRequest struct {
+	Rating int32  `json:"rating"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+}
+
+// listProductReviews returns the published reviews of a product
+func (h *RestHandler) listProductReviews(ctx *fasthttp.RequestCtx) {
+	id, ok := h.productIDFromPath(ctx)
+	if !ok {
+		return
+	}
+
+	h.sendReviewPage(ctx, internal.ReviewFilter{
+		ProductID: id,
+		Status:    internal.ReviewPublished,
+	}, false)
+}
+
+// adminListReviews lists reviews of any status, ?status=pending is the
+// moderation queue
+func (h *RestHandler) adminListReviews(ctx *fasthttp.RequestCtx) {
+	args := ctx.QueryArgs()
+
+	filter := internal.ReviewFilter{Status: string(args.Peek("status"))}
+	if args.Has("product_id") {
+		id, err := strconv.ParseInt(string(args.Peek("product_id")), 10, 32)
+		if err != nil || id <= 0 {
+			h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid product id")
+			return
+		}
+		filter.ProductID = int32(id)
+	}
+
+	h.sendReviewPage(ctx, filter, true)
+}
+
+func (h *RestHandler) sendReviewPage(ctx *fasthttp.RequestCtx, filter internal.ReviewFilter, withUser bool) {
+	args := ctx.QueryArgs()
+	filter.Sort = string(args.Peek("sort"))
+	filter.Cursor = string(args.Peek("page_token"))
+	if args.Has("page_size") {
+		n, err := strconv.Atoi(string(args.Peek("page_size")))
+		if err != nil || n < 0 {
+			h.res.SendError(ctx, fasthttp.StatusBadRequest, "page_size must be a non-negative integer")
+			return
+		}
+		filter.PageSize = n
+	}
+
+	reviews, nextCursor, err := h.catalog.ListReviews(ctx, filter)
+	if err != nil {
+		h.sendProductError(ctx, err)
+		return
+	}
+
+	page := ReviewPage{Reviews: make([]ReviewView, 0, len(reviews)), NextPageToken: nextCursor}
+	for i := range reviews {
+		page.Reviews = append(page.Reviews, newReviewView(&reviews[i], withUser))
+	}
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, page)
+}
+
+func (h *RestHandler) createReview(ctx *fasthttp.RequestCtx) {
+	userID, ok := h.reviewerID(ctx)
+	if !ok {
+		return
+	}
+	id, ok := h.productIDFromPath(ctx)
+	if !ok {
+		return
+	}
+
+	var parsedBody ReviewRequest
+	if err := json.Unmarshal(ctx.Request.Body(), &parsedBody); err != nil {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	review, err := h.catalog.CreateReview(ctx, id, userID, internal.ReviewInput(parsedBody))
+	if err != nil {
+		h.sendProductError(ctx, err)
+		return
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusCreated, newReviewView(&review, false))
+}
+
+// getMyReview returns the signed in user's review whatever its status, so
+// they can see when it was held back
+func (h *RestHandler) getMyReview(ctx *fasthttp.RequestCtx) {
+	userID, ok := h.reviewerID(ctx)
+	if !ok {
+		return
+	}
+	id, ok := h.productIDFromPath(ctx)
+	if !ok {
+		return
+	}
+
+	review, err := h.catalog.GetUserReview(ctx, id, userID)
+	if err != nil {
+		h.sendProductError(ctx, err)
+		return
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, newReviewView(&review, false))
+}
+
+func (h *RestHandler) updateReview(ctx *fasthttp.RequestCtx) {
+	userID, ok := h.reviewerID(ctx)
+	if !ok {
+		return
+	}
+	id, ok := h.productIDFromPath(ctx)
+	if !ok {
+		return
+	}
+
+	var parsedBody ReviewRequest
+	if err := json.Unmarshal(ctx.Request.Body(), &parsedBody); err != nil {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	review, err := h.catalog.UpdateReview(ctx, id, userID, internal.ReviewInput(parsedBody))
+	if err != nil {
+		h.sendProductError(ctx, err)
+		return
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, newReviewView(&review, false))
+}
+
+func (h *RestHandler) deleteReview(ctx *fasthttp.RequestCtx) {
+	userID, ok := h.reviewerID(ctx)
+	if !ok {
+		return
+	}
+	id, ok := h.productIDFromPath(ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.catalog.DeleteReview(ctx, id, userID); err != nil {
+		h.sendProductError(ctx, err)
+		return
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, "review deleted")
+}
+
+type ReviewStatusRequest struct {
+	Status string `json:"status"`
+}
+
+func (h *RestHandler) setReviewStatus(ctx *fasthttp.RequestCtx) {
+	raw, _ := ctx.UserValue("reviewId").(string)
+	reviewID, err := strconv.ParseInt(raw, 10, 32)
+	if err != nil || reviewID <= 0 {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid review id")
+		return
+	}
+
+	var parsedBody ReviewStatusRequest
+	if err := json.Unmarshal(ctx.Request.Body(), &parsedBody); err != nil {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	review, err := h.catalog.SetReviewStatus(ctx, int32(reviewID), parsedBody.Status)
+	if err != nil {
+		h.sendProductError(ctx, err)
+		return
+	}
+
+	h.log.Info().Str("admin_id", middleware.GetUserIDFromCtx(ctx)).Int32("review_id", review.ID).Str("status", review.Status).Msg("review moderated")
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, newReviewView(&review, true))
+}
+
+// reviewerID is the signed in user, reviews can't be written anonymously
+func (h *RestHandler) reviewerID(ctx *fasthttp.RequestCtx) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(middleware.GetUserIDFromCtx(ctx))
+	if err != nil {
+		h.res.SendError(ctx, fasthttp.StatusUnauthorized, "sign in to review products")
+		return uuid.UUID{}, false
+	}
+	return userID, true
+}
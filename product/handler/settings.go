@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/lmnzx/slopify/pkg/middleware"
+	"github.com/lmnzx/slopify/product/internal"
+
+	"github.com/valyala/fasthttp"
+)
+
+type SearchSettingsRequest struct {
+	Settings internal.SearchSettings `json:"settings"`
+	// Note says what the version changes and why
+	Note string `json:"note"`
+}
+
+func (h *RestHandler) listSearchSettings(ctx *fasthttp.RequestCtx) {
+	versions, err := h.catalog.ListSearchSettings(ctx)
+	if err != nil {
+		h.sendProductError(ctx, err)
+		return
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, versions)
+}
+
+// createSearchSettings adds a version of the settings, the index is only
+// changed once it is applied
+func (h *RestHandler) createSearchSettings(ctx *fasthttp.RequestCtx) {
+	var parsedBody SearchSettingsRequest
+	if err := json.Unmarshal(ctx.Request.Body(), &parsedBody); err != nil {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid request format")
+		return
+	}
+
+	version, err := h.catalog.CreateSearchSettings(ctx, parsedBody.Settings, parsedBody.Note, middleware.GetUserIDFromCtx(ctx))
+	if err != nil {
+		h.sendProductError(ctx, err)
+		return
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusCreated, version)
+}
+
+func (h *RestHandler) getSearchSettings(ctx *fasthttp.RequestCtx) {
+	version, ok := h.settingsVersionFromPath(ctx)
+	if !ok {
+		return
+	}
+
+	v, err := h.catalog.GetSearchSettings(ctx, version)
+	if err != nil {
+		h.sendProductError(ctx, err)
+		return
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, v)
+}
+
+// diffSearchSettings shows what applying the version would change in the
+// index
+func (h *RestHandler) diffSearchSettings(ctx *fasthttp.RequestCtx) {
+	version, ok := h.settingsVersionFromPath(ctx)
+	if !ok {
+		return
+	}
+
+	changes, err := h.catalog.DiffSearchSettings(ctx, version)
+	if err != nil {
+		h.sendProductError(ctx, err)
+		return
+	}
+
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, changes)
+}
+
+// applySearchSettings pushes the version to the index, applying an earlier
+// version rolls back to it
+func (h *RestHandler) applySearchSettings(ctx *fasthttp.RequestCtx) {
+	version, ok := h.settingsVersionFromPath(ctx)
+	if !ok {
+		return
+	}
+
+	applied, err := h.catalog.ApplySearchSettings(ctx, version)
+	if err != nil {
+		h.sendProductError(ctx, err)
+		return
+	}
+
+	h.log.Info().Str("admin_id", middleware.GetUserIDFromCtx(ctx)).Int32("version", applied.Version).Int32("previous_version", applied.PreviousVersion).Msg("search settings applied")
+	h.res.SendSuccess(ctx, fasthttp.StatusOK, applied)
+}
+
+func (h *RestHandler) settingsVersionFromPath(ctx *fasthttp.RequestCtx) (int32, bool) {
+	raw, _ := ctx.UserValue("version").(string)
+	version, err := strconv.ParseInt(raw, 10, 32)
+	if err != nil || version <= 0 {
+		h.res.SendError(ctx, fasthttp.StatusBadRequest, "invalid settings version")
+		return 0, false
+	}
+	return int32(version), true
+}
@@ -0,0 +1,381 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lmnzx/slopify/pkg/logger"
+	"github.com/lmnzx/slopify/product/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+	"github.com/valkey-io/valkey-go"
+)
+
+const (
+	// analyticsBufferSize is how many events wait for the writer before new
+	// ones are dropped, recording never blocks a request
+	analyticsBufferSize    = 10000
+	analyticsBatchSize     = 500
+	analyticsFlushInterval = 2 * time.Second
+	analyticsFlushTimeout  = 10 * time.Second
+	// AnalyticsRetention is how long searches and clicks are kept
+	AnalyticsRetention        = 90 * 24 * time.Hour
+	analyticsPruneInterval    = time.Hour
+	DefaultAnalyticsReport    = 50
+	MaxAnalyticsReport        = 500
+	DefaultAnalyticsReportAge = 7 * 24 * time.Hour
+	// clicks on a sampled search's hits are taken for this long after it
+	searchClickWindow   = time.Hour
+	searchRecordTimeout = 2 * time.Second
+)
+
+var (
+	ErrSearchNotFound = errors.New("search not found")
+	ErrHitNotInSearch = errors.New("product was not the search's hit at that position")
+)
+
+// SearchQueryStats is a row of the top queries report
+type SearchQueryStats struct {
+	Query        string  `json:"query"`
+	Searches     int64   `json:"searches"`
+	AvgHits      float64 `json:"avg_hits"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+}
+
+// ZeroResultQuery is a row of the zero result queries report
+type ZeroResultQuery struct {
+	Query          string    `json:"query"`
+	Searches       int64     `json:"searches"`
+	LastSearchedAt time.Time `json:"last_searched_at"`
+}
+
+// QueryClickThrough is a row of the click through report
+type QueryClickThrough struct {
+	Query    string `json:"query"`
+	Searches int64  `json:"searches"`
+	// ClickedSearches is the searches with at least one click
+	ClickedSearches int64 `json:"clicked_searches"`
+	Clicks          int64 `json:"clicks"`
+	// CTR is the share of searches with a click
+	CTR float64 `json:"ctr"`
+	// AvgFirstPosition is where the first click landed, 0 is the top hit
+	AvgFirstPosition float64 `json:"avg_first_position"`
+}
+
+// SearchAnalytics records a sample of searches and the clicks on their
+// results. Events are queued in memory and written in batches by Start, so a
+// request only pays for a channel send, and a sampled search for storing its
+// hits as well. Events are dropped rather than waited for when the queue is
+// full or the service stops.
+type SearchAnalytics struct {
+	queries    *repository.Queries
+	kv         valkey.Client
+	sampleRate float64
+	searches   chan repository.InsertSearchEventsParams
+	clicks     chan repository.InsertSearchClicksParams
+	dropped    atomic.Int64
+	log        zerolog.Logger
+}
+
+// NewSearchAnalytics records sampleRate of searches, from 0 for none to 1 for
+// all of them
+func NewSearchAnalytics(db *pgxpool.Pool, kv valkey.Client, sampleRate float64) *SearchAnalytics {
+	return &SearchAnalytics{
+		queries:    repository.New(db),
+		kv:         kv,
+		sampleRate: min(max(sampleRate, 0), 1),
+		searches:   make(chan repository.InsertSearchEventsParams, analyticsBufferSize),
+		clicks:     make(chan repository.InsertSearchClicksParams, analyticsBufferSize),
+		log:        logger.GetLogger(),
+	}
+}
+
+// Sample decides whether a search is recorded, deciding before building the
+// event keeps unsampled searches free
+func (a *SearchAnalytics) Sample() bool {
+	return a.sampleRate > 0 && rand.Float64() < a.sampleRate
+}
+
+// RecordSearch queues a sampled search and returns the id its clicks are
+// recorded against. The hits are stored before it returns, so the search's
+// clicks can be checked as soon as the response is out. It returns uuid.Nil
+// when the search was dropped or its hits couldn't be stored. userID is
+// uuid.Nil for guests and guestID for signed in users.
+func (a *SearchAnalytics) RecordSearch(ctx context.Context, params SearchParams, result *SearchResult, latency time.Duration, userID, guestID uuid.UUID) uuid.UUID {
+	id := uuid.New()
+	event := repository.InsertSearchEventsParams{
+		ID:        id,
+		Query:     strings.Join(strings.Fields(strings.ToLower(params.Query)), " "),
+		Filters:   params.filter(),
+		Sort:      params.Sort,
+		HitCount:  result.TotalHits,
+		LatencyMs: int32(latency.Milliseconds()),
+		UserID:    pgtype.UUID{Bytes: userID, Valid: userID != uuid.Nil},
+		GuestID:   pgtype.UUID{Bytes: guestID, Valid: guestID != uuid.Nil},
+		Degraded:  result.Degraded,
+		CreatedAt: time.Now(),
+	}
+	if event.Filters == nil {
+		event.Filters = []string{}
+	}
+
+	select {
+	case a.searches <- event:
+	default:
+		a.dropped.Add(1)
+		return uuid.Nil
+	}
+
+	seen := searchSeen{UserID: userID, GuestID: guestID, Hits: make([]int32, 0, len(result.Hits))}
+	for _, hit := range result.Hits {
+		seen.Hits = append(seen.Hits, hit.ID)
+	}
+	if err := a.storeSearchSeen(ctx, id, seen); err != nil {
+		a.log.Error().Err(err).Str("searchId", id.String()).Msg("failed to store search hits, its clicks will be refused")
+		return uuid.Nil
+	}
+
+	return id
+}
+
+// searchSeen is what a sampled search showed to whom, clicks are checked
+// against it
+type searchSeen struct {
+	UserID  uuid.UUID `json:"user_id"`
+	GuestID uuid.UUID `json:"guest_id"`
+	Hits    []int32   `json:"hits"`
+}
+
+func searchSeenKey(id uuid.UUID) string {
+	return "search:v1:" + id.String()
+}
+
+func (a *SearchAnalytics) storeSearchSeen(ctx context.Context, id uuid.UUID, seen searchSeen) error {
+	ctx, cancel := context.WithTimeout(ctx, searchRecordTimeout)
+	defer cancel()
+
+	data, err := json.Marshal(seen)
+	if err != nil {
+		return err
+	}
+	return a.kv.Do(ctx, a.kv.B().Set().Key(searchSeenKey(id)).Value(string(data)).Ex(searchClickWindow).Build()).Error()
+}
+
+// CheckClick makes sure the product was the search's hit at position and that
+// whoever clicked it ran the search, as the same user or the same guest.
+// Searches that weren't sampled or are past searchClickWindow are
+// ErrSearchNotFound.
+func (a *SearchAnalytics) CheckClick(ctx context.Context, searchID, userID, guestID uuid.UUID, productID, position int32) error {
+	data, err := a.kv.Do(ctx, a.kv.B().Get().Key(searchSeenKey(searchID)).Build()).AsBytes()
+	if valkey.IsValkeyNil(err) {
+		return ErrSearchNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	var seen searchSeen
+	if err := json.Unmarshal(data, &seen); err != nil {
+		return err
+	}
+
+	sameUser := seen.UserID != uuid.Nil && seen.UserID == userID
+	sameGuest := seen.GuestID != uuid.Nil && seen.GuestID == guestID
+	if !sameUser && !sameGuest {
+		return ErrSearchNotFound
+	}
+	if position < 0 || int(position) >= len(seen.Hits) || seen.Hits[position] != productID {
+		return ErrHitNotInSearch
+	}
+	return nil
+}
+
+// RecordClick queues a click on the hit at position, counted from 0, of a
+// sampled search
+func (a *SearchAnalytics) RecordClick(searchID uuid.UUID, productID, position int32) {
+	click := repository.InsertSearchClicksParams{
+		SearchID:  searchID,
+		ProductID: productID,
+		Position:  position,
+		CreatedAt: time.Now(),
+	}
+
+	select {
+	case a.clicks <- click:
+	default:
+		a.dropped.Add(1)
+	}
+}
+
+// Start writes the queued events until ctx is cancelled, then writes what is
+// left. It also deletes events past AnalyticsRetention.
+func (a *SearchAnalytics) Start(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	flush := time.NewTicker(analyticsFlushInterval)
+	defer flush.Stop()
+	prune := time.NewTicker(analyticsPruneInterval)
+	defer prune.Stop()
+
+	a.log.Info().Float64("sampleRate", a.sampleRate).Msg("search analytics writer started")
+
+	var searches []repository.InsertSearchEventsParams
+	var clicks []repository.InsertSearchClicksParams
+	write := func() {
+		// the writes outlive ctx so the final flush on shutdown goes through
+		writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), analyticsFlushTimeout)
+		defer cancel()
+
+		a.write(writeCtx, searches, clicks)
+		searches, clicks = searches[:0], clicks[:0]
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			for drained := false; !drained; {
+				select {
+				case event := <-a.searches:
+					searches = append(searches, event)
+				case click := <-a.clicks:
+					clicks = append(clicks, click)
+				default:
+					drained = true
+				}
+			}
+			write()
+			a.log.Info().Msg("search analytics writer stopped")
+			return
+		case event := <-a.searches:
+			searches = append(searches, event)
+			if len(searches) >= analyticsBatchSize {
+				write()
+			}
+		case click := <-a.clicks:
+			clicks = append(clicks, click)
+			if len(clicks) >= analyticsBatchSize {
+				write()
+			}
+		case <-flush.C:
+			write()
+		case <-prune.C:
+			a.prune(ctx)
+		}
+	}
+}
+
+func (a *SearchAnalytics) write(ctx context.Context, searches []repository.InsertSearchEventsParams, clicks []repository.InsertSearchClicksParams) {
+	if n := a.dropped.Swap(0); n > 0 {
+		a.log.Warn().Int64("dropped", n).Msg("search analytics queue full, events dropped")
+	}
+
+	if len(searches) > 0 {
+		if _, err := a.queries.InsertSearchEvents(ctx, searches); err != nil {
+			a.log.Error().Err(err).Int("count", len(searches)).Msg("failed to write search events")
+		}
+	}
+	if len(clicks) > 0 {
+		if _, err := a.queries.InsertSearchClicks(ctx, clicks); err != nil {
+			a.log.Error().Err(err).Int("count", len(clicks)).Msg("failed to write search clicks")
+		}
+	}
+}
+
+func (a *SearchAnalytics) prune(ctx context.Context) {
+	before := time.Now().Add(-AnalyticsRetention)
+
+	searches, err := a.queries.DeleteSearchEventsBefore(ctx, before)
+	if err != nil {
+		a.log.Error().Err(err).Msg("failed to prune search events")
+		return
+	}
+	clicks, err := a.queries.DeleteSearchClicksBefore(ctx, before)
+	if err != nil {
+		a.log.Error().Err(err).Msg("failed to prune search clicks")
+		return
+	}
+
+	if searches > 0 || clicks > 0 {
+		a.log.Info().Int64("searches", searches).Int64("clicks", clicks).Msg("search analytics pruned")
+	}
+}
+
+// AnalyticsReport selects the searches a report covers
+type AnalyticsReport struct {
+	// Since is the start of the period, DefaultAnalyticsReportAge ago when
+	// zero
+	Since time.Time
+	Limit int
+}
+
+func (r AnalyticsReport) params() (time.Time, int32) {
+	since := r.Since
+	if since.IsZero() {
+		since = time.Now().Add(-DefaultAnalyticsReportAge)
+	}
+	return since, int32(clamp(r.Limit, DefaultAnalyticsReport, MaxAnalyticsReport))
+}
+
+// TopQueries reports the most searched queries
+func (a *SearchAnalytics) TopQueries(ctx context.Context, report AnalyticsReport) ([]SearchQueryStats, error) {
+	since, limit := report.params()
+	rows, err := a.queries.TopSearchQueries(ctx, repository.TopSearchQueriesParams{Since: since, MaxResults: limit})
+	if err != nil {
+		a.log.Error().Err(err).Msg("failed to report top search queries")
+		return nil, err
+	}
+
+	out := make([]SearchQueryStats, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, SearchQueryStats(r))
+	}
+	return out, nil
+}
+
+// ZeroResultQueries reports the most searched queries that found nothing
+func (a *SearchAnalytics) ZeroResultQueries(ctx context.Context, report AnalyticsReport) ([]ZeroResultQuery, error) {
+	since, limit := report.params()
+	rows, err := a.queries.ZeroResultSearchQueries(ctx, repository.ZeroResultSearchQueriesParams{Since: since, MaxResults: limit})
+	if err != nil {
+		a.log.Error().Err(err).Msg("failed to report zero result search queries")
+		return nil, err
+	}
+
+	out := make([]ZeroResultQuery, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, ZeroResultQuery(r))
+	}
+	return out, nil
+}
+
+// ClickThrough reports how often the most searched queries lead to a click
+func (a *SearchAnalytics) ClickThrough(ctx context.Context, report AnalyticsReport) ([]QueryClickThrough, error) {
+	since, limit := report.params()
+	rows, err := a.queries.SearchClickThrough(ctx, repository.SearchClickThroughParams{Since: since, MaxResults: limit})
+	if err != nil {
+		a.log.Error().Err(err).Msg("failed to report search click through")
+		return nil, err
+	}
+
+	out := make([]QueryClickThrough, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, QueryClickThrough{
+			Query:            r.Query,
+			Searches:         r.Searches,
+			ClickedSearches:  r.ClickedSearches,
+			Clicks:           r.Clicks,
+			CTR:              float64(r.ClickedSearches) / float64(r.Searches),
+			AvgFirstPosition: r.AvgFirstPosition,
+		})
+	}
+	return out, nil
+}
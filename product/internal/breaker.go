@@ -0,0 +1,93 @@
+package internal
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// searchBreakerFailures consecutive failures open the breaker
+	searchBreakerFailures = 5
+	// searchBreakerCooldown is how long an open breaker waits before letting
+	// a request through to see whether the index is back
+	searchBreakerCooldown = 30 * time.Second
+)
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	// half open lets a single probe through, its result closes or reopens
+	// the breaker
+	breakerHalfOpen
+)
+
+// breaker stops calls to a dependency that keeps failing, so requests fall
+// back straight away instead of each waiting on it to time out
+type breaker struct {
+	mu        sync.Mutex
+	state     breakerState
+	failures  int
+	threshold int
+	cooldown  time.Duration
+	openedAt  time.Time
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{threshold: threshold, cooldown: cooldown}
+}
+
+// allow reports whether a call should be attempted. Every allowed call must
+// be followed by success or failure.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		// the probe is still out
+		return false
+	default:
+		return true
+	}
+}
+
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = breakerClosed
+	b.failures = 0
+}
+
+// failure records a failed call and reports whether it opened the breaker
+func (b *breaker) failure() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == breakerHalfOpen || (b.state == breakerClosed && b.failures >= b.threshold) {
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+		return true
+	}
+	return false
+}
+
+// abandon is for an allowed call that ended without telling whether the
+// dependency works, like a cancelled request. A probe is let through again.
+func (b *breaker) abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == breakerHalfOpen {
+		b.state = breakerOpen
+	}
+}
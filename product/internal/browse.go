@@ -0,0 +1,149 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/lmnzx/slopify/product/repository"
+)
+
+const (
+	DefaultProductPageSize = 50
+	MaxProductPageSize     = 200
+	DefaultSearchLimit     = 10
+	MaxSearchLimit         = 50
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type ProductFilter struct {
+	// Cursor is the id of the last product of the previous page
+	Cursor   string
+	PageSize int
+	// Category matches the category and its descendants
+	Category        string
+	IncludeArchived bool
+}
+
+// ListProducts pages through products ordered by id, the returned cursor is
+// empty on the last page
+func (s *CatalogService) ListProducts(ctx context.Context, filter ProductFilter) ([]repository.Product, string, error) {
+	pageSize := filter.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultProductPageSize
+	}
+	if pageSize > MaxProductPageSize {
+		pageSize = MaxProductPageSize
+	}
+
+	params := repository.ListProductsParams{
+		IncludeArchived: filter.IncludeArchived,
+		// one extra row tells us whether there is another page
+		PageSize: int32(pageSize + 1),
+	}
+	if filter.Cursor != "" {
+		after, err := strconv.ParseInt(filter.Cursor, 10, 32)
+		if err != nil {
+			return nil, "", ErrInvalidCursor
+		}
+		params.After = int32(after)
+	}
+	if filter.Category != "" {
+		categories, err := s.categorySubtree(ctx, filter.Category)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(categories) == 0 {
+			return []repository.Product{}, "", nil
+		}
+		params.Categories = categories
+	}
+
+	products, err := s.queries.ListProducts(ctx, params)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to list products")
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if len(products) > pageSize {
+		products = products[:pageSize]
+		nextCursor = strconv.Itoa(int(products[pageSize-1].ID))
+	}
+	if products == nil {
+		products = []repository.Product{}
+	}
+
+	return products, nextCursor, nil
+}
+
+// GetProducts returns the products that exist out of ids, keyed by id
+func (s *CatalogService) GetProducts(ctx context.Context, ids []int32) (map[int32]repository.Product, error) {
+	products, err := s.queries.GetProductsByIds(ctx, ids)
+	if err != nil {
+		s.log.Error().Err(err).Int("count", len(ids)).Msg("failed to get products")
+		return nil, err
+	}
+
+	found := make(map[int32]repository.Product, len(products))
+	for _, p := range products {
+		found[p.ID] = p
+	}
+	return found, nil
+}
+
+// SearchProducts runs the query against the search index and loads the hits
+// from the database, so prices and stock are current rather than whatever
+// was last indexed. Hits keep the index's ranking.
+func (s *CatalogService) SearchProducts(ctx context.Context, params SearchParams) ([]repository.Product, int64, error) {
+	req, err := params.request()
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Facets = nil
+	req.AttributesToRetrieve = []string{"id"}
+
+	raw, err := s.index.SearchRawWithContext(ctx, params.Query, req)
+	if err != nil {
+		s.log.Error().Err(err).Str("query", params.Query).Msg("search failed")
+		return nil, 0, err
+	}
+
+	var res struct {
+		Hits []struct {
+			ID int32 `json:"id"`
+		} `json:"hits"`
+		EstimatedTotalHits int64 `json:"estimatedTotalHits"`
+		TotalHits          int64 `json:"totalHits"`
+	}
+	if err := json.Unmarshal(*raw, &res); err != nil {
+		return nil, 0, err
+	}
+
+	ids := make([]int32, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		ids = append(ids, hit.ID)
+	}
+
+	found, err := s.GetProducts(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	products := make([]repository.Product, 0, len(ids))
+	for _, id := range ids {
+		// the index can briefly lag behind an archive
+		if p, ok := found[id]; ok && !p.ArchivedAt.Valid {
+			products = append(products, p)
+		}
+	}
+
+	total := res.EstimatedTotalHits
+	if req.Page > 0 {
+		total = res.TotalHits
+	}
+
+	return products, total, nil
+}
@@ -0,0 +1,179 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lmnzx/slopify/pkg/instrumentation"
+	"github.com/lmnzx/slopify/pkg/logger"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+	"github.com/valkey-io/valkey-go"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	productCacheName = "product"
+	// ids that don't exist are cached briefly so probing them can't hammer
+	// the database
+	productNotFoundTTL      = 30 * time.Second
+	productCacheLoadTimeout = 5 * time.Second
+	invalidatorRetryDelay   = time.Second
+)
+
+// ProductCache is a read-through cache of products in Valkey. Entries are
+// dropped when the products trigger reports a change, the TTL bounds how long
+// a missed notification or a load racing a write can serve an old row.
+type ProductCache struct {
+	kv      valkey.Client
+	catalog *CatalogService
+	ttl     time.Duration
+	group   singleflight.Group
+	log     zerolog.Logger
+}
+
+func NewProductCache(kv valkey.Client, catalog *CatalogService, ttl time.Duration) *ProductCache {
+	return &ProductCache{
+		kv:      kv,
+		catalog: catalog,
+		ttl:     ttl,
+		log:     logger.GetLogger(),
+	}
+}
+
+// productCacheKey is versioned so entries written with an older shape of
+// ProductWithVariants are never decoded
+func productCacheKey(id int32) string {
+	return "product:v4:" + strconv.Itoa(int(id))
+}
+
+// GetProduct returns the product with its variants whether or not it is
+// archived, like CatalogService.GetProductWithVariants. Concurrent misses for
+// the same id share a single database load.
+func (c *ProductCache) GetProduct(ctx context.Context, id int32) (ProductWithVariants, error) {
+	key := productCacheKey(id)
+
+	if product, found, ok := c.lookup(ctx, id); ok {
+		instrumentation.RecordCacheLookup(ctx, productCacheName, true)
+		if !found {
+			return ProductWithVariants{}, ErrProductNotFound
+		}
+		return product, nil
+	}
+	instrumentation.RecordCacheLookup(ctx, productCacheName, false)
+
+	v, err, _ := c.group.Do(key, func() (any, error) {
+		// the load is shared, one caller giving up shouldn't fail the others
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), productCacheLoadTimeout)
+		defer cancel()
+
+		product, err := c.catalog.GetProductWithVariants(loadCtx, id)
+		if errors.Is(err, ErrProductNotFound) {
+			c.set(loadCtx, key, nil, productNotFoundTTL)
+			return nil, err
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := json.Marshal(product)
+		if err != nil {
+			return nil, err
+		}
+		c.set(loadCtx, key, data, c.ttl)
+		return product, nil
+	})
+	if err != nil {
+		return ProductWithVariants{}, err
+	}
+
+	return v.(ProductWithVariants), nil
+}
+
+// lookup reports ok when the cache had an answer, found is false when that
+// answer is that the product doesn't exist
+func (c *ProductCache) lookup(ctx context.Context, id int32) (product ProductWithVariants, found bool, ok bool) {
+	data, err := c.kv.Do(ctx, c.kv.B().Get().Key(productCacheKey(id)).Build()).AsBytes()
+	if err != nil {
+		if !valkey.IsValkeyNil(err) {
+			// a cache outage falls back to the database rather than failing reads
+			c.log.Error().Err(err).Int32("productId", id).Msg("failed to read product cache")
+		}
+		return product, false, false
+	}
+	if len(data) == 0 {
+		return product, false, true
+	}
+
+	if err := json.Unmarshal(data, &product); err != nil {
+		c.log.Error().Err(err).Int32("productId", id).Msg("failed to decode cached product")
+		return product, false, false
+	}
+	return product, true, true
+}
+
+func (c *ProductCache) set(ctx context.Context, key string, data []byte, ttl time.Duration) {
+	err := c.kv.Do(ctx, c.kv.B().Set().Key(key).Value(valkey.BinaryString(data)).Ex(ttl).Build()).Error()
+	if err != nil {
+		c.log.Error().Err(err).Str("key", key).Msg("failed to write product cache")
+	}
+}
+
+// Invalidate drops the cached product
+func (c *ProductCache) Invalidate(ctx context.Context, id int32) {
+	if err := c.kv.Do(ctx, c.kv.B().Del().Key(productCacheKey(id)).Build()).Error(); err != nil {
+		c.log.Error().Err(err).Int32("productId", id).Msg("failed to invalidate product cache")
+	}
+}
+
+// StartInvalidator drops cached products as the products trigger reports
+// changes, so writes from the admin API, imports and anything else touching
+// the table are all covered. It runs until ctx is cancelled.
+func (c *ProductCache) StartInvalidator(ctx context.Context, db *pgxpool.Pool, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	c.log.Info().Msg("product cache invalidator started")
+
+	for {
+		err := c.runInvalidator(ctx, db)
+		if ctx.Err() != nil {
+			c.log.Info().Msg("product cache invalidator stopped")
+			return
+		}
+		c.log.Error().Err(err).Dur("retryIn", invalidatorRetryDelay).Msg("product cache invalidator failed")
+
+		select {
+		case <-ctx.Done():
+			c.log.Info().Msg("product cache invalidator stopped")
+			return
+		case <-time.After(invalidatorRetryDelay):
+		}
+	}
+}
+
+func (c *ProductCache) runInvalidator(ctx context.Context, db *pgxpool.Pool) error {
+	conn, release, err := listen(ctx, db, productChangeChannel)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	for {
+		n, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		id, err := strconv.ParseInt(n.Payload, 10, 32)
+		if err != nil {
+			c.log.Warn().Str("payload", n.Payload).Msg("unexpected product change notification")
+			continue
+		}
+		c.Invalidate(ctx, int32(id))
+	}
+}
@@ -0,0 +1,219 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/lmnzx/slopify/product/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	maxCategorySlugLength = 64
+	maxCategoryNameLength = 100
+)
+
+// same as the check on categories.slug
+var categorySlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+var (
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrCategoryExists   = errors.New("a category with this slug already exists")
+)
+
+type CategoryInput struct {
+	Slug string
+	Name string
+	// Parent is the slug of the parent category, empty for a top level one
+	Parent   string
+	Position int32
+}
+
+func (in *CategoryInput) normalize() {
+	in.Slug = strings.ToLower(strings.TrimSpace(in.Slug))
+	in.Name = strings.TrimSpace(in.Name)
+	in.Parent = strings.ToLower(strings.TrimSpace(in.Parent))
+}
+
+func (in *CategoryInput) validate() error {
+	if !categorySlugPattern.MatchString(in.Slug) || len(in.Slug) > maxCategorySlugLength {
+		return &ProductValidationError{Field: "slug", Reason: fmt.Sprintf("must be at most %d lowercase letters, digits and single dashes", maxCategorySlugLength)}
+	}
+	if in.Name == "" {
+		return &ProductValidationError{Field: "name", Reason: "is required"}
+	}
+	if len(in.Name) > maxCategoryNameLength {
+		return &ProductValidationError{Field: "name", Reason: fmt.Sprintf("must be at most %d characters", maxCategoryNameLength)}
+	}
+	return nil
+}
+
+// CategoryNode is a category along with its children in display order
+type CategoryNode struct {
+	ID       int32           `json:"id"`
+	Slug     string          `json:"slug"`
+	Name     string          `json:"name"`
+	Children []*CategoryNode `json:"children"`
+}
+
+type CategoryCrumb struct {
+	ID   int32  `json:"id"`
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+// CategoryDetail is a category with its subtree and the path to it from the
+// top of the tree
+type CategoryDetail struct {
+	*CategoryNode
+	// Breadcrumb runs from the top level category down to this one
+	Breadcrumb []CategoryCrumb `json:"breadcrumb"`
+}
+
+// buildCategoryTree links the categories to their parents, keeping the order
+// they are given in among siblings. Categories whose parent isn't in the list
+// are returned as roots.
+func buildCategoryTree(categories []repository.Category) []*CategoryNode {
+	nodes := make(map[int32]*CategoryNode, len(categories))
+	for _, c := range categories {
+		nodes[c.ID] = &CategoryNode{ID: c.ID, Slug: c.Slug, Name: c.Name, Children: []*CategoryNode{}}
+	}
+
+	roots := []*CategoryNode{}
+	for _, c := range categories {
+		if parent, ok := nodes[c.ParentID.Int32]; ok && c.ParentID.Valid {
+			parent.Children = append(parent.Children, nodes[c.ID])
+		} else {
+			roots = append(roots, nodes[c.ID])
+		}
+	}
+	return roots
+}
+
+// CategoryTree returns the top level categories with their descendants
+func (s *CatalogService) CategoryTree(ctx context.Context) ([]*CategoryNode, error) {
+	categories, err := s.queries.ListCategories(ctx)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to list categories")
+		return nil, err
+	}
+	return buildCategoryTree(categories), nil
+}
+
+func (s *CatalogService) GetCategory(ctx context.Context, slug string) (*CategoryDetail, error) {
+	slug = strings.ToLower(strings.TrimSpace(slug))
+
+	ancestors, err := s.queries.GetCategoryBreadcrumb(ctx, slug)
+	if err != nil {
+		s.log.Error().Err(err).Str("slug", slug).Msg("failed to get category breadcrumb")
+		return nil, err
+	}
+	if len(ancestors) == 0 {
+		return nil, ErrCategoryNotFound
+	}
+
+	subtree, err := s.queries.ListCategorySubtree(ctx, slug)
+	if err != nil {
+		s.log.Error().Err(err).Str("slug", slug).Msg("failed to get category subtree")
+		return nil, err
+	}
+	roots := buildCategoryTree(subtree)
+	if len(roots) == 0 {
+		// removed between the two queries
+		return nil, ErrCategoryNotFound
+	}
+
+	breadcrumb := make([]CategoryCrumb, 0, len(ancestors))
+	for _, c := range ancestors {
+		breadcrumb = append(breadcrumb, CategoryCrumb{ID: c.ID, Slug: c.Slug, Name: c.Name})
+	}
+
+	return &CategoryDetail{CategoryNode: roots[0], Breadcrumb: breadcrumb}, nil
+}
+
+// categorySubtree returns the slugs of the category and its descendants, none
+// when the category doesn't exist
+func (s *CatalogService) categorySubtree(ctx context.Context, slug string) ([]string, error) {
+	subtree, err := s.queries.ListCategorySubtree(ctx, strings.ToLower(strings.TrimSpace(slug)))
+	if err != nil {
+		s.log.Error().Err(err).Str("slug", slug).Msg("failed to get category subtree")
+		return nil, err
+	}
+
+	slugs := make([]string, 0, len(subtree))
+	for _, c := range subtree {
+		slugs = append(slugs, c.Slug)
+	}
+	return slugs, nil
+}
+
+// CreateCategory adds a category under in.Parent. Categories can't be moved
+// once created, so search documents never need their category path changed.
+func (s *CatalogService) CreateCategory(ctx context.Context, in CategoryInput) (repository.Category, error) {
+	in.normalize()
+	if err := in.validate(); err != nil {
+		return repository.Category{}, err
+	}
+
+	params := repository.InsertCategoryParams{
+		Slug:     in.Slug,
+		Name:     in.Name,
+		Position: in.Position,
+	}
+	if in.Parent != "" {
+		parent, err := s.queries.GetCategoryBySlug(ctx, in.Parent)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return repository.Category{}, &ProductValidationError{Field: "parent", Reason: "is not a known category"}
+			}
+			s.log.Error().Err(err).Str("slug", in.Parent).Msg("failed to get parent category")
+			return repository.Category{}, err
+		}
+		params.ParentID = pgtype.Int4{Int32: parent.ID, Valid: true}
+	}
+
+	category, err := s.queries.InsertCategory(ctx, params)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return repository.Category{}, ErrCategoryExists
+		}
+		s.log.Error().Err(err).Str("slug", in.Slug).Msg("failed to create category")
+		return repository.Category{}, err
+	}
+
+	s.log.Info().Int32("categoryId", category.ID).Str("slug", category.Slug).Msg("category created")
+
+	return category, nil
+}
+
+// categoryPaths maps every category slug to the slugs from the top of the
+// tree down to it
+func categoryPaths(ctx context.Context, q *repository.Queries) (map[string][]string, error) {
+	rows, err := q.ListCategoryPaths(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string][]string, len(rows))
+	for _, r := range rows {
+		paths[r.Slug] = r.Path
+	}
+	return paths, nil
+}
+
+// unknownCategory turns a product referring to a category that doesn't exist
+// into a validation error
+func unknownCategory(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" && pgErr.ConstraintName == "products_category_fkey" {
+		return &ProductValidationError{Field: "category", Reason: "is not a known category"}
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package internal
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/lmnzx/slopify/pkg/money"
+	"github.com/lmnzx/slopify/product/repository"
+
+	"github.com/meilisearch/meilisearch-go"
+)
+
+// fullTextSearchLimit caps the products the database search returns, the
+// facets and pages are worked out from these
+const fullTextSearchLimit = 1000
+
+// fullTextSearch answers a search from Postgres when the index can't. The
+// database ranks and filters the matches, so the limit only drops matching
+// products past the first fullTextSearchLimit. The rest of the request is
+// applied to their documents the way the index would.
+func (s *CatalogService) fullTextSearch(ctx context.Context, params SearchParams, req *meilisearch.SearchRequest) (*SearchResult, error) {
+	categories, err := s.searchCategories(ctx, params.Categories)
+	if err != nil {
+		return nil, err
+	}
+
+	currencies := money.Currencies()
+	codes := make([]string, 0, len(currencies))
+	exponents := make([]int32, 0, len(currencies))
+	for _, c := range currencies {
+		codes = append(codes, string(c))
+		exponents = append(exponents, int32(c.Exponent()))
+	}
+
+	query := repository.SearchProductsFullTextParams{
+		Query:      strings.TrimSpace(params.Query),
+		Categories: categories,
+		Currencies: codes,
+		Exponents:  exponents,
+		MinPrice:   math.Inf(-1),
+		MaxPrice:   math.Inf(1),
+		InStock:    params.InStock,
+		MaxResults: fullTextSearchLimit,
+	}
+	if params.MinPrice != nil {
+		query.MinPrice = float64(*params.MinPrice)
+	}
+	if params.MaxPrice != nil {
+		query.MaxPrice = float64(*params.MaxPrice)
+	}
+	if params.MinDiscount != nil {
+		query.MinDiscount = float64(*params.MinDiscount)
+	}
+
+	products, err := s.queries.SearchProductsFullText(ctx, query)
+	if err != nil {
+		s.log.Error().Err(err).Str("query", params.Query).Msg("database search failed")
+		return nil, err
+	}
+
+	ids := make([]int32, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+	variants, err := s.GetVariants(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	paths, err := categoryPaths(ctx, s.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SearchResult{
+		Hits:       []ProductDocument{},
+		Categories: map[string]int64{},
+		Degraded:   true,
+	}
+
+	var matched []ProductDocument
+	for i := range products {
+		doc := NewProductDocument(&products[i], variants[products[i].ID], paths[products[i].Category])
+		if !params.matches(&doc) {
+			continue
+		}
+		matched = append(matched, doc)
+
+		result.Categories[doc.Category]++
+		result.Price = extendStats(result.Price, doc.Price)
+		result.Discount = extendStats(result.Discount, doc.DiscountPercentage)
+	}
+
+	if len(req.Sort) > 0 {
+		attr, dir, _ := strings.Cut(req.Sort[0], ":")
+		slices.SortStableFunc(matched, func(a, b ProductDocument) int {
+			c := cmp.Compare(sortValue(&a, attr), sortValue(&b, attr))
+			if dir == "desc" {
+				return -c
+			}
+			return c
+		})
+	}
+
+	total := int64(len(matched))
+	result.TotalHits = total
+	var start, end int64
+	if req.Page > 0 {
+		result.Page = req.Page
+		result.HitsPerPage = req.HitsPerPage
+		result.TotalPages = (total + req.HitsPerPage - 1) / req.HitsPerPage
+		start = (req.Page - 1) * req.HitsPerPage
+		end = start + req.HitsPerPage
+	} else {
+		result.Offset = req.Offset
+		result.Limit = req.Limit
+		start = req.Offset
+		end = start + req.Limit
+	}
+	if start < total {
+		result.Hits = matched[start:min(end, total)]
+	}
+
+	return result, nil
+}
+
+// searchCategories returns the slugs a category filter matches, the categories
+// and their descendants. A category that doesn't exist still matches products
+// filed under its slug, as their documents' category path does. It is nil
+// without a filter.
+func (s *CatalogService) searchCategories(ctx context.Context, categories []string) ([]string, error) {
+	if len(categories) == 0 {
+		return nil, nil
+	}
+
+	slugs := []string{}
+	for _, c := range categories {
+		subtree, err := s.categorySubtree(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		slugs = append(slugs, strings.ToLower(strings.TrimSpace(c)))
+		slugs = append(slugs, subtree...)
+	}
+	return slugs, nil
+}
+
+// matches applies filter to a single document, the ones the database search
+// already applied match again
+func (p *SearchParams) matches(doc *ProductDocument) bool {
+	if len(p.Categories) > 0 && !slices.ContainsFunc(p.Categories, func(c string) bool {
+		return slices.Contains(doc.CategoryPath, strings.ToLower(strings.TrimSpace(c)))
+	}) {
+		return false
+	}
+	if p.MinPrice != nil && doc.Price < float64(*p.MinPrice) {
+		return false
+	}
+	if p.MaxPrice != nil && doc.Price > float64(*p.MaxPrice) {
+		return false
+	}
+	if p.MinDiscount != nil && doc.DiscountPercentage < float64(*p.MinDiscount) {
+		return false
+	}
+	if p.MinRating != nil && doc.Rating < float64(*p.MinRating) {
+		return false
+	}
+	if p.InStock && doc.Stock <= 0 {
+		return false
+	}
+	if len(p.Options) > 0 && !slices.Contains(doc.VariantOptions, optionKey(p.Options)) {
+		return false
+	}
+	return true
+}
+
+func sortValue(doc *ProductDocument, attr string) float64 {
+	switch attr {
+	case attrPrice:
+		return doc.Price
+	case attrDiscount:
+		return doc.DiscountPercentage
+	case attrRating:
+		return doc.Rating
+	default:
+		return 0
+	}
+}
+
+func extendStats(stats *FacetStats, v float64) *FacetStats {
+	if stats == nil {
+		return &FacetStats{Min: v, Max: v}
+	}
+	stats.Min = min(stats.Min, v)
+	stats.Max = max(stats.Max, v)
+	return stats
+}
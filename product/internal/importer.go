@@ -0,0 +1,385 @@
+package internal
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/lmnzx/slopify/pkg/money"
+	"github.com/lmnzx/slopify/product/repository"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	DefaultImportBatchSize = 500
+	maxSKULength           = 64
+)
+
+var ErrUnsupportedImportFormat = errors.New("unsupported import format, use .json, .ndjson, .jsonl or .csv")
+
+// ImportRecord is one product in an import file. CSV files use the json names
+// as their header.
+type ImportRecord struct {
+	SKU         string `json:"sku"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Category    string `json:"category"`
+	// Price is in major units, it is kept as written so "19.99" doesn't go
+	// through a float
+	Price json.Number `json:"price"`
+	// Currency defaults to USD
+	Currency string `json:"currency"`
+	// Discount is a percentage, "12.5" is 12.5% off
+	Discount        json.Number `json:"discount"`
+	QuantityInStock int32       `json:"quantity_in_stock"`
+}
+
+// ImportRow is a record along with where it came from. Rows that could not be
+// parsed carry Err instead of a record.
+type ImportRow struct {
+	// Row is the 1-based position of the record in the file, for CSV and
+	// NDJSON it is the line number
+	Row    int
+	Record ImportRecord
+	Err    error
+}
+
+type ImportRowError struct {
+	Row    int    `json:"row"`
+	SKU    string `json:"sku,omitempty"`
+	Reason string `json:"reason"`
+}
+
+type ImportReport struct {
+	Created   int              `json:"created"`
+	Updated   int              `json:"updated"`
+	Unchanged int              `json:"unchanged"`
+	Errors    []ImportRowError `json:"errors"`
+}
+
+// ReadImportFile parses a JSON array, NDJSON or CSV file depending on its
+// extension. A row that fails to parse doesn't stop the rest of the file.
+func ReadImportFile(path string) ([]ImportRow, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return readJSONImport(f)
+	case ".ndjson", ".jsonl":
+		return readNDJSONImport(f)
+	case ".csv":
+		return readCSVImport(f)
+	default:
+		return nil, ErrUnsupportedImportFormat
+	}
+}
+
+func readJSONImport(r io.Reader) ([]ImportRow, error) {
+	var raw []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("expected a json array of products: %w", err)
+	}
+
+	rows := make([]ImportRow, 0, len(raw))
+	for i, msg := range raw {
+		row := ImportRow{Row: i + 1}
+		row.Err = json.Unmarshal(msg, &row.Record)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func readNDJSONImport(r io.Reader) ([]ImportRow, error) {
+	dec := json.NewDecoder(r)
+
+	var rows []ImportRow
+	for line := 1; ; line++ {
+		var msg json.RawMessage
+		err := dec.Decode(&msg)
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			// the decoder can't resync after a syntax error
+			return nil, fmt.Errorf("record %d: %w", line, err)
+		}
+
+		row := ImportRow{Row: line}
+		row.Err = json.Unmarshal(msg, &row.Record)
+		rows = append(rows, row)
+	}
+}
+
+func readCSVImport(r io.Reader) ([]ImportRow, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	// trailing optional columns may be left off
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"sku", "title", "category", "price"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("csv header is missing the %s column", required)
+		}
+	}
+
+	var rows []ImportRow
+	for {
+		fields, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+
+		var row ImportRow
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, err
+			}
+			row.Row = parseErr.StartLine
+			row.Err = parseErr.Err
+		} else {
+			row.Row, _ = cr.FieldPos(0)
+			row.Record, row.Err = csvRecord(columns, fields)
+		}
+		rows = append(rows, row)
+	}
+}
+
+func csvRecord(columns map[string]int, fields []string) (ImportRecord, error) {
+	get := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(fields) {
+			return strings.TrimSpace(fields[i])
+		}
+		return ""
+	}
+
+	rec := ImportRecord{
+		SKU:         get("sku"),
+		Title:       get("title"),
+		Description: get("description"),
+		Category:    get("category"),
+		Price:       json.Number(get("price")),
+		Currency:    get("currency"),
+		Discount:    json.Number(get("discount")),
+	}
+
+	if v := get("quantity_in_stock"); v != "" {
+		stock, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return rec, errors.New("quantity_in_stock is not an integer")
+		}
+		rec.QuantityInStock = int32(stock)
+	}
+
+	return rec, nil
+}
+
+func (r *ImportRecord) toInput() (ProductInput, error) {
+	currency := money.DefaultCurrency
+	if r.Currency != "" {
+		var err error
+		if currency, err = money.ParseCurrency(r.Currency); err != nil {
+			return ProductInput{}, err
+		}
+	}
+
+	price, err := money.Parse(r.Price.String(), currency)
+	if err != nil {
+		return ProductInput{}, fmt.Errorf("price: %w", err)
+	}
+
+	var discount money.BasisPoints
+	if r.Discount != "" {
+		if discount, err = money.ParsePercent(r.Discount.String()); err != nil {
+			return ProductInput{}, fmt.Errorf("discount: %w", err)
+		}
+	}
+
+	return ProductInput{
+		Title:           r.Title,
+		Description:     r.Description,
+		Category:        r.Category,
+		Price:           price,
+		Discount:        discount,
+		QuantityInStock: r.QuantityInStock,
+	}, nil
+}
+
+type validImportRow struct {
+	row int
+	sku string
+	in  ProductInput
+}
+
+// Import upserts the rows by sku in transactions of batchSize rows. A product
+// without a sku that has a row's title and category is taken to be that row,
+// so catalogs seeded before skus are updated instead of duplicated. Invalid
+// rows are reported and skipped, a database error stops the import and
+// returns what was committed so far along with the error.
+func (s *CatalogService) Import(ctx context.Context, rows []ImportRow, batchSize int) (*ImportReport, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultImportBatchSize
+	}
+
+	report := &ImportReport{Errors: []ImportRowError{}}
+
+	// checked up front, a row with an unknown category would otherwise fail
+	// its whole batch
+	categories, err := s.queries.ListCategories(ctx)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to list categories")
+		return report, err
+	}
+	known := make(map[string]struct{}, len(categories))
+	for _, c := range categories {
+		known[c.Slug] = struct{}{}
+	}
+
+	valid := make([]validImportRow, 0, len(rows))
+	seen := make(map[string]int, len(rows))
+
+	for _, row := range rows {
+		sku := strings.TrimSpace(row.Record.SKU)
+		fail := func(reason string) {
+			report.Errors = append(report.Errors, ImportRowError{Row: row.Row, SKU: sku, Reason: reason})
+		}
+
+		if row.Err != nil {
+			fail(row.Err.Error())
+			continue
+		}
+		if sku == "" {
+			fail("sku is required")
+			continue
+		}
+		if len(sku) > maxSKULength {
+			fail(fmt.Sprintf("sku must be at most %d characters", maxSKULength))
+			continue
+		}
+		if first, ok := seen[sku]; ok {
+			fail(fmt.Sprintf("duplicate sku, first seen in row %d", first))
+			continue
+		}
+		seen[sku] = row.Row
+
+		in, err := row.Record.toInput()
+		if err != nil {
+			fail(err.Error())
+			continue
+		}
+		in.normalize()
+		if err := in.validate(); err != nil {
+			fail(err.Error())
+			continue
+		}
+		if _, ok := known[in.Category]; !ok {
+			fail((&ProductValidationError{Field: "category", Reason: "is not a known category"}).Error())
+			continue
+		}
+
+		valid = append(valid, validImportRow{row: row.Row, sku: sku, in: in})
+	}
+
+	for start := 0; start < len(valid); start += batchSize {
+		batch := valid[start:min(start+batchSize, len(valid))]
+
+		var created, updated, unchanged int
+		err := s.withTx(ctx, func(q *repository.Queries) error {
+			for _, v := range batch {
+				if _, err := q.AdoptProductSKU(ctx, repository.AdoptProductSKUParams{
+					Sku:      v.sku,
+					Title:    v.in.Title,
+					Category: v.in.Category,
+				}); err != nil {
+					return fmt.Errorf("row %d (sku %s): %w", v.row, v.sku, err)
+				}
+				res, err := q.UpsertProductBySKU(ctx, repository.UpsertProductBySKUParams{
+					Sku:             v.sku,
+					Title:           v.in.Title,
+					Description:     v.in.Description,
+					Category:        v.in.Category,
+					PriceMinor:      v.in.Price.Amount,
+					Currency:        v.in.Price.Currency,
+					DiscountBps:     v.in.Discount,
+					QuantityInStock: v.in.QuantityInStock,
+				})
+				switch {
+				case errors.Is(err, pgx.ErrNoRows):
+					unchanged++
+				case err != nil:
+					return fmt.Errorf("row %d (sku %s): %w", v.row, v.sku, err)
+				case res.Inserted:
+					created++
+				default:
+					updated++
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			s.log.Error().Err(err).Msg("import batch failed")
+			return report, err
+		}
+
+		report.Created += created
+		report.Updated += updated
+		report.Unchanged += unchanged
+		s.log.Info().Int("rows", start+len(batch)).Int("of", len(valid)).Msg("import batch committed")
+	}
+
+	return report, nil
+}
+
+// ImportCategories creates the categories of a JSON array file that don't
+// exist yet, parents have to be listed before their children. Existing
+// categories are left as they are.
+func (s *CatalogService) ImportCategories(ctx context.Context, path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var records []struct {
+		Slug     string `json:"slug"`
+		Name     string `json:"name"`
+		Parent   string `json:"parent"`
+		Position int32  `json:"position"`
+	}
+	if err := json.NewDecoder(f).Decode(&records); err != nil {
+		return 0, fmt.Errorf("expected a json array of categories: %w", err)
+	}
+
+	var created int
+	for i, r := range records {
+		_, err := s.CreateCategory(ctx, CategoryInput{Slug: r.Slug, Name: r.Name, Parent: r.Parent, Position: r.Position})
+		if errors.Is(err, ErrCategoryExists) {
+			continue
+		}
+		if err != nil {
+			return created, fmt.Errorf("category %d (%s): %w", i+1, r.Slug, err)
+		}
+		created++
+	}
+	return created, nil
+}
@@ -0,0 +1,246 @@
+package internal
+
+import (
+	"context"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lmnzx/slopify/pkg/instrumentation"
+	"github.com/lmnzx/slopify/product/repository"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// productIndexChannel is notified with the id of every product queued for
+	// the index, see the product_index_queue migration
+	productIndexChannel = "product_index"
+	// productChangeChannel is notified with the id of every changed product,
+	// including stock changes that leave the search document as it is
+	productChangeChannel = "product_change"
+	indexBatchSize       = 500
+	// notifications are only a wake-up call, the queue is also polled in case
+	// one was missed while the listener was reconnecting
+	indexPollInterval = 5 * time.Second
+	indexTaskTimeout  = 30 * time.Second
+	indexMinBackoff   = 500 * time.Millisecond
+	indexMaxBackoff   = 30 * time.Second
+)
+
+// StartIndexer applies queued product changes to the search index until ctx
+// is cancelled. A change only leaves the queue once Meilisearch reports the
+// task as succeeded, failures are retried with exponential backoff.
+func (s *CatalogService) StartIndexer(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	s.log.Info().Msg("product indexer started")
+
+	backoff := indexMinBackoff
+	for {
+		started := time.Now()
+		err := s.runIndexer(ctx)
+		if ctx.Err() != nil {
+			s.log.Info().Msg("product indexer stopped")
+			return
+		}
+
+		// a long healthy run means this is a new failure, not the same one
+		if time.Since(started) > indexMaxBackoff {
+			backoff = indexMinBackoff
+		}
+		s.recordIndexLag(ctx)
+
+		wait := backoff/2 + rand.N(backoff/2+1)
+		s.log.Error().Err(err).Dur("retryIn", wait).Msg("product indexer failed")
+
+		select {
+		case <-ctx.Done():
+			s.log.Info().Msg("product indexer stopped")
+			return
+		case <-time.After(wait):
+		}
+		backoff = min(backoff*2, indexMaxBackoff)
+	}
+}
+
+// runIndexer listens for changes on a dedicated connection and drains the
+// queue whenever it is woken up. It only returns on error.
+func (s *CatalogService) runIndexer(ctx context.Context) error {
+	conn, release, err := listen(ctx, s.db, productIndexChannel)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	for {
+		if err := s.drainIndexQueue(ctx); err != nil {
+			return err
+		}
+		s.recordIndexLag(ctx)
+
+		waitCtx, cancel := context.WithTimeout(ctx, indexPollInterval)
+		_, err := conn.Conn().WaitForNotification(waitCtx)
+		cancel()
+		if err != nil && !pgconn.Timeout(err) {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// listen acquires a connection subscribed to channel. release unsubscribes it
+// before handing it back, so pooled connections don't keep collecting
+// notifications nobody reads.
+func listen(ctx context.Context, db *pgxpool.Pool, channel string) (*pgxpool.Conn, func(), error) {
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := conn.Exec(ctx, "LISTEN "+channel); err != nil {
+		conn.Release()
+		return nil, nil, err
+	}
+
+	release := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		if _, err := conn.Exec(ctx, "UNLISTEN "+channel); err != nil {
+			// don't return a connection in an unknown state to the pool
+			conn.Conn().Close(ctx)
+		}
+		conn.Release()
+	}
+	return conn, release, nil
+}
+
+func (s *CatalogService) drainIndexQueue(ctx context.Context) error {
+	for {
+		synced, err := s.syncIndexBatch(ctx)
+		if err != nil {
+			return err
+		}
+		if synced < indexBatchSize {
+			return nil
+		}
+	}
+}
+
+// syncIndexBatch applies the oldest queued changes. The products are read
+// when the batch is applied rather than when they changed, so several changes
+// to one product collapse into a single upsert or delete of its latest state.
+func (s *CatalogService) syncIndexBatch(ctx context.Context) (int, error) {
+	var synced int
+
+	err := s.withTx(ctx, func(q *repository.Queries) error {
+		if err := q.LockProductIndexQueue(ctx); err != nil {
+			return err
+		}
+
+		entries, err := q.ListProductIndexQueue(ctx, indexBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+
+		entryIDs := make([]int64, 0, len(entries))
+		productIDs := make([]int32, 0, len(entries))
+		seen := make(map[int32]struct{}, len(entries))
+		for _, e := range entries {
+			entryIDs = append(entryIDs, e.ID)
+			if _, ok := seen[e.ProductID]; ok {
+				continue
+			}
+			seen[e.ProductID] = struct{}{}
+			productIDs = append(productIDs, e.ProductID)
+		}
+
+		products, err := q.GetProductsByIds(ctx, productIDs)
+		if err != nil {
+			return err
+		}
+		found := make(map[int32]*repository.Product, len(products))
+		for i := range products {
+			found[products[i].ID] = &products[i]
+		}
+
+		variantRows, err := q.ListVariantsByProductIds(ctx, productIDs)
+		if err != nil {
+			return err
+		}
+		variants := groupVariants(variantRows)
+
+		paths, err := categoryPaths(ctx, q)
+		if err != nil {
+			return err
+		}
+
+		var upserts []ProductDocument
+		var deletes []string
+		for _, id := range productIDs {
+			// deleted and archived products both leave the index
+			if p, ok := found[id]; ok && !p.ArchivedAt.Valid {
+				upserts = append(upserts, NewProductDocument(p, variants[id], paths[p.Category]))
+			} else {
+				deletes = append(deletes, strconv.Itoa(int(id)))
+			}
+		}
+
+		if len(upserts) > 0 {
+			task, err := s.index.AddDocumentsWithContext(ctx, upserts, "id")
+			if err != nil {
+				return err
+			}
+			if err := s.waitForIndexTask(ctx, task.TaskUID); err != nil {
+				return err
+			}
+		}
+		if len(deletes) > 0 {
+			task, err := s.index.DeleteDocumentsWithContext(ctx, deletes)
+			if err != nil {
+				return err
+			}
+			if err := s.waitForIndexTask(ctx, task.TaskUID); err != nil {
+				return err
+			}
+		}
+
+		if err := q.DeleteProductIndexQueue(ctx, entryIDs); err != nil {
+			return err
+		}
+
+		s.log.Info().Int("upserts", len(upserts)).Int("deletes", len(deletes)).Msg("search index synced")
+		synced = len(entries)
+		return nil
+	})
+
+	return synced, err
+}
+
+func (s *CatalogService) waitForIndexTask(ctx context.Context, taskUID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, indexTaskTimeout)
+	defer cancel()
+
+	return s.waitForTask(ctx, taskUID)
+}
+
+func (s *CatalogService) recordIndexLag(ctx context.Context) {
+	stats, err := s.queries.GetProductIndexQueueStats(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.log.Error().Err(err).Msg("failed to get index queue stats")
+		}
+		return
+	}
+
+	lag := time.Duration(stats.LagSeconds * float64(time.Second))
+	instrumentation.RecordIndexSyncLag(ctx, ProductIndex, lag, stats.Pending)
+}
@@ -0,0 +1,328 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/lmnzx/slopify/pkg/logger"
+	"github.com/lmnzx/slopify/pkg/money"
+	"github.com/lmnzx/slopify/product/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/rs/zerolog"
+)
+
+const (
+	maxTitleLength       = 200
+	maxDescriptionLength = 5000
+	// the limit of the DECIMAL(10, 2) products.price used to be
+	maxPriceMinor money.Minor = 99_999_999_99
+)
+
+var ErrProductNotFound = errors.New("product not found")
+
+type ProductValidationError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ProductValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Reason)
+}
+
+type ProductInput struct {
+	Title           string
+	Description     string
+	Category        string
+	Price           money.Money
+	Discount        money.BasisPoints
+	QuantityInStock int32
+}
+
+func (in *ProductInput) normalize() {
+	in.Title = strings.TrimSpace(in.Title)
+	in.Description = strings.TrimSpace(in.Description)
+	in.Category = strings.ToLower(strings.TrimSpace(in.Category))
+	in.Price.Currency = money.Currency(strings.ToUpper(strings.TrimSpace(string(in.Price.Currency))))
+}
+
+func (in *ProductInput) validate() error {
+	if in.Title == "" {
+		return &ProductValidationError{Field: "title", Reason: "is required"}
+	}
+	if len(in.Title) > maxTitleLength {
+		return &ProductValidationError{Field: "title", Reason: fmt.Sprintf("must be at most %d characters", maxTitleLength)}
+	}
+	if len(in.Description) > maxDescriptionLength {
+		return &ProductValidationError{Field: "description", Reason: fmt.Sprintf("must be at most %d characters", maxDescriptionLength)}
+	}
+	if in.Category == "" {
+		return &ProductValidationError{Field: "category", Reason: "is required"}
+	}
+	if !in.Price.Currency.Valid() {
+		return &ProductValidationError{Field: "price", Reason: "has an unsupported currency"}
+	}
+	if in.Price.Amount <= 0 || in.Price.Amount > maxPriceMinor {
+		return &ProductValidationError{Field: "price", Reason: fmt.Sprintf("must be greater than 0 and at most %d minor units", maxPriceMinor)}
+	}
+	if in.Discount < 0 || in.Discount > money.MaxBasisPoints {
+		return &ProductValidationError{Field: "discount", Reason: "must be between 0 and 10000 basis points"}
+	}
+	if in.QuantityInStock < 0 {
+		return &ProductValidationError{Field: "quantity_in_stock", Reason: "cannot be negative"}
+	}
+	return nil
+}
+
+// ProductDocument is the shape of a product in the search index. The field
+// names follow the ones the index was first seeded with. Price and
+// DiscountPercentage are floats only so the index can filter and sort on them,
+// PriceMinor is the exact price. For a product with variants the price is the
+// lowest variant price and the stock is the sum of the variants' stock.
+type ProductDocument struct {
+	ID          int32  `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Category    string `json:"category"`
+	// CategoryPath is the slugs from the top level category down to
+	// Category, filtering on it matches a category's descendants too
+	CategoryPath       []string       `json:"categoryPath"`
+	Price              float64        `json:"price"`
+	PriceMinor         money.Minor    `json:"priceMinor"`
+	Currency           money.Currency `json:"currency"`
+	DiscountPercentage float64        `json:"discountPercentage"`
+	// Stock is what was available as of the last time the product was
+	// indexed. Only whether it is above 0 is kept current, read the product
+	// for the exact count.
+	Stock int32 `json:"stock"`
+	// Rating is the average of the published reviews, 0 without any
+	Rating      float64 `json:"rating"`
+	RatingCount int32   `json:"ratingCount"`
+	// Options collects the attribute values of the variants, {"size": ["M",
+	// "L"]}, for display
+	Options map[string][]string `json:"options,omitempty"`
+	// VariantOptions holds a key per combination of up to MaxSearchOptions
+	// attributes of each variant, "colour=red|size=m", so an option filter
+	// only matches when a single variant has all of the options
+	VariantOptions []string          `json:"variantOptions,omitempty"`
+	Variants       []VariantDocument `json:"variants,omitempty"`
+	// MyRating is never indexed, search results set it to the signed in
+	// user's rating of the product when they have reviewed it
+	MyRating int32 `json:"myRating,omitempty"`
+}
+
+type VariantDocument struct {
+	ID         int32             `json:"id"`
+	SKU        string            `json:"sku"`
+	Attributes map[string]string `json:"attributes"`
+	Price      float64           `json:"price"`
+	PriceMinor money.Minor       `json:"priceMinor"`
+	// Stock, like ProductDocument.Stock, is only kept current on whether it is
+	// above 0
+	Stock int32 `json:"stock"`
+}
+
+func NewProductDocument(p *repository.Product, variants []repository.ProductVariant, categoryPath []string) ProductDocument {
+	if len(categoryPath) == 0 {
+		categoryPath = []string{p.Category}
+	}
+
+	doc := ProductDocument{
+		ID:                 p.ID,
+		Title:              p.Title,
+		Description:        p.Description,
+		Category:           p.Category,
+		CategoryPath:       categoryPath,
+		Price:              ProductPrice(p).Float64(),
+		PriceMinor:         p.PriceMinor,
+		Currency:           p.Currency,
+		DiscountPercentage: p.DiscountBps.Percent(),
+		Stock:              ProductAvailable(p),
+		Rating:             ProductRating(p),
+		RatingCount:        p.RatingCount,
+	}
+	if len(variants) == 0 {
+		return doc
+	}
+
+	doc.Stock = 0
+	doc.Options = map[string][]string{}
+	for i := range variants {
+		v := &variants[i]
+		price := VariantPrice(p, v)
+		attrs := VariantAttributes(v)
+
+		doc.Variants = append(doc.Variants, VariantDocument{
+			ID:         v.ID,
+			SKU:        v.Sku,
+			Attributes: attrs,
+			Price:      price.Float64(),
+			PriceMinor: price.Amount,
+			Stock:      VariantAvailable(v),
+		})
+		doc.Stock += VariantAvailable(v)
+		if i == 0 || price.Amount < doc.PriceMinor {
+			doc.PriceMinor = price.Amount
+			doc.Price = price.Float64()
+		}
+		for k, val := range attrs {
+			if !slices.Contains(doc.Options[k], val) {
+				doc.Options[k] = append(doc.Options[k], val)
+			}
+		}
+		for _, key := range variantOptionKeys(attrs) {
+			if !slices.Contains(doc.VariantOptions, key) {
+				doc.VariantOptions = append(doc.VariantOptions, key)
+			}
+		}
+	}
+	return doc
+}
+
+// ProductPrice is the list price, before the discount
+func ProductPrice(p *repository.Product) money.Money {
+	return money.New(p.PriceMinor, p.Currency)
+}
+
+// ProductAvailable is the stock that can still be reserved. It is 0 rather
+// than negative when the stock on hand was set below what is reserved.
+func ProductAvailable(p *repository.Product) int32 {
+	return max(p.QuantityInStock-p.ReservedQuantity, 0)
+}
+
+// ProductSalePrice is the price with the discount applied
+func ProductSalePrice(p *repository.Product) money.Money {
+	return ProductPrice(p).ApplyDiscount(p.DiscountBps)
+}
+
+type CatalogService struct {
+	db      *pgxpool.Pool
+	queries *repository.Queries
+	index   meilisearch.IndexManager
+	// searchBreaker sends searches to the database while the index is down
+	searchBreaker *breaker
+	log           zerolog.Logger
+}
+
+func NewCatalogService(db *pgxpool.Pool, index meilisearch.IndexManager) *CatalogService {
+	return &CatalogService{
+		db:            db,
+		queries:       repository.New(db),
+		index:         index,
+		searchBreaker: newBreaker(searchBreakerFailures, searchBreakerCooldown),
+		log:           logger.GetLogger(),
+	}
+}
+
+// GetProduct returns the product whether or not it is archived
+func (s *CatalogService) GetProduct(ctx context.Context, id int32) (repository.Product, error) {
+	product, err := s.queries.GetProduct(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.Product{}, ErrProductNotFound
+		}
+		return repository.Product{}, err
+	}
+	return product, nil
+}
+
+// CreateProduct stores the product, the indexer picks it up from there
+func (s *CatalogService) CreateProduct(ctx context.Context, in ProductInput) (repository.Product, error) {
+	in.normalize()
+	if err := in.validate(); err != nil {
+		return repository.Product{}, err
+	}
+
+	product, err := s.queries.InsertProduct(ctx, repository.InsertProductParams{
+		Title:           in.Title,
+		Description:     in.Description,
+		Category:        in.Category,
+		PriceMinor:      in.Price.Amount,
+		Currency:        in.Price.Currency,
+		DiscountBps:     in.Discount,
+		QuantityInStock: in.QuantityInStock,
+	})
+	if err != nil {
+		if verr := unknownCategory(err); verr != nil {
+			return repository.Product{}, verr
+		}
+		s.log.Error().Err(err).Str("title", in.Title).Msg("failed to create product")
+		return repository.Product{}, err
+	}
+
+	s.log.Info().Int32("productId", product.ID).Msg("product created")
+
+	// the row has product_rows' columns, which is what Product is
+	return repository.Product(product), nil
+}
+
+// UpdateProduct replaces the product's fields
+func (s *CatalogService) UpdateProduct(ctx context.Context, id int32, in ProductInput) (repository.Product, error) {
+	in.normalize()
+	if err := in.validate(); err != nil {
+		return repository.Product{}, err
+	}
+
+	product, err := s.queries.UpdateProduct(ctx, repository.UpdateProductParams{
+		ID:              id,
+		Title:           in.Title,
+		Description:     in.Description,
+		Category:        in.Category,
+		PriceMinor:      in.Price.Amount,
+		Currency:        in.Price.Currency,
+		DiscountBps:     in.Discount,
+		QuantityInStock: in.QuantityInStock,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.Product{}, ErrProductNotFound
+		}
+		if verr := unknownCategory(err); verr != nil {
+			return repository.Product{}, verr
+		}
+		s.log.Error().Err(err).Int32("productId", id).Msg("failed to update product")
+		return repository.Product{}, err
+	}
+
+	return repository.Product(product), nil
+}
+
+// ArchiveProduct hides the product from search while keeping the row, so
+// past orders can still refer to it. Archiving twice is a no-op.
+func (s *CatalogService) ArchiveProduct(ctx context.Context, id int32) (repository.Product, error) {
+	product, err := s.queries.ArchiveProduct(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.Product{}, ErrProductNotFound
+		}
+		s.log.Error().Err(err).Int32("productId", id).Msg("failed to archive product")
+		return repository.Product{}, err
+	}
+
+	s.log.Info().Int32("productId", id).Msg("product archived")
+
+	return repository.Product(product), nil
+}
+
+func (s *CatalogService) withTx(ctx context.Context, fn func(q *repository.Queries) error) error {
+	return withTx(ctx, s.db, s.queries, fn)
+}
+
+func withTx(ctx context.Context, db *pgxpool.Pool, queries *repository.Queries, fn func(q *repository.Queries) error) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(queries.WithTx(tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
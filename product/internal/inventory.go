@@ -0,0 +1,395 @@
+package internal
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/lmnzx/slopify/pkg/logger"
+	"github.com/lmnzx/slopify/product/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+const (
+	DefaultReservationTTL = 15 * time.Minute
+	// MaxReservationTTL bounds how long a checkout can hold stock
+	MaxReservationTTL     = time.Hour
+	maxReservationItems   = 100
+	maxReferenceLength    = 128
+	reaperBatchSize       = 500
+	DefaultReaperInterval = 30 * time.Second
+)
+
+// the values of inventory_reservations.status
+const (
+	ReservationPending   = "pending"
+	ReservationCommitted = "committed"
+	ReservationReleased  = "released"
+	ReservationExpired   = "expired"
+)
+
+var (
+	ErrInsufficientStock    = errors.New("not enough stock")
+	ErrReservationNotFound  = errors.New("reservation not found")
+	ErrReservationExpired   = errors.New("reservation has expired")
+	ErrReservationReleased  = errors.New("reservation was released")
+	ErrReservationCommitted = errors.New("reservation was already committed")
+	ErrReferenceInUse       = errors.New("reference is already used by a reservation of other items")
+)
+
+type ReservationItemInput struct {
+	ProductID int32
+	// VariantID is required for products with variants and zero otherwise
+	VariantID int32
+	Quantity  int32
+}
+
+type ReserveInput struct {
+	// Reference is the caller's id for the checkout, reserving again with the
+	// same reference and items returns the existing reservation
+	Reference string
+	Items     []ReservationItemInput
+	// TTL is how long the stock is held, the service's default when zero
+	TTL time.Duration
+}
+
+// normalize merges items for the same product and variant and sorts them, so
+// concurrent reservations lock rows in the same order and can't deadlock
+func (in *ReserveInput) normalize() {
+	merged := make(map[[2]int32]int32, len(in.Items))
+	for _, item := range in.Items {
+		merged[[2]int32{item.ProductID, item.VariantID}] += item.Quantity
+	}
+
+	items := make([]ReservationItemInput, 0, len(merged))
+	for key, quantity := range merged {
+		items = append(items, ReservationItemInput{ProductID: key[0], VariantID: key[1], Quantity: quantity})
+	}
+	slices.SortFunc(items, func(a, b ReservationItemInput) int {
+		return cmp.Or(cmp.Compare(a.ProductID, b.ProductID), cmp.Compare(a.VariantID, b.VariantID))
+	})
+	in.Items = items
+}
+
+func (in *ReserveInput) validate() error {
+	if in.Reference == "" || len(in.Reference) > maxReferenceLength {
+		return &ProductValidationError{Field: "reference", Reason: fmt.Sprintf("must be between 1 and %d characters", maxReferenceLength)}
+	}
+	if len(in.Items) == 0 || len(in.Items) > maxReservationItems {
+		return &ProductValidationError{Field: "items", Reason: fmt.Sprintf("must have between 1 and %d entries", maxReservationItems)}
+	}
+	for _, item := range in.Items {
+		if item.ProductID <= 0 || item.VariantID < 0 {
+			return &ProductValidationError{Field: "items", Reason: "product and variant ids must be positive"}
+		}
+		if item.Quantity <= 0 {
+			return &ProductValidationError{Field: "items", Reason: "quantity must be positive"}
+		}
+	}
+	if in.TTL < 0 || in.TTL > MaxReservationTTL {
+		return &ProductValidationError{Field: "ttl", Reason: fmt.Sprintf("must be at most %s", MaxReservationTTL)}
+	}
+	return nil
+}
+
+// Reservation is a reservation along with the stock it holds
+type Reservation struct {
+	repository.InventoryReservation
+	Items []repository.InventoryReservationItem `json:"items"`
+}
+
+// InventoryService holds stock for checkouts. A reservation adds to the
+// reserved quantity of the product or variant through a conditional update of
+// its row, so two checkouts can't both hold the last unit. The stock on hand
+// only goes down once a reservation is committed, which leaves admins free to
+// set it at any time.
+type InventoryService struct {
+	db      *pgxpool.Pool
+	queries *repository.Queries
+	ttl     time.Duration
+	log     zerolog.Logger
+}
+
+func NewInventoryService(db *pgxpool.Pool, ttl time.Duration) *InventoryService {
+	if ttl <= 0 {
+		ttl = DefaultReservationTTL
+	}
+	return &InventoryService{
+		db:      db,
+		queries: repository.New(db),
+		ttl:     min(ttl, MaxReservationTTL),
+		log:     logger.GetLogger(),
+	}
+}
+
+// Reserve holds the stock of every item or none of them. It fails with
+// ErrInsufficientStock when an item is short, archived or doesn't exist.
+func (s *InventoryService) Reserve(ctx context.Context, in ReserveInput) (Reservation, error) {
+	in.normalize()
+	if err := in.validate(); err != nil {
+		return Reservation{}, err
+	}
+	ttl := in.TTL
+	if ttl == 0 {
+		ttl = s.ttl
+	}
+
+	var res Reservation
+	err := s.withTx(ctx, func(q *repository.Queries) error {
+		reservation, err := q.InsertReservation(ctx, repository.InsertReservationParams{
+			Reference: in.Reference,
+			ExpiresAt: time.Now().Add(ttl),
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			res, err = getReservationByReference(ctx, q, in)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		res.InventoryReservation = reservation
+
+		for _, item := range in.Items {
+			var reserved int64
+			var variantID pgtype.Int4
+			if item.VariantID != 0 {
+				variantID = pgtype.Int4{Int32: item.VariantID, Valid: true}
+				reserved, err = q.ReserveVariantStock(ctx, repository.ReserveVariantStockParams{
+					ID:        item.VariantID,
+					ProductID: item.ProductID,
+					Quantity:  item.Quantity,
+				})
+			} else {
+				reserved, err = q.ReserveProductStock(ctx, repository.ReserveProductStockParams{
+					ID:       item.ProductID,
+					Quantity: item.Quantity,
+				})
+			}
+			if err != nil {
+				return err
+			}
+			if reserved == 0 {
+				return fmt.Errorf("%w: product %d variant %d", ErrInsufficientStock, item.ProductID, item.VariantID)
+			}
+
+			row, err := q.InsertReservationItem(ctx, repository.InsertReservationItemParams{
+				ReservationID: reservation.ID,
+				ProductID:     item.ProductID,
+				VariantID:     variantID,
+				Quantity:      item.Quantity,
+			})
+			if err != nil {
+				return err
+			}
+			res.Items = append(res.Items, row)
+		}
+		return nil
+	})
+	if err != nil {
+		var validationErr *ProductValidationError
+		if !errors.As(err, &validationErr) && !errors.Is(err, ErrInsufficientStock) && !errors.Is(err, ErrReferenceInUse) {
+			s.log.Error().Err(err).Str("reference", in.Reference).Msg("failed to reserve stock")
+		}
+		return Reservation{}, err
+	}
+
+	s.log.Info().Str("reservationId", res.ID.String()).Str("reference", res.Reference).Msg("stock reserved")
+
+	return res, nil
+}
+
+// getReservationByReference returns the reservation a retried Reserve already
+// made, as long as it was for the same items
+func getReservationByReference(ctx context.Context, q *repository.Queries, in ReserveInput) (Reservation, error) {
+	reservation, err := q.GetReservationByReference(ctx, in.Reference)
+	if err != nil {
+		return Reservation{}, err
+	}
+	items, err := q.ListReservationItems(ctx, reservation.ID)
+	if err != nil {
+		return Reservation{}, err
+	}
+
+	same := len(items) == len(in.Items)
+	for i := 0; same && i < len(items); i++ {
+		same = items[i].ProductID == in.Items[i].ProductID &&
+			items[i].VariantID.Int32 == in.Items[i].VariantID &&
+			items[i].Quantity == in.Items[i].Quantity
+	}
+	if !same {
+		return Reservation{}, ErrReferenceInUse
+	}
+
+	return Reservation{InventoryReservation: reservation, Items: items}, nil
+}
+
+func (s *InventoryService) GetReservation(ctx context.Context, id uuid.UUID) (Reservation, error) {
+	reservation, err := s.queries.GetReservation(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Reservation{}, ErrReservationNotFound
+		}
+		return Reservation{}, err
+	}
+
+	items, err := s.queries.ListReservationItems(ctx, id)
+	if err != nil {
+		return Reservation{}, err
+	}
+
+	return Reservation{InventoryReservation: reservation, Items: items}, nil
+}
+
+// Commit takes the reserved stock off the stock on hand, once the order is
+// placed. Committing twice is a no-op.
+func (s *InventoryService) Commit(ctx context.Context, id uuid.UUID) (Reservation, error) {
+	var committed bool
+	err := s.withTx(ctx, func(q *repository.Queries) error {
+		_, err := q.CommitReservation(ctx, id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		committed = true
+		if err := q.ShipReservedProducts(ctx, id); err != nil {
+			return err
+		}
+		return q.ShipReservedVariants(ctx, id)
+	})
+	if err != nil {
+		s.log.Error().Err(err).Str("reservationId", id.String()).Msg("failed to commit reservation")
+		return Reservation{}, err
+	}
+
+	res, err := s.GetReservation(ctx, id)
+	if err != nil {
+		return Reservation{}, err
+	}
+	if !committed {
+		switch res.Status {
+		case ReservationCommitted:
+		case ReservationReleased:
+			return Reservation{}, ErrReservationReleased
+		default:
+			// past its expiry, the reaper may not have released the stock yet
+			return Reservation{}, ErrReservationExpired
+		}
+	}
+
+	s.log.Info().Str("reservationId", id.String()).Msg("reservation committed")
+
+	return res, nil
+}
+
+// Release stops holding the reserved stock, when the checkout is abandoned.
+// Releasing twice is a no-op.
+func (s *InventoryService) Release(ctx context.Context, id uuid.UUID) (Reservation, error) {
+	var released bool
+	err := s.withTx(ctx, func(q *repository.Queries) error {
+		_, err := q.ReleaseReservation(ctx, id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		released = true
+		return release(ctx, q, []uuid.UUID{id})
+	})
+	if err != nil {
+		s.log.Error().Err(err).Str("reservationId", id.String()).Msg("failed to release reservation")
+		return Reservation{}, err
+	}
+
+	res, err := s.GetReservation(ctx, id)
+	if err != nil {
+		return Reservation{}, err
+	}
+	if !released {
+		switch res.Status {
+		case ReservationReleased, ReservationExpired:
+		case ReservationCommitted:
+			return Reservation{}, ErrReservationCommitted
+		}
+	}
+
+	s.log.Info().Str("reservationId", id.String()).Msg("reservation released")
+
+	return res, nil
+}
+
+// ExpireReservations stops holding the stock of up to a batch of pending
+// reservations past their expiry and returns how many it expired
+func (s *InventoryService) ExpireReservations(ctx context.Context) (int, error) {
+	var expired int
+	err := s.withTx(ctx, func(q *repository.Queries) error {
+		ids, err := q.ExpireReservations(ctx, reaperBatchSize)
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		expired = len(ids)
+		return release(ctx, q, ids)
+	})
+	return expired, err
+}
+
+// StartReaper expires reservations every interval until ctx is cancelled
+func (s *InventoryService) StartReaper(ctx context.Context, interval time.Duration, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	if interval <= 0 {
+		interval = DefaultReaperInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s.log.Info().Dur("interval", interval).Msg("reservation reaper started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Info().Msg("reservation reaper stopped")
+			return
+		case <-ticker.C:
+		}
+
+		// a full batch means there may be more waiting
+		for {
+			n, err := s.ExpireReservations(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					s.log.Error().Err(err).Msg("failed to expire reservations")
+				}
+				break
+			}
+			if n > 0 {
+				s.log.Info().Int("expired", n).Msg("reservations expired")
+			}
+			if n < reaperBatchSize {
+				break
+			}
+		}
+	}
+}
+
+func release(ctx context.Context, q *repository.Queries, ids []uuid.UUID) error {
+	if err := q.ReleaseReservedProducts(ctx, ids); err != nil {
+		return err
+	}
+	return q.ReleaseReservedVariants(ctx, ids)
+}
+
+func (s *InventoryService) withTx(ctx context.Context, fn func(q *repository.Queries) error) error {
+	return withTx(ctx, s.db, s.queries, fn)
+}
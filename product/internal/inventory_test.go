@@ -0,0 +1,215 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lmnzx/slopify/pkg/money"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testDatabaseURLEnvVar names a database with the product migrations applied,
+// the tests that need one are skipped without it
+const testDatabaseURLEnvVar = "PRODUCT_TEST_DATABASE_URL"
+
+func testDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	url := os.Getenv(testDatabaseURLEnvVar)
+	if url == "" {
+		t.Skipf("%s is not set", testDatabaseURLEnvVar)
+	}
+
+	db, err := pgxpool.New(context.Background(), url)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(db.Close)
+	return db
+}
+
+func TestReserveConcurrent(t *testing.T) {
+	const (
+		stock    = 5
+		attempts = 40
+	)
+
+	db := testDB(t)
+	ctx := context.Background()
+	catalog := NewCatalogService(db, nil)
+	inventory := NewInventoryService(db, time.Minute)
+
+	run := fmt.Sprintf("inventory-test-%d", time.Now().UnixNano())
+	if _, err := catalog.CreateCategory(ctx, CategoryInput{Slug: run, Name: run}); err != nil {
+		t.Fatalf("CreateCategory: %v", err)
+	}
+
+	newProduct := func(t *testing.T, quantity int32) ProductInput {
+		t.Helper()
+		return ProductInput{
+			Title:           run + " " + t.Name(),
+			Category:        run,
+			Price:           money.New(1999, money.DefaultCurrency),
+			QuantityInStock: quantity,
+		}
+	}
+
+	tests := []struct {
+		name string
+		// setup creates what is reserved and returns its item along with a
+		// function reading its stock on hand and reserved quantity
+		setup func(t *testing.T) (ReservationItemInput, func() (int32, int32))
+		// edit sets the stock on hand the way an admin would
+		edit func(t *testing.T, item ReservationItemInput, quantity int32)
+	}{
+		{
+			name: "product",
+			setup: func(t *testing.T) (ReservationItemInput, func() (int32, int32)) {
+				p, err := catalog.CreateProduct(ctx, newProduct(t, stock))
+				if err != nil {
+					t.Fatalf("CreateProduct: %v", err)
+				}
+				return ReservationItemInput{ProductID: p.ID}, func() (int32, int32) {
+					p, err := catalog.GetProduct(ctx, p.ID)
+					if err != nil {
+						t.Fatalf("GetProduct: %v", err)
+					}
+					return p.QuantityInStock, p.ReservedQuantity
+				}
+			},
+			edit: func(t *testing.T, item ReservationItemInput, quantity int32) {
+				in := newProduct(t, quantity)
+				if _, err := catalog.UpdateProduct(ctx, item.ProductID, in); err != nil {
+					t.Fatalf("UpdateProduct: %v", err)
+				}
+			},
+		},
+		{
+			name: "variant",
+			setup: func(t *testing.T) (ReservationItemInput, func() (int32, int32)) {
+				p, err := catalog.CreateProduct(ctx, newProduct(t, 0))
+				if err != nil {
+					t.Fatalf("CreateProduct: %v", err)
+				}
+				v, err := catalog.CreateVariant(ctx, p.ID, VariantInput{
+					SKU:             run,
+					Attributes:      map[string]string{"size": "m"},
+					QuantityInStock: stock,
+				})
+				if err != nil {
+					t.Fatalf("CreateVariant: %v", err)
+				}
+				return ReservationItemInput{ProductID: p.ID, VariantID: v.ID}, func() (int32, int32) {
+					p, err := catalog.GetProductWithVariants(ctx, p.ID)
+					if err != nil {
+						t.Fatalf("GetProductWithVariants: %v", err)
+					}
+					if len(p.Variants) != 1 {
+						t.Fatalf("product has %d variants, want 1", len(p.Variants))
+					}
+					return p.Variants[0].QuantityInStock, p.Variants[0].ReservedQuantity
+				}
+			},
+			edit: func(t *testing.T, item ReservationItemInput, quantity int32) {
+				_, err := catalog.UpdateVariant(ctx, item.ProductID, item.VariantID, VariantInput{
+					SKU:             run,
+					Attributes:      map[string]string{"size": "m"},
+					QuantityInStock: quantity,
+				})
+				if err != nil {
+					t.Fatalf("UpdateVariant: %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, read := tt.setup(t)
+			item.Quantity = 1
+
+			check := func(step string, wantStock, wantReserved int32) {
+				t.Helper()
+				got, reserved := read()
+				if got < 0 || reserved < 0 {
+					t.Fatalf("%s: stock %d reserved %d, want neither below 0", step, got, reserved)
+				}
+				if got != wantStock || reserved != wantReserved {
+					t.Errorf("%s: stock %d reserved %d, want %d and %d", step, got, reserved, wantStock, wantReserved)
+				}
+			}
+
+			start := make(chan struct{})
+			var (
+				wg       sync.WaitGroup
+				mu       sync.Mutex
+				reserved []uuid.UUID
+			)
+			for i := range attempts {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					<-start
+
+					res, err := inventory.Reserve(ctx, ReserveInput{
+						Reference: fmt.Sprintf("%s-%s-%d", run, tt.name, i),
+						Items:     []ReservationItemInput{item},
+					})
+					if errors.Is(err, ErrInsufficientStock) {
+						return
+					}
+					if err != nil {
+						t.Errorf("Reserve: %v", err)
+						return
+					}
+					mu.Lock()
+					reserved = append(reserved, res.ID)
+					mu.Unlock()
+				}()
+			}
+			close(start)
+			wg.Wait()
+
+			if len(reserved) != stock {
+				t.Fatalf("%d of %d reservations succeeded, want %d", len(reserved), attempts, stock)
+			}
+			check("after reserving", stock, stock)
+
+			// setting the stock while it is held must not add the held
+			// stock on top once it is released
+			tt.edit(t, item, stock)
+			if _, err := inventory.Release(ctx, reserved[0]); err != nil {
+				t.Fatalf("Release: %v", err)
+			}
+			check("after releasing one", stock, stock-1)
+
+			for _, id := range reserved[1:] {
+				if _, err := inventory.Commit(ctx, id); err != nil {
+					t.Fatalf("Commit: %v", err)
+				}
+			}
+			check("after committing the rest", 1, 0)
+
+			// stock set below what is held stops at 0 when it ships
+			res, err := inventory.Reserve(ctx, ReserveInput{
+				Reference: fmt.Sprintf("%s-%s-last", run, tt.name),
+				Items:     []ReservationItemInput{item},
+			})
+			if err != nil {
+				t.Fatalf("Reserve: %v", err)
+			}
+			tt.edit(t, item, 0)
+			if _, err := inventory.Commit(ctx, res.ID); err != nil {
+				t.Fatalf("Commit: %v", err)
+			}
+			check("after shipping more than is on hand", 0, 0)
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/lmnzx/slopify/pkg/logger"
+	"github.com/lmnzx/slopify/product/repository"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/meilisearch/meilisearch-go"
+)
+
+// ProductIndex is the uid search is served from
+const ProductIndex = "products"
+
+const reindexBatchSize = 1000
+
+type ReindexResult struct {
+	// PreviousIndex holds the documents that were live before the swap,
+	// swapping it back with ProductIndex rolls the reindex back
+	PreviousIndex string
+	Documents     int64
+}
+
+// Reindex builds a new index from every live product and swaps it with
+// ProductIndex once it is complete. It holds the indexer's lock for the whole
+// run, so changes made meanwhile stay queued and are applied to the new index
+// after the swap rather than to the old one. The search settings lock is held
+// as long, for the same reason.
+func Reindex(ctx context.Context, db *pgxpool.Pool, client meilisearch.ServiceManager) (result *ReindexResult, err error) {
+	log := logger.GetLogger()
+
+	uid := ProductIndex + "_" + time.Now().UTC().Format("20060102150405")
+	staging := NewCatalogService(db, client.Index(uid))
+
+	task, err := client.CreateIndexWithContext(ctx, &meilisearch.IndexConfig{Uid: uid, PrimaryKey: "id"})
+	if err != nil {
+		return nil, err
+	}
+	// once it exists a failed run must not leave the staging index behind,
+	// unless the swap was submitted and uid may now hold the previous live
+	// documents, which are the only way to roll back
+	var swapped bool
+	defer func() {
+		if err != nil && !swapped {
+			dropIndex(ctx, staging, client, uid)
+		}
+	}()
+	if err := staging.waitForTask(ctx, task.TaskUID); err != nil {
+		return nil, err
+	}
+	log.Info().Str("index", uid).Msg("reindex started")
+
+	// settings go first, applying them to a full index means indexing twice
+	if err := staging.ConfigureIndex(ctx); err != nil {
+		return nil, err
+	}
+
+	var total int64
+	err = staging.withTx(ctx, func(q *repository.Queries) error {
+		// held until the swap, settings applied meanwhile would go to the
+		// index that is about to be swapped out
+		if err := q.LockSearchSettings(ctx); err != nil {
+			return err
+		}
+		if _, err := staging.applyActiveSearchSettings(ctx, q); err != nil {
+			return err
+		}
+
+		if err := q.LockProductIndexQueue(ctx); err != nil {
+			return err
+		}
+
+		paths, err := categoryPaths(ctx, q)
+		if err != nil {
+			return err
+		}
+
+		var after int32
+		for {
+			products, err := q.ListProducts(ctx, repository.ListProductsParams{
+				After:    after,
+				PageSize: reindexBatchSize,
+			})
+			if err != nil {
+				return err
+			}
+			if len(products) == 0 {
+				break
+			}
+
+			ids := make([]int32, 0, len(products))
+			for _, p := range products {
+				ids = append(ids, p.ID)
+			}
+			variantRows, err := q.ListVariantsByProductIds(ctx, ids)
+			if err != nil {
+				return err
+			}
+			variants := groupVariants(variantRows)
+
+			docs := make([]ProductDocument, 0, len(products))
+			for i := range products {
+				docs = append(docs, NewProductDocument(&products[i], variants[products[i].ID], paths[products[i].Category]))
+			}
+			task, err := staging.index.AddDocumentsWithContext(ctx, docs, "id")
+			if err != nil {
+				return err
+			}
+			if err := staging.waitForTask(ctx, task.TaskUID); err != nil {
+				return err
+			}
+
+			total += int64(len(docs))
+			after = products[len(products)-1].ID
+			log.Info().Str("index", uid).Int64("documents", total).Msg("reindex batch added")
+		}
+
+		stats, err := staging.index.GetStatsWithContext(ctx)
+		if err != nil {
+			return err
+		}
+		if stats.NumberOfDocuments != total {
+			return fmt.Errorf("index %s has %d documents, expected %d", uid, stats.NumberOfDocuments, total)
+		}
+
+		if err := ensureIndex(ctx, staging, client, ProductIndex); err != nil {
+			return err
+		}
+
+		task, err := client.SwapIndexesWithContext(ctx, []*meilisearch.SwapIndexesParams{
+			{Indexes: []string{ProductIndex, uid}},
+		})
+		if err != nil {
+			return err
+		}
+		swapped = true
+		return staging.waitForTask(ctx, task.TaskUID)
+	})
+	if err != nil {
+		if swapped {
+			log.Error().Err(err).Str("index", uid).Msg("reindex failed after the swap was submitted, check which index is live before deleting it")
+			return nil, err
+		}
+		log.Error().Err(err).Str("index", uid).Msg("reindex failed, the live index was not touched")
+		return nil, err
+	}
+
+	log.Info().Str("previousIndex", uid).Int64("documents", total).Msg("reindex swapped in")
+
+	return &ReindexResult{PreviousIndex: uid, Documents: total}, nil
+}
+
+// dropIndex deletes the staging index of a failed reindex. It outlives ctx so
+// a cancelled run cleans up too.
+func dropIndex(ctx context.Context, s *CatalogService, client meilisearch.ServiceManager, uid string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), indexTaskTimeout)
+	defer cancel()
+
+	task, err := client.DeleteIndexWithContext(ctx, uid)
+	if err == nil {
+		err = s.waitForTask(ctx, task.TaskUID)
+	}
+	if err != nil {
+		s.log.Error().Err(err).Str("index", uid).Msg("failed to delete the staging index, delete it by hand")
+		return
+	}
+	s.log.Info().Str("index", uid).Msg("staging index deleted")
+}
+
+// ensureIndex creates uid if it does not exist yet, a swap needs both sides
+func ensureIndex(ctx context.Context, s *CatalogService, client meilisearch.ServiceManager, uid string) error {
+	_, err := client.GetIndexWithContext(ctx, uid)
+	if err == nil {
+		return nil
+	}
+
+	var apiErr *meilisearch.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		return err
+	}
+
+	task, err := client.CreateIndexWithContext(ctx, &meilisearch.IndexConfig{Uid: uid, PrimaryKey: "id"})
+	if err != nil {
+		return err
+	}
+	return s.waitForTask(ctx, task.TaskUID)
+}
@@ -0,0 +1,285 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lmnzx/slopify/product/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	maxReviewTitleLength  = 200
+	maxReviewBodyLength   = 5000
+	DefaultReviewPageSize = 20
+	MaxReviewPageSize     = 100
+)
+
+// the values of product_reviews.status, only published reviews are listed
+// publicly and count towards the rating
+const (
+	ReviewPending   = "pending"
+	ReviewPublished = "published"
+	ReviewRejected  = "rejected"
+)
+
+// the sort orders of ListReviews
+var reviewSorts = []string{"newest", "oldest", "rating_desc", "rating_asc"}
+
+var (
+	ErrReviewNotFound      = errors.New("review not found")
+	ErrReviewExists        = errors.New("you have already reviewed this product")
+	ErrInvalidReviewSort   = errors.New("sort must be one of newest, oldest, rating_desc, rating_asc")
+	ErrInvalidReviewStatus = errors.New("status must be one of pending, published, rejected")
+)
+
+type ReviewInput struct {
+	// Rating is from 1 to 5 stars
+	Rating int32
+	Title  string
+	Body   string
+}
+
+func (in *ReviewInput) normalize() {
+	in.Title = strings.TrimSpace(in.Title)
+	in.Body = strings.TrimSpace(in.Body)
+}
+
+func (in *ReviewInput) validate() error {
+	if in.Rating < 1 || in.Rating > 5 {
+		return &ProductValidationError{Field: "rating", Reason: "must be between 1 and 5"}
+	}
+	if len(in.Title) > maxReviewTitleLength {
+		return &ProductValidationError{Field: "title", Reason: fmt.Sprintf("must be at most %d characters", maxReviewTitleLength)}
+	}
+	if len(in.Body) > maxReviewBodyLength {
+		return &ProductValidationError{Field: "body", Reason: fmt.Sprintf("must be at most %d characters", maxReviewBodyLength)}
+	}
+	return nil
+}
+
+type ReviewFilter struct {
+	// ProductID limits the reviews to one product when set
+	ProductID int32
+	// Status limits the reviews to one status when set
+	Status string
+	// Sort is newest, oldest, rating_desc or rating_asc, newest when empty
+	Sort string
+	// Cursor is the offset the previous page returned
+	Cursor   string
+	PageSize int
+}
+
+// ProductRating is the average of the product's published reviews, 0 when it
+// has none
+func ProductRating(p *repository.Product) float64 {
+	if p.RatingCount == 0 {
+		return 0
+	}
+	// two decimals is as precise as a star rating gets shown
+	return math.Round(float64(p.RatingSum)/float64(p.RatingCount)*100) / 100
+}
+
+func (s *CatalogService) CreateReview(ctx context.Context, productID int32, userID uuid.UUID, in ReviewInput) (repository.ProductReview, error) {
+	in.normalize()
+	if err := in.validate(); err != nil {
+		return repository.ProductReview{}, err
+	}
+
+	review, err := s.queries.InsertReview(ctx, repository.InsertReviewParams{
+		ProductID: productID,
+		UserID:    userID,
+		Rating:    in.Rating,
+		Title:     in.Title,
+		Body:      in.Body,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.ProductReview{}, ErrProductNotFound
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return repository.ProductReview{}, ErrReviewExists
+		}
+		s.log.Error().Err(err).Int32("productId", productID).Msg("failed to create review")
+		return repository.ProductReview{}, err
+	}
+
+	s.log.Info().Int32("productId", productID).Int32("reviewId", review.ID).Msg("review created")
+
+	return review, nil
+}
+
+// UpdateReview replaces the user's review of the product
+func (s *CatalogService) UpdateReview(ctx context.Context, productID int32, userID uuid.UUID, in ReviewInput) (repository.ProductReview, error) {
+	in.normalize()
+	if err := in.validate(); err != nil {
+		return repository.ProductReview{}, err
+	}
+
+	review, err := s.queries.UpdateReview(ctx, repository.UpdateReviewParams{
+		ProductID: productID,
+		UserID:    userID,
+		Rating:    in.Rating,
+		Title:     in.Title,
+		Body:      in.Body,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.ProductReview{}, ErrReviewNotFound
+		}
+		s.log.Error().Err(err).Int32("productId", productID).Msg("failed to update review")
+		return repository.ProductReview{}, err
+	}
+
+	return review, nil
+}
+
+// DeleteReview removes the user's review of the product
+func (s *CatalogService) DeleteReview(ctx context.Context, productID int32, userID uuid.UUID) error {
+	_, err := s.queries.DeleteReview(ctx, repository.DeleteReviewParams{
+		ProductID: productID,
+		UserID:    userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrReviewNotFound
+		}
+		s.log.Error().Err(err).Int32("productId", productID).Msg("failed to delete review")
+		return err
+	}
+
+	return nil
+}
+
+// GetUserReview returns the user's review of the product whatever its status
+func (s *CatalogService) GetUserReview(ctx context.Context, productID int32, userID uuid.UUID) (repository.ProductReview, error) {
+	review, err := s.queries.GetUserReview(ctx, repository.GetUserReviewParams{
+		ProductID: productID,
+		UserID:    userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.ProductReview{}, ErrReviewNotFound
+		}
+		return repository.ProductReview{}, err
+	}
+	return review, nil
+}
+
+// UserRatings returns the user's rating of each of the products they have
+// reviewed
+func (s *CatalogService) UserRatings(ctx context.Context, userID uuid.UUID, productIDs []int32) (map[int32]int32, error) {
+	if len(productIDs) == 0 {
+		return map[int32]int32{}, nil
+	}
+
+	rows, err := s.queries.ListUserReviewRatings(ctx, repository.ListUserReviewRatingsParams{
+		UserID:     userID,
+		ProductIds: productIDs,
+	})
+	if err != nil {
+		s.log.Error().Err(err).Str("userId", userID.String()).Msg("failed to get user ratings")
+		return nil, err
+	}
+
+	ratings := make(map[int32]int32, len(rows))
+	for _, r := range rows {
+		ratings[r.ProductID] = r.Rating
+	}
+	return ratings, nil
+}
+
+// ListReviews pages through reviews, the returned cursor is empty on the last
+// page
+func (s *CatalogService) ListReviews(ctx context.Context, filter ReviewFilter) ([]repository.ProductReview, string, error) {
+	pageSize := filter.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultReviewPageSize
+	}
+	if pageSize > MaxReviewPageSize {
+		pageSize = MaxReviewPageSize
+	}
+
+	params := repository.ListReviewsParams{
+		Sort: filter.Sort,
+		// one extra row tells us whether there is another page
+		PageSize: int32(pageSize + 1),
+	}
+	if params.Sort == "" {
+		params.Sort = reviewSorts[0]
+	}
+	if !slices.Contains(reviewSorts, params.Sort) {
+		return nil, "", ErrInvalidReviewSort
+	}
+	if filter.Status != "" {
+		if !validReviewStatus(filter.Status) {
+			return nil, "", ErrInvalidReviewStatus
+		}
+		params.Status = pgtype.Text{String: filter.Status, Valid: true}
+	}
+	if filter.ProductID != 0 {
+		params.ProductID = pgtype.Int4{Int32: filter.ProductID, Valid: true}
+	}
+	if filter.Cursor != "" {
+		offset, err := strconv.ParseInt(filter.Cursor, 10, 32)
+		if err != nil || offset < 0 {
+			return nil, "", ErrInvalidCursor
+		}
+		params.PageOffset = int32(offset)
+	}
+
+	reviews, err := s.queries.ListReviews(ctx, params)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to list reviews")
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if len(reviews) > pageSize {
+		reviews = reviews[:pageSize]
+		nextCursor = strconv.Itoa(int(params.PageOffset) + pageSize)
+	}
+	if reviews == nil {
+		reviews = []repository.ProductReview{}
+	}
+
+	return reviews, nextCursor, nil
+}
+
+// SetReviewStatus is how admins moderate reviews, the product's rating follows
+// the change
+func (s *CatalogService) SetReviewStatus(ctx context.Context, reviewID int32, status string) (repository.ProductReview, error) {
+	if !validReviewStatus(status) {
+		return repository.ProductReview{}, ErrInvalidReviewStatus
+	}
+
+	review, err := s.queries.SetReviewStatus(ctx, repository.SetReviewStatusParams{
+		ID:     reviewID,
+		Status: status,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.ProductReview{}, ErrReviewNotFound
+		}
+		s.log.Error().Err(err).Int32("reviewId", reviewID).Msg("failed to set review status")
+		return repository.ProductReview{}, err
+	}
+
+	s.log.Info().Int32("reviewId", reviewID).Str("status", status).Msg("review moderated")
+
+	return review, nil
+}
+
+func validReviewStatus(status string) bool {
+	return status == ReviewPending || status == ReviewPublished || status == ReviewRejected
+}
@@ -0,0 +1,280 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/meilisearch/meilisearch-go"
+)
+
+const MaxHitsPerPage = 100
+
+// MaxSearchOptions bounds the option filters of a search, each variant is
+// indexed under every combination of up to this many of its attributes
+const MaxSearchOptions = 3
+
+var (
+	ErrInvalidSort    = errors.New("sort must be one of price:asc, price:desc, discount:asc, discount:desc, rating:asc, rating:desc")
+	ErrInvalidOption  = errors.New("option names must be lowercase letters, digits and underscores")
+	ErrTooManyOptions = fmt.Errorf("at most %d options can be filtered on", MaxSearchOptions)
+)
+
+// index attributes, these are the ProductDocument json names
+const (
+	attrCategory       = "category"
+	attrCategoryPath   = "categoryPath"
+	attrPrice          = "price"
+	attrDiscount       = "discountPercentage"
+	attrStock          = "stock"
+	attrVariantOptions = "variantOptions"
+	attrRating         = "rating"
+)
+
+var (
+	filterableAttributes = []string{attrCategory, attrCategoryPath, attrPrice, attrDiscount, attrStock, attrVariantOptions, attrRating}
+	sortableAttributes   = []string{attrPrice, attrDiscount, attrRating}
+	// the public sort keys mapped to index attributes
+	sortKeys = map[string]string{
+		"price":    attrPrice,
+		"discount": attrDiscount,
+		"rating":   attrRating,
+	}
+)
+
+// ConfigureIndex applies the filterable and sortable attributes search relies
+// on and waits until the index has picked them up
+func (s *CatalogService) ConfigureIndex(ctx context.Context) error {
+	task, err := s.index.UpdateSettingsWithContext(ctx, &meilisearch.Settings{
+		FilterableAttributes: filterableAttributes,
+		SortableAttributes:   sortableAttributes,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.waitForTask(ctx, task.TaskUID)
+}
+
+func (s *CatalogService) waitForTask(ctx context.Context, taskUID int64) error {
+	done, err := s.index.WaitForTaskWithContext(ctx, taskUID, 100*time.Millisecond)
+	if err != nil {
+		return err
+	}
+	if done.Status != meilisearch.TaskStatusSucceeded {
+		return fmt.Errorf("meilisearch task %d %s: %s", taskUID, done.Status, done.Error.Message)
+	}
+	return nil
+}
+
+type SearchParams struct {
+	// Query may be empty to browse, it then matches every product
+	Query string
+	// Categories matches products in any of the categories or their
+	// descendants
+	Categories  []string
+	MinPrice    *float32
+	MaxPrice    *float32
+	MinDiscount *float32
+	// MinRating matches products whose average rating is at least this
+	MinRating *float32
+	InStock   bool
+	// Options matches products with a variant that has all of the options,
+	// {"size": "M", "colour": "red"}, at most MaxSearchOptions of them
+	Options map[string]string
+	// Sort is "<price|discount|rating>:<asc|desc>", empty keeps relevance order
+	Sort string
+
+	// Page and HitsPerPage select page based pagination, which gives an exact
+	// total. Without Page, Offset and Limit are used and the total is an
+	// estimate.
+	Page        int
+	HitsPerPage int
+	Offset      int
+	Limit       int
+}
+
+type FacetStats struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+type SearchResult struct {
+	Hits []ProductDocument `json:"hits"`
+	// TotalHits is exact with page based pagination and an estimate otherwise
+	TotalHits   int64 `json:"total_hits"`
+	Page        int64 `json:"page,omitempty"`
+	TotalPages  int64 `json:"total_pages,omitempty"`
+	HitsPerPage int64 `json:"hits_per_page,omitempty"`
+	Offset      int64 `json:"offset"`
+	Limit       int64 `json:"limit,omitempty"`
+	// Categories is the number of matching products per category
+	Categories map[string]int64 `json:"categories"`
+	Price      *FacetStats      `json:"price,omitempty"`
+	Discount   *FacetStats      `json:"discount,omitempty"`
+	// Degraded is set when the index was unavailable and the database
+	// answered instead, matching and ranking are cruder then
+	Degraded bool `json:"degraded,omitempty"`
+	// SearchID is set when the search was recorded for analytics, clicks on
+	// its hits are tracked against it
+	SearchID string `json:"search_id,omitempty"`
+}
+
+func (p *SearchParams) request() (*meilisearch.SearchRequest, error) {
+	req := &meilisearch.SearchRequest{
+		Filter: p.filter(),
+		Facets: []string{attrCategory, attrPrice, attrDiscount},
+	}
+
+	for name := range p.Options {
+		if !attributeNamePattern.MatchString(name) {
+			return nil, ErrInvalidOption
+		}
+	}
+	if len(p.Options) > MaxSearchOptions {
+		return nil, ErrTooManyOptions
+	}
+
+	if p.Sort != "" {
+		key, dir, ok := strings.Cut(p.Sort, ":")
+		attr, known := sortKeys[key]
+		if !ok || !known || (dir != "asc" && dir != "desc") {
+			return nil, ErrInvalidSort
+		}
+		req.Sort = []string{attr + ":" + dir}
+	}
+
+	if p.Page > 0 {
+		req.Page = int64(p.Page)
+		req.HitsPerPage = int64(clamp(p.HitsPerPage, DefaultSearchLimit, MaxHitsPerPage))
+	} else {
+		req.Offset = int64(max(p.Offset, 0))
+		req.Limit = int64(clamp(p.Limit, DefaultSearchLimit, MaxSearchLimit))
+	}
+
+	return req, nil
+}
+
+// filter builds the conditions as a list, which Meilisearch ANDs together
+func (p *SearchParams) filter() []string {
+	var conds []string
+
+	if len(p.Categories) > 0 {
+		quoted := make([]string, 0, len(p.Categories))
+		for _, c := range p.Categories {
+			quoted = append(quoted, quoteFilterValue(strings.ToLower(strings.TrimSpace(c))))
+		}
+		conds = append(conds, attrCategoryPath+" IN ["+strings.Join(quoted, ", ")+"]")
+	}
+	if p.MinPrice != nil {
+		conds = append(conds, attrPrice+" >= "+formatFloat(*p.MinPrice))
+	}
+	if p.MaxPrice != nil {
+		conds = append(conds, attrPrice+" <= "+formatFloat(*p.MaxPrice))
+	}
+	if p.MinDiscount != nil {
+		conds = append(conds, attrDiscount+" >= "+formatFloat(*p.MinDiscount))
+	}
+	if p.MinRating != nil {
+		conds = append(conds, attrRating+" >= "+formatFloat(*p.MinRating))
+	}
+	if p.InStock {
+		conds = append(conds, attrStock+" > 0")
+	}
+	if len(p.Options) > 0 {
+		conds = append(conds, attrVariantOptions+" = "+quoteFilterValue(optionKey(p.Options)))
+	}
+
+	return conds
+}
+
+// Search queries the index directly and returns the indexed documents along
+// with facet counts for the matching set. While the index is failing it
+// searches the database instead and marks the result as degraded.
+func (s *CatalogService) Search(ctx context.Context, params SearchParams) (*SearchResult, error) {
+	req, err := params.request()
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.searchBreaker.allow() {
+		return s.fullTextSearch(ctx, params, req)
+	}
+
+	raw, err := s.index.SearchRawWithContext(ctx, params.Query, req)
+	if err != nil {
+		if ctx.Err() != nil {
+			s.searchBreaker.abandon()
+			return nil, err
+		}
+		s.log.Error().Err(err).Str("query", params.Query).Msg("search failed, falling back to the database")
+		if s.searchBreaker.failure() {
+			s.log.Warn().Dur("cooldown", searchBreakerCooldown).Msg("search index unavailable, searching the database")
+		}
+		return s.fullTextSearch(ctx, params, req)
+	}
+	s.searchBreaker.success()
+
+	var res struct {
+		Hits               []ProductDocument           `json:"hits"`
+		EstimatedTotalHits int64                       `json:"estimatedTotalHits"`
+		TotalHits          int64                       `json:"totalHits"`
+		Page               int64                       `json:"page"`
+		TotalPages         int64                       `json:"totalPages"`
+		HitsPerPage        int64                       `json:"hitsPerPage"`
+		Offset             int64                       `json:"offset"`
+		Limit              int64                       `json:"limit"`
+		FacetDistribution  map[string]map[string]int64 `json:"facetDistribution"`
+		FacetStats         map[string]FacetStats       `json:"facetStats"`
+	}
+	if err := json.Unmarshal(*raw, &res); err != nil {
+		return nil, err
+	}
+
+	result := &SearchResult{
+		Hits:        res.Hits,
+		TotalHits:   res.EstimatedTotalHits,
+		Page:        res.Page,
+		TotalPages:  res.TotalPages,
+		HitsPerPage: res.HitsPerPage,
+		Offset:      res.Offset,
+		Limit:       res.Limit,
+		Categories:  res.FacetDistribution[attrCategory],
+	}
+	if req.Page > 0 {
+		result.TotalHits = res.TotalHits
+	}
+	if result.Hits == nil {
+		result.Hits = []ProductDocument{}
+	}
+	if result.Categories == nil {
+		result.Categories = map[string]int64{}
+	}
+	if stats, ok := res.FacetStats[attrPrice]; ok {
+		result.Price = &stats
+	}
+	if stats, ok := res.FacetStats[attrDiscount]; ok {
+		result.Discount = &stats
+	}
+
+	return result, nil
+}
+
+func quoteFilterValue(v string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v) + `"`
+}
+
+func formatFloat(f float32) string {
+	return strconv.FormatFloat(float64(f), 'f', -1, 32)
+}
+
+func clamp(v, fallback, limit int) int {
+	if v <= 0 {
+		return fallback
+	}
+	return min(v, limit)
+}
@@ -0,0 +1,503 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lmnzx/slopify/product/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/meilisearch/meilisearch-go"
+)
+
+const (
+	maxSynonyms           = 1000
+	maxSynonymsPerWord    = 20
+	maxStopWords          = 1000
+	maxSettingsWordLength = 64
+	maxRankingRules       = 20
+	maxSettingsNoteLength = 500
+	// searchSettingsListSize is how many of the latest versions are listed
+	searchSettingsListSize = 50
+)
+
+// the names of the settings, as they appear in a diff
+const (
+	settingSynonyms             = "synonyms"
+	settingStopWords            = "stop_words"
+	settingRankingRules         = "ranking_rules"
+	settingSearchableAttributes = "searchable_attributes"
+)
+
+var ErrSearchSettingsNotFound = errors.New("search settings version not found")
+
+var (
+	// the ranking rules Meilisearch starts with, in its order
+	builtinRankingRules = []string{"words", "typo", "proximity", "attribute", "sort", "exactness"}
+	// custom ranking rules sort on a document field, price:asc
+	customRankingRulePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_.]*:(asc|desc)$`)
+	// the ProductDocument fields worth searching, "*" searches every field
+	searchableFields = []string{"title", "description", "category", "categoryPath", "options", "variants.sku", "variants.attributes"}
+)
+
+// SearchSettings are the relevance settings of the products index. An empty
+// setting means Meilisearch's default.
+type SearchSettings struct {
+	// Synonyms maps a word to the words that should match it, {"tee":
+	// ["t-shirt"]}. They are one way, list both words to make them mutual.
+	Synonyms  map[string][]string `json:"synonyms,omitempty"`
+	StopWords []string            `json:"stop_words,omitempty"`
+	// RankingRules are built in rules and field:asc|desc custom ones, in
+	// order of precedence
+	RankingRules []string `json:"ranking_rules,omitempty"`
+	// SearchableAttributes are the fields searched, in order of importance
+	SearchableAttributes []string `json:"searchable_attributes,omitempty"`
+}
+
+// normalize lowercases and sorts the words the way the index stores them, so
+// settings read back from it compare equal
+func (s *SearchSettings) normalize() {
+	synonyms := make(map[string][]string, len(s.Synonyms))
+	for word, matches := range s.Synonyms {
+		word = normalizeSettingsWord(word)
+		if word == "" {
+			continue
+		}
+		for _, m := range matches {
+			if m = normalizeSettingsWord(m); m != "" && m != word {
+				synonyms[word] = append(synonyms[word], m)
+			}
+		}
+		if len(synonyms[word]) > 0 {
+			slices.Sort(synonyms[word])
+			synonyms[word] = slices.Compact(synonyms[word])
+		}
+	}
+	s.Synonyms = synonyms
+
+	stopWords := make([]string, 0, len(s.StopWords))
+	for _, w := range s.StopWords {
+		if w = normalizeSettingsWord(w); w != "" {
+			stopWords = append(stopWords, w)
+		}
+	}
+	slices.Sort(stopWords)
+	s.StopWords = slices.Compact(stopWords)
+
+	for i := range s.RankingRules {
+		s.RankingRules[i] = strings.TrimSpace(s.RankingRules[i])
+	}
+	for i := range s.SearchableAttributes {
+		s.SearchableAttributes[i] = strings.TrimSpace(s.SearchableAttributes[i])
+	}
+}
+
+func normalizeSettingsWord(w string) string {
+	return strings.Join(strings.Fields(strings.ToLower(w)), " ")
+}
+
+func (s *SearchSettings) validate() error {
+	if len(s.Synonyms) > maxSynonyms {
+		return &ProductValidationError{Field: settingSynonyms, Reason: fmt.Sprintf("must have at most %d words", maxSynonyms)}
+	}
+	for word, matches := range s.Synonyms {
+		if len(word) > maxSettingsWordLength {
+			return &ProductValidationError{Field: settingSynonyms, Reason: fmt.Sprintf("words must be at most %d characters", maxSettingsWordLength)}
+		}
+		if len(matches) > maxSynonymsPerWord {
+			return &ProductValidationError{Field: settingSynonyms, Reason: fmt.Sprintf("a word can have at most %d synonyms", maxSynonymsPerWord)}
+		}
+		for _, m := range matches {
+			if len(m) > maxSettingsWordLength {
+				return &ProductValidationError{Field: settingSynonyms, Reason: fmt.Sprintf("words must be at most %d characters", maxSettingsWordLength)}
+			}
+		}
+	}
+
+	if len(s.StopWords) > maxStopWords {
+		return &ProductValidationError{Field: settingStopWords, Reason: fmt.Sprintf("must have at most %d words", maxStopWords)}
+	}
+	for _, w := range s.StopWords {
+		if len(w) > maxSettingsWordLength || strings.Contains(w, " ") {
+			return &ProductValidationError{Field: settingStopWords, Reason: fmt.Sprintf("must be single words of at most %d characters", maxSettingsWordLength)}
+		}
+	}
+
+	if len(s.RankingRules) > maxRankingRules {
+		return &ProductValidationError{Field: settingRankingRules, Reason: fmt.Sprintf("must have at most %d rules", maxRankingRules)}
+	}
+	for i, rule := range s.RankingRules {
+		if !slices.Contains(builtinRankingRules, rule) && !customRankingRulePattern.MatchString(rule) {
+			return &ProductValidationError{Field: settingRankingRules, Reason: fmt.Sprintf("%q must be one of %s or field:asc|desc", rule, strings.Join(builtinRankingRules, ", "))}
+		}
+		if slices.Contains(s.RankingRules[:i], rule) {
+			return &ProductValidationError{Field: settingRankingRules, Reason: fmt.Sprintf("%q is listed twice", rule)}
+		}
+	}
+
+	for i, attr := range s.SearchableAttributes {
+		if attr == "*" && len(s.SearchableAttributes) == 1 {
+			continue
+		}
+		if !slices.Contains(searchableFields, attr) {
+			return &ProductValidationError{Field: settingSearchableAttributes, Reason: fmt.Sprintf("%q must be \"*\" on its own or one of %s", attr, strings.Join(searchableFields, ", "))}
+		}
+		if slices.Contains(s.SearchableAttributes[:i], attr) {
+			return &ProductValidationError{Field: settingSearchableAttributes, Reason: fmt.Sprintf("%q is listed twice", attr)}
+		}
+	}
+
+	return nil
+}
+
+// withDefaults spells out the defaults empty settings stand for, which is
+// also how the index reports them
+func (s SearchSettings) withDefaults() SearchSettings {
+	if s.Synonyms == nil {
+		s.Synonyms = map[string][]string{}
+	}
+	if s.StopWords == nil {
+		s.StopWords = []string{}
+	}
+	if len(s.RankingRules) == 0 {
+		s.RankingRules = builtinRankingRules
+	}
+	if len(s.SearchableAttributes) == 0 {
+		s.SearchableAttributes = []string{"*"}
+	}
+	return s
+}
+
+// SettingsChange is a setting that differs between the index and a version
+type SettingsChange struct {
+	Setting string `json:"setting"`
+	From    any    `json:"from"`
+	To      any    `json:"to"`
+}
+
+// diffSettings lists the settings to change to go from current to desired
+func diffSettings(current, desired SearchSettings) []SettingsChange {
+	current, desired = current.withDefaults(), desired.withDefaults()
+
+	changes := []SettingsChange{}
+	if !maps.EqualFunc(current.Synonyms, desired.Synonyms, slices.Equal) {
+		changes = append(changes, SettingsChange{Setting: settingSynonyms, From: current.Synonyms, To: desired.Synonyms})
+	}
+	if !slices.Equal(current.StopWords, desired.StopWords) {
+		changes = append(changes, SettingsChange{Setting: settingStopWords, From: current.StopWords, To: desired.StopWords})
+	}
+	if !slices.Equal(current.RankingRules, desired.RankingRules) {
+		changes = append(changes, SettingsChange{Setting: settingRankingRules, From: current.RankingRules, To: desired.RankingRules})
+	}
+	if !slices.Equal(current.SearchableAttributes, desired.SearchableAttributes) {
+		changes = append(changes, SettingsChange{Setting: settingSearchableAttributes, From: current.SearchableAttributes, To: desired.SearchableAttributes})
+	}
+	return changes
+}
+
+type SearchSettingsVersion struct {
+	Version   int32          `json:"version"`
+	Settings  SearchSettings `json:"settings"`
+	Note      string         `json:"note"`
+	CreatedBy string         `json:"created_by"`
+	CreatedAt time.Time      `json:"created_at"`
+	// AppliedAt is when the version was last pushed to the index
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	// Active is set on the version the index was last given
+	Active bool `json:"active"`
+}
+
+func newSearchSettingsVersion(row *repository.SearchSetting) (SearchSettingsVersion, error) {
+	v := SearchSettingsVersion{
+		Version:   row.Version,
+		Note:      row.Note,
+		CreatedBy: row.CreatedBy,
+		CreatedAt: row.CreatedAt,
+	}
+	if err := json.Unmarshal(row.Settings, &v.Settings); err != nil {
+		return SearchSettingsVersion{}, fmt.Errorf("decoding search settings version %d: %w", row.Version, err)
+	}
+	if row.AppliedAt.Valid {
+		v.AppliedAt = &row.AppliedAt.Time
+	}
+	return v, nil
+}
+
+// AppliedSearchSettings is the outcome of applying a version
+type AppliedSearchSettings struct {
+	SearchSettingsVersion
+	// PreviousVersion was active before, applying it again rolls back. It is
+	// 0 when no version had been applied.
+	PreviousVersion int32            `json:"previous_version,omitempty"`
+	Changes         []SettingsChange `json:"changes"`
+}
+
+// CreateSearchSettings adds a version of the settings, it takes effect once
+// applied
+func (s *CatalogService) CreateSearchSettings(ctx context.Context, settings SearchSettings, note, createdBy string) (SearchSettingsVersion, error) {
+	settings.normalize()
+	if err := settings.validate(); err != nil {
+		return SearchSettingsVersion{}, err
+	}
+	note = strings.TrimSpace(note)
+	if len(note) > maxSettingsNoteLength {
+		return SearchSettingsVersion{}, &ProductValidationError{Field: "note", Reason: fmt.Sprintf("must be at most %d characters", maxSettingsNoteLength)}
+	}
+
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return SearchSettingsVersion{}, err
+	}
+	row, err := s.queries.InsertSearchSettings(ctx, repository.InsertSearchSettingsParams{
+		Settings:  raw,
+		Note:      note,
+		CreatedBy: createdBy,
+	})
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to create search settings")
+		return SearchSettingsVersion{}, err
+	}
+
+	s.log.Info().Int32("version", row.Version).Str("createdBy", createdBy).Msg("search settings version created")
+
+	return newSearchSettingsVersion(&row)
+}
+
+func (s *CatalogService) GetSearchSettings(ctx context.Context, version int32) (SearchSettingsVersion, error) {
+	row, err := s.queries.GetSearchSettings(ctx, version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return SearchSettingsVersion{}, ErrSearchSettingsNotFound
+		}
+		return SearchSettingsVersion{}, err
+	}
+
+	v, err := newSearchSettingsVersion(&row)
+	if err != nil {
+		return SearchSettingsVersion{}, err
+	}
+	active, err := s.activeSearchSettingsVersion(ctx, s.queries)
+	if err != nil {
+		return SearchSettingsVersion{}, err
+	}
+	v.Active = v.Version == active
+	return v, nil
+}
+
+// ListSearchSettings returns the latest versions, newest first
+func (s *CatalogService) ListSearchSettings(ctx context.Context) ([]SearchSettingsVersion, error) {
+	rows, err := s.queries.ListSearchSettings(ctx, searchSettingsListSize)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to list search settings")
+		return nil, err
+	}
+	active, err := s.activeSearchSettingsVersion(ctx, s.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]SearchSettingsVersion, 0, len(rows))
+	for i := range rows {
+		v, err := newSearchSettingsVersion(&rows[i])
+		if err != nil {
+			return nil, err
+		}
+		v.Active = v.Version == active
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+// DiffSearchSettings lists what applying the version would change in the
+// index
+func (s *CatalogService) DiffSearchSettings(ctx context.Context, version int32) ([]SettingsChange, error) {
+	v, err := s.GetSearchSettings(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+	live, err := s.liveSearchSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return diffSettings(live, v.Settings), nil
+}
+
+// ApplySearchSettings pushes the version to the index and waits until the
+// index has taken it up. Applying an earlier version is how settings are
+// rolled back.
+//
+// The version is marked active before the push, so a push that fails rolls
+// the mark back with it. A failure once the push has started puts the active
+// version's settings back, and ReconcileSearchSettings covers a failure to do
+// even that.
+func (s *CatalogService) ApplySearchSettings(ctx context.Context, version int32) (AppliedSearchSettings, error) {
+	var res AppliedSearchSettings
+	pushed := false
+	err := s.withTx(ctx, func(q *repository.Queries) error {
+		if err := q.LockSearchSettings(ctx); err != nil {
+			return err
+		}
+
+		if _, err := q.GetSearchSettings(ctx, version); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrSearchSettingsNotFound
+			}
+			return err
+		}
+		var err error
+		if res.PreviousVersion, err = s.activeSearchSettingsVersion(ctx, q); err != nil {
+			return err
+		}
+
+		row, err := q.MarkSearchSettingsApplied(ctx, version)
+		if err != nil {
+			return err
+		}
+		if res.SearchSettingsVersion, err = newSearchSettingsVersion(&row); err != nil {
+			return err
+		}
+
+		pushed = true
+		res.Changes, err = s.pushSearchSettings(ctx, res.Settings)
+		return err
+	})
+	if err != nil {
+		if !errors.Is(err, ErrSearchSettingsNotFound) {
+			s.log.Error().Err(err).Int32("version", version).Msg("failed to apply search settings")
+		}
+		if pushed {
+			s.restoreSearchSettings(ctx)
+		}
+		return AppliedSearchSettings{}, err
+	}
+	res.Active = true
+
+	s.log.Info().Int32("version", version).Int32("previousVersion", res.PreviousVersion).Int("changes", len(res.Changes)).Msg("search settings applied")
+
+	return res, nil
+}
+
+// restoreSearchSettings gives the index the active version's settings again
+// after a failed apply, which may have changed some of them
+func (s *CatalogService) restoreSearchSettings(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), indexTaskTimeout)
+	defer cancel()
+
+	if _, err := s.ReconcileSearchSettings(ctx); err != nil {
+		s.log.Error().Err(err).Msg("failed to restore the active search settings, they are reconciled on the next start")
+	}
+}
+
+// ReconcileSearchSettings makes the index's settings those of the active
+// version and returns what it had to change. It runs at start, so an index
+// left out of step by a failed apply is put right.
+func (s *CatalogService) ReconcileSearchSettings(ctx context.Context) ([]SettingsChange, error) {
+	var changes []SettingsChange
+	err := s.withTx(ctx, func(q *repository.Queries) error {
+		if err := q.LockSearchSettings(ctx); err != nil {
+			return err
+		}
+		var err error
+		changes, err = s.applyActiveSearchSettings(ctx, q)
+		return err
+	})
+	return changes, err
+}
+
+// applyActiveSearchSettings gives the index the settings of the active
+// version, a new index starts with the defaults. The caller holds
+// LockSearchSettings, so the active version can't change underneath it.
+func (s *CatalogService) applyActiveSearchSettings(ctx context.Context, q *repository.Queries) ([]SettingsChange, error) {
+	row, err := q.GetActiveSearchSettings(ctx)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	v, err := newSearchSettingsVersion(&row)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.pushSearchSettings(ctx, v.Settings)
+}
+
+// activeSearchSettingsVersion returns 0 when no version was ever applied
+func (s *CatalogService) activeSearchSettingsVersion(ctx context.Context, q *repository.Queries) (int32, error) {
+	row, err := q.GetActiveSearchSettings(ctx)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return row.Version, nil
+}
+
+// liveSearchSettings reads the settings the index is using
+func (s *CatalogService) liveSearchSettings(ctx context.Context) (SearchSettings, error) {
+	live, err := s.index.GetSettingsWithContext(ctx)
+	if err != nil {
+		return SearchSettings{}, err
+	}
+
+	settings := SearchSettings{
+		Synonyms:             live.Synonyms,
+		StopWords:            live.StopWords,
+		RankingRules:         live.RankingRules,
+		SearchableAttributes: live.SearchableAttributes,
+	}
+	settings.normalize()
+	return settings, nil
+}
+
+// pushSearchSettings updates the settings that differ from the index's and
+// waits for each update. Settings left empty are reset, the index ignores
+// empty values in an update.
+func (s *CatalogService) pushSearchSettings(ctx context.Context, settings SearchSettings) ([]SettingsChange, error) {
+	live, err := s.liveSearchSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	changes := diffSettings(live, settings)
+	desired := settings.withDefaults()
+
+	for _, change := range changes {
+		var task *meilisearch.TaskInfo
+		switch change.Setting {
+		case settingSynonyms:
+			if len(desired.Synonyms) == 0 {
+				task, err = s.index.ResetSynonymsWithContext(ctx)
+			} else {
+				task, err = s.index.UpdateSynonymsWithContext(ctx, &desired.Synonyms)
+			}
+		case settingStopWords:
+			if len(desired.StopWords) == 0 {
+				task, err = s.index.ResetStopWordsWithContext(ctx)
+			} else {
+				task, err = s.index.UpdateStopWordsWithContext(ctx, &desired.StopWords)
+			}
+		case settingRankingRules:
+			task, err = s.index.UpdateRankingRulesWithContext(ctx, &desired.RankingRules)
+		case settingSearchableAttributes:
+			task, err = s.index.UpdateSearchableAttributesWithContext(ctx, &desired.SearchableAttributes)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.waitForTask(ctx, task.TaskUID); err != nil {
+			return nil, fmt.Errorf("applying %s: %w", change.Setting, err)
+		}
+	}
+
+	return changes, nil
+}
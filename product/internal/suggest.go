@@ -0,0 +1,417 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lmnzx/slopify/pkg/logger"
+
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/rs/zerolog"
+	"github.com/valkey-io/valkey-go"
+)
+
+const (
+	// prefixes shorter than this match too much to be worth storing
+	minSuggestPrefix = 2
+	// queries are stored under each of their prefixes up to this length,
+	// longer prefixes fall back to the longest stored one
+	maxSuggestPrefix = 15
+	maxSuggestQuery  = 64
+	// each prefix keeps only its most popular queries
+	suggestQueriesPerPrefix = 50
+	// prefixes nobody has searched for in this long are dropped
+	suggestPrefixTTL     = 30 * 24 * time.Hour
+	suggestRecordTimeout = 2 * time.Second
+	// suggestBufferSize is how many searches wait for the recorder before new
+	// ones are dropped, recording never blocks a request
+	suggestBufferSize = 1000
+	suggestBatchSize  = 100
+	// a client searching for the same query again within this long doesn't
+	// make it any more popular
+	suggestClientWindow = 24 * time.Hour
+
+	DefaultSuggestLimit = 8
+	MaxSuggestLimit     = 20
+)
+
+// the kinds of suggestion
+const (
+	SuggestionQuery    = "query"
+	SuggestionProduct  = "product"
+	SuggestionCategory = "category"
+)
+
+type Suggestion struct {
+	Text string `json:"text"`
+	Kind string `json:"kind"`
+	// ProductID is set for product suggestions
+	ProductID int32 `json:"product_id,omitempty"`
+}
+
+type Suggestions struct {
+	Suggestions []Suggestion `json:"suggestions"`
+	// DidYouMean is a popular query close to the input, set when nothing
+	// completes it
+	DidYouMean string `json:"did_you_mean,omitempty"`
+}
+
+// Suggester completes what is being typed into the search box from the
+// queries people actually searched for, ranked by how often, along with
+// matching product titles and categories from the index. Searches are queued
+// in memory and counted in batches by Start.
+type Suggester struct {
+	kv       valkey.Client
+	index    meilisearch.IndexManager
+	searches chan suggestSearch
+	dropped  atomic.Int64
+	log      zerolog.Logger
+}
+
+// suggestSearch is a normalized query waiting to be counted
+type suggestSearch struct {
+	query  string
+	client string
+}
+
+func NewSuggester(kv valkey.Client, index meilisearch.IndexManager) *Suggester {
+	return &Suggester{
+		kv:       kv,
+		index:    index,
+		searches: make(chan suggestSearch, suggestBufferSize),
+		log:      logger.GetLogger(),
+	}
+}
+
+func suggestKey(prefix string) string {
+	return "suggest:v1:" + prefix
+}
+
+func suggestSeenKey(client, query string) string {
+	return "suggest:v1:seen:" + client + ":" + query
+}
+
+// normalizeQuery lowercases the query and collapses its whitespace, it returns
+// an empty string for queries too short or too long to suggest
+func normalizeQuery(q string) string {
+	q = strings.Join(strings.Fields(strings.ToLower(q)), " ")
+	if n := utf8.RuneCountInString(q); n < minSuggestPrefix || n > maxSuggestQuery {
+		return ""
+	}
+	return q
+}
+
+// prefixes returns the stored prefixes of q from the shortest
+func prefixes(q string) []string {
+	var out []string
+	n := 0
+	for i := range q {
+		if n >= minSuggestPrefix {
+			out = append(out, q[:i])
+		}
+		n++
+		if n > maxSuggestPrefix {
+			return out
+		}
+	}
+	return append(out, q)
+}
+
+// Record queues a search to count towards the popularity of its query. It
+// only pays for a channel send, the search is dropped when the queue is full.
+func (s *Suggester) Record(query, client string) {
+	q := normalizeQuery(query)
+	if q == "" {
+		return
+	}
+
+	select {
+	case s.searches <- suggestSearch{query: q, client: client}:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// Start counts the queued searches until ctx is cancelled, then counts what is
+// left
+func (s *Suggester) Start(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	s.log.Info().Msg("suggestion recorder started")
+
+	batch := make([]suggestSearch, 0, suggestBatchSize)
+	record := func() {
+		// the writes outlive ctx so the searches left on shutdown are counted
+		recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), suggestRecordTimeout)
+		defer cancel()
+
+		s.record(recordCtx, batch)
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			for drained := false; !drained; {
+				select {
+				case search := <-s.searches:
+					batch = append(batch, search)
+					if len(batch) >= suggestBatchSize {
+						record()
+					}
+				default:
+					drained = true
+				}
+			}
+			record()
+			s.log.Info().Msg("suggestion recorder stopped")
+			return
+		case search := <-s.searches:
+			batch = append(batch, search)
+			// take whatever else is already queued, up to a batch
+			for drained := false; !drained && len(batch) < suggestBatchSize; {
+				select {
+				case search := <-s.searches:
+					batch = append(batch, search)
+				default:
+					drained = true
+				}
+			}
+			record()
+		}
+	}
+}
+
+// record counts each search towards the popularity of its query, once per
+// client and query in suggestClientWindow so nobody can push a query up by
+// repeating it
+func (s *Suggester) record(ctx context.Context, batch []suggestSearch) {
+	if n := s.dropped.Swap(0); n > 0 {
+		s.log.Warn().Int64("dropped", n).Msg("suggestion queue full, searches dropped")
+	}
+	if len(batch) == 0 {
+		return
+	}
+
+	seen := make(valkey.Commands, 0, len(batch))
+	for _, search := range batch {
+		seen = append(seen, s.kv.B().Set().Key(suggestSeenKey(search.client, search.query)).Value("1").Nx().Ex(suggestClientWindow).Build())
+	}
+
+	var cmds valkey.Commands
+	for i, res := range s.kv.DoMulti(ctx, seen...) {
+		if err := res.Error(); err != nil {
+			if !valkey.IsValkeyNil(err) {
+				s.log.Error().Err(err).Str("query", batch[i].query).Msg("failed to record search for suggestions")
+			}
+			continue
+		}
+
+		q := batch[i].query
+		for _, p := range prefixes(q) {
+			key := suggestKey(p)
+			cmds = append(cmds,
+				s.kv.B().Zincrby().Key(key).Increment(1).Member(q).Build(),
+				s.kv.B().Zremrangebyrank().Key(key).Start(0).Stop(-suggestQueriesPerPrefix-1).Build(),
+				s.kv.B().Expire().Key(key).Seconds(int64(suggestPrefixTTL.Seconds())).Build(),
+			)
+		}
+	}
+	if len(cmds) == 0 {
+		return
+	}
+
+	for _, res := range s.kv.DoMulti(ctx, cmds...) {
+		if err := res.Error(); err != nil {
+			s.log.Error().Err(err).Int("searches", len(batch)).Msg("failed to record searches for suggestions")
+			return
+		}
+	}
+}
+
+// Suggest returns up to limit completions of the prefix, popular queries
+// first, then categories and product titles. Each source is best effort, one
+// failing leaves the others.
+func (s *Suggester) Suggest(ctx context.Context, prefix string, limit int) *Suggestions {
+	limit = clamp(limit, DefaultSuggestLimit, MaxSuggestLimit)
+	res := &Suggestions{Suggestions: []Suggestion{}}
+
+	q := normalizeQuery(prefix)
+	if q == "" {
+		return res
+	}
+
+	seen := make(map[string]struct{}, limit)
+	add := func(sg Suggestion) {
+		key := strings.ToLower(sg.Text)
+		if _, ok := seen[key]; ok || len(res.Suggestions) >= limit {
+			return
+		}
+		seen[key] = struct{}{}
+		res.Suggestions = append(res.Suggestions, sg)
+	}
+
+	queries, err := s.popularQueries(ctx, q, limit)
+	if err != nil {
+		s.log.Error().Err(err).Str("prefix", q).Msg("failed to read popular queries")
+	}
+	for _, query := range queries {
+		add(Suggestion{Text: query, Kind: SuggestionQuery})
+	}
+
+	categories, err := s.categories(ctx, q)
+	if err != nil {
+		s.log.Error().Err(err).Str("prefix", q).Msg("failed to search categories for suggestions")
+	}
+	for _, c := range categories {
+		add(Suggestion{Text: c, Kind: SuggestionCategory})
+	}
+
+	products, err := s.products(ctx, q, limit)
+	if err != nil {
+		s.log.Error().Err(err).Str("prefix", q).Msg("failed to search products for suggestions")
+	}
+	for _, p := range products {
+		add(p)
+	}
+
+	if len(queries) == 0 {
+		res.DidYouMean = s.didYouMean(ctx, q)
+	}
+
+	return res
+}
+
+// popularQueries returns the most searched queries starting with q
+func (s *Suggester) popularQueries(ctx context.Context, q string, limit int) ([]string, error) {
+	stored := prefixes(q)
+	key := suggestKey(stored[len(stored)-1])
+
+	// a prefix longer than the stored ones needs filtering, so read all of
+	// the longest stored prefix's queries
+	n := int64(limit)
+	if stored[len(stored)-1] != q {
+		n = suggestQueriesPerPrefix
+	}
+
+	members, err := s.kv.Do(ctx, s.kv.B().Zrange().Key(key).Min("0").Max(strconv.FormatInt(n-1, 10)).Rev().Build()).AsStrSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, 0, limit)
+	for _, m := range members {
+		if strings.HasPrefix(m, q) && len(out) < limit {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+// categories returns the categories with products that start with q, the
+// index's facet search matches them by prefix
+func (s *Suggester) categories(ctx context.Context, q string) ([]string, error) {
+	raw, err := s.index.FacetSearchWithContext(ctx, &meilisearch.FacetSearchRequest{
+		FacetName:  attrCategory,
+		FacetQuery: q,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var res struct {
+		FacetHits []struct {
+			Value string `json:"value"`
+		} `json:"facetHits"`
+	}
+	if err := json.Unmarshal(*raw, &res); err != nil {
+		return nil, err
+	}
+
+	out := make([]string, 0, len(res.FacetHits))
+	for _, hit := range res.FacetHits {
+		out = append(out, hit.Value)
+	}
+	return out, nil
+}
+
+// products returns matching product titles. The index treats the last word as
+// a prefix and tolerates typos, so this also covers misspelled input.
+func (s *Suggester) products(ctx context.Context, q string, limit int) ([]Suggestion, error) {
+	raw, err := s.index.SearchRawWithContext(ctx, q, &meilisearch.SearchRequest{
+		Limit:                int64(limit),
+		AttributesToRetrieve: []string{"id", "title"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var res struct {
+		Hits []struct {
+			ID    int32  `json:"id"`
+			Title string `json:"title"`
+		} `json:"hits"`
+	}
+	if err := json.Unmarshal(*raw, &res); err != nil {
+		return nil, err
+	}
+
+	out := make([]Suggestion, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		out = append(out, Suggestion{Text: hit.Title, Kind: SuggestionProduct, ProductID: hit.ID})
+	}
+	return out, nil
+}
+
+// didYouMean looks for the most popular query within a couple of typos of q
+// among those sharing its first characters, typos rarely start a word
+func (s *Suggester) didYouMean(ctx context.Context, q string) string {
+	key := suggestKey(prefixes(q)[0])
+	members, err := s.kv.Do(ctx, s.kv.B().Zrange().Key(key).Min("0").Max("-1").Rev().Build()).AsStrSlice()
+	if err != nil {
+		if !valkey.IsValkeyNil(err) {
+			s.log.Error().Err(err).Str("query", q).Msg("failed to read queries for did you mean")
+		}
+		return ""
+	}
+
+	// one typo for short input, two for longer
+	maxDistance := 1
+	if utf8.RuneCountInString(q) > 5 {
+		maxDistance = 2
+	}
+	for _, m := range members {
+		if m != q && editDistance(q, m) <= maxDistance {
+			return m
+		}
+	}
+	return ""
+}
+
+// editDistance is the Levenshtein distance between a and b in runes
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
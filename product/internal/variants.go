@@ -0,0 +1,328 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/lmnzx/slopify/pkg/money"
+	"github.com/lmnzx/slopify/product/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	maxVariantAttributes    = 10
+	maxAttributeValueLength = 64
+)
+
+// attribute names double as search option names, option.<name> in the api
+var attributeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+var (
+	ErrVariantNotFound = errors.New("variant not found")
+	ErrVariantSKUTaken = errors.New("sku is already used by another variant")
+	ErrVariantExists   = errors.New("product already has a variant with these attributes")
+)
+
+type VariantInput struct {
+	SKU string
+	// Attributes tell variants of a product apart, {"size": "M", "colour": "red"}
+	Attributes map[string]string
+	// Price overrides the product's price when set, it has to be in the
+	// product's currency
+	Price           *money.Money
+	QuantityInStock int32
+	// Position orders the variants of a product
+	Position int32
+}
+
+func (in *VariantInput) normalize() {
+	in.SKU = strings.TrimSpace(in.SKU)
+
+	attrs := make(map[string]string, len(in.Attributes))
+	for k, v := range in.Attributes {
+		attrs[strings.ToLower(strings.TrimSpace(k))] = strings.TrimSpace(v)
+	}
+	in.Attributes = attrs
+}
+
+func (in *VariantInput) validate(product *repository.Product) error {
+	if in.SKU == "" {
+		return &ProductValidationError{Field: "sku", Reason: "is required"}
+	}
+	if len(in.SKU) > maxSKULength {
+		return &ProductValidationError{Field: "sku", Reason: fmt.Sprintf("must be at most %d characters", maxSKULength)}
+	}
+	if len(in.Attributes) == 0 || len(in.Attributes) > maxVariantAttributes {
+		return &ProductValidationError{Field: "attributes", Reason: fmt.Sprintf("must have between 1 and %d entries", maxVariantAttributes)}
+	}
+	for k, v := range in.Attributes {
+		if !attributeNamePattern.MatchString(k) {
+			return &ProductValidationError{Field: "attributes", Reason: fmt.Sprintf("%q must be lowercase letters, digits and underscores", k)}
+		}
+		if v == "" || len(v) > maxAttributeValueLength {
+			return &ProductValidationError{Field: "attributes", Reason: fmt.Sprintf("%s must be between 1 and %d characters", k, maxAttributeValueLength)}
+		}
+	}
+	if in.Price != nil {
+		if in.Price.Currency != product.Currency {
+			return &ProductValidationError{Field: "price", Reason: fmt.Sprintf("must be in the product's currency, %s", product.Currency)}
+		}
+		if in.Price.Amount <= 0 || in.Price.Amount > maxPriceMinor {
+			return &ProductValidationError{Field: "price", Reason: fmt.Sprintf("must be greater than 0 and at most %d minor units", maxPriceMinor)}
+		}
+	}
+	if in.QuantityInStock < 0 {
+		return &ProductValidationError{Field: "quantity_in_stock", Reason: "cannot be negative"}
+	}
+	return nil
+}
+
+func (in *VariantInput) priceMinor() *money.Minor {
+	if in.Price == nil {
+		return nil
+	}
+	return &in.Price.Amount
+}
+
+// ProductWithVariants is a product along with its live variants in display
+// order
+type ProductWithVariants struct {
+	repository.Product
+	Variants []repository.ProductVariant `json:"variants"`
+}
+
+// VariantAttributes decodes the variant's attributes, they are validated on
+// the way in so a decode error means the row was written by hand
+func VariantAttributes(v *repository.ProductVariant) map[string]string {
+	attrs := map[string]string{}
+	_ = json.Unmarshal(v.Attributes, &attrs)
+	return attrs
+}
+
+// VariantPrice is the variant's list price, the product's when it doesn't
+// override it
+func VariantPrice(p *repository.Product, v *repository.ProductVariant) money.Money {
+	if v.PriceMinor != nil {
+		return money.New(*v.PriceMinor, p.Currency)
+	}
+	return ProductPrice(p)
+}
+
+// VariantSalePrice is the variant's price with the product's discount applied
+func VariantSalePrice(p *repository.Product, v *repository.ProductVariant) money.Money {
+	return VariantPrice(p, v).ApplyDiscount(p.DiscountBps)
+}
+
+// VariantAvailable is the variant's stock that can still be reserved
+func VariantAvailable(v *repository.ProductVariant) int32 {
+	return max(v.QuantityInStock-v.ReservedQuantity, 0)
+}
+
+// GetProductWithVariants returns the product whether or not it is archived
+func (s *CatalogService) GetProductWithVariants(ctx context.Context, id int32) (ProductWithVariants, error) {
+	product, err := s.GetProduct(ctx, id)
+	if err != nil {
+		return ProductWithVariants{}, err
+	}
+
+	variants, err := s.GetVariants(ctx, []int32{id})
+	if err != nil {
+		return ProductWithVariants{}, err
+	}
+
+	return ProductWithVariants{Product: product, Variants: variants[id]}, nil
+}
+
+// GetVariants returns the live variants of the products keyed by product id,
+// products without variants have no entry
+func (s *CatalogService) GetVariants(ctx context.Context, productIDs []int32) (map[int32][]repository.ProductVariant, error) {
+	rows, err := s.queries.ListVariantsByProductIds(ctx, productIDs)
+	if err != nil {
+		s.log.Error().Err(err).Int("count", len(productIDs)).Msg("failed to get variants")
+		return nil, err
+	}
+	return groupVariants(rows), nil
+}
+
+func groupVariants(rows []repository.ProductVariant) map[int32][]repository.ProductVariant {
+	variants := make(map[int32][]repository.ProductVariant)
+	for _, v := range rows {
+		variants[v.ProductID] = append(variants[v.ProductID], v)
+	}
+	return variants
+}
+
+func (s *CatalogService) CreateVariant(ctx context.Context, productID int32, in VariantInput) (repository.ProductVariant, error) {
+	in.normalize()
+
+	var variant repository.ProductVariant
+	err := s.withTx(ctx, func(q *repository.Queries) error {
+		product, err := q.GetProduct(ctx, productID)
+		if err != nil {
+			return err
+		}
+		if err := in.validate(&product); err != nil {
+			return err
+		}
+
+		attrs, err := json.Marshal(in.Attributes)
+		if err != nil {
+			return err
+		}
+
+		variant, err = q.InsertProductVariant(ctx, repository.InsertProductVariantParams{
+			ProductID:       productID,
+			Sku:             in.SKU,
+			Attributes:      attrs,
+			PriceMinor:      in.priceMinor(),
+			QuantityInStock: in.QuantityInStock,
+			Position:        in.Position,
+		})
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.ProductVariant{}, ErrProductNotFound
+		}
+		return repository.ProductVariant{}, s.variantError(err, productID)
+	}
+
+	s.log.Info().Int32("productId", productID).Int32("variantId", variant.ID).Msg("variant created")
+
+	return variant, nil
+}
+
+func (s *CatalogService) UpdateVariant(ctx context.Context, productID, variantID int32, in VariantInput) (repository.ProductVariant, error) {
+	in.normalize()
+
+	var variant repository.ProductVariant
+	err := s.withTx(ctx, func(q *repository.Queries) error {
+		product, err := q.GetProduct(ctx, productID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrProductNotFound
+			}
+			return err
+		}
+		if err := in.validate(&product); err != nil {
+			return err
+		}
+
+		attrs, err := json.Marshal(in.Attributes)
+		if err != nil {
+			return err
+		}
+
+		variant, err = q.UpdateProductVariant(ctx, repository.UpdateProductVariantParams{
+			ID:              variantID,
+			ProductID:       productID,
+			Sku:             in.SKU,
+			Attributes:      attrs,
+			PriceMinor:      in.priceMinor(),
+			QuantityInStock: in.QuantityInStock,
+			Position:        in.Position,
+		})
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.ProductVariant{}, ErrVariantNotFound
+		}
+		return repository.ProductVariant{}, s.variantError(err, productID)
+	}
+
+	return variant, nil
+}
+
+// ArchiveVariant takes the variant off sale while keeping the row for orders
+// that refer to it
+func (s *CatalogService) ArchiveVariant(ctx context.Context, productID, variantID int32) (repository.ProductVariant, error) {
+	variant, err := s.queries.ArchiveProductVariant(ctx, repository.ArchiveProductVariantParams{
+		ID:        variantID,
+		ProductID: productID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.ProductVariant{}, ErrVariantNotFound
+		}
+		s.log.Error().Err(err).Int32("variantId", variantID).Msg("failed to archive variant")
+		return repository.ProductVariant{}, err
+	}
+
+	s.log.Info().Int32("productId", productID).Int32("variantId", variantID).Msg("variant archived")
+
+	return variant, nil
+}
+
+// variantError maps unique violations to their errors and logs anything that
+// isn't the caller's fault
+func (s *CatalogService) variantError(err error, productID int32) error {
+	var validationErr *ProductValidationError
+	if errors.As(err, &validationErr) || errors.Is(err, ErrProductNotFound) {
+		return err
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		switch pgErr.ConstraintName {
+		case "product_variants_sku_key":
+			return ErrVariantSKUTaken
+		case "idx_product_variants_attributes":
+			return ErrVariantExists
+		}
+	}
+
+	s.log.Error().Err(err).Int32("productId", productID).Msg("failed to save variant")
+	return err
+}
+
+// variantOptionKeys returns the option key of every combination of up to
+// MaxSearchOptions of the attributes, the keys a search can filter the
+// variant on
+func variantOptionKeys(attrs map[string]string) []string {
+	names := slices.Sorted(maps.Keys(attrs))
+
+	var keys []string
+	var walk func(start int, picked map[string]string)
+	walk = func(start int, picked map[string]string) {
+		for i := start; i < len(names); i++ {
+			picked[names[i]] = attrs[names[i]]
+			keys = append(keys, optionKey(picked))
+			if len(picked) < MaxSearchOptions {
+				walk(i+1, picked)
+			}
+			delete(picked, names[i])
+		}
+	}
+	walk(0, map[string]string{})
+	return keys
+}
+
+// optionKey joins the options in name order, "colour=red|size=m". Values are
+// lowercased as option filters ignore case, and | and \ in them are escaped so
+// no two sets of options share a key.
+func optionKey(options map[string]string) string {
+	var b strings.Builder
+	for i, name := range slices.Sorted(maps.Keys(options)) {
+		if i > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString(name)
+		b.WriteByte('=')
+		for _, r := range strings.ToLower(strings.TrimSpace(options[name])) {
+			if r == '|' || r == '\\' {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
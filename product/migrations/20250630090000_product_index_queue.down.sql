@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS products_index_queue ON products;
+DROP FUNCTION IF EXISTS enqueue_product_index();
+DROP TABLE IF EXISTS product_index_queue;
@@ -0,0 +1,30 @@
+-- every change to products leaves a row here, the indexer removes rows once
+-- the search index has applied them
+CREATE TABLE product_index_queue (
+    id BIGSERIAL PRIMARY KEY,
+    product_id INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE FUNCTION enqueue_product_index() RETURNS trigger AS $$
+DECLARE
+    changed_id INT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed_id := OLD.id;
+    ELSE
+        changed_id := NEW.id;
+    END IF;
+
+    INSERT INTO product_index_queue (product_id) VALUES (changed_id);
+    PERFORM pg_notify('product_index', changed_id::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER products_index_queue
+AFTER INSERT OR UPDATE OR DELETE ON products
+FOR EACH ROW EXECUTE FUNCTION enqueue_product_index();
+
+-- rows written before the trigger existed may never have reached the index
+INSERT INTO product_index_queue (product_id) SELECT id FROM products;
@@ -0,0 +1,5 @@
+-- sku is the key bulk imports match on, products created through the admin
+-- API don't need one
+ALTER TABLE products ADD COLUMN sku TEXT;
+
+CREATE UNIQUE INDEX idx_products_sku ON products (sku) WHERE sku IS NOT NULL;
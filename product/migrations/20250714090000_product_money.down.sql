@@ -0,0 +1,14 @@
+ALTER TABLE products ADD COLUMN price DECIMAL(10, 2);
+ALTER TABLE products ADD COLUMN discount DECIMAL(10, 2) NOT NULL DEFAULT 0.00;
+
+-- only exact for currencies with two minor unit digits, which was all there
+-- was before this migration
+UPDATE products
+SET price = price_minor / 100.0,
+    discount = discount_bps / 100.0;
+
+ALTER TABLE products ALTER COLUMN price SET NOT NULL;
+
+ALTER TABLE products DROP COLUMN discount_bps;
+ALTER TABLE products DROP COLUMN currency;
+ALTER TABLE products DROP COLUMN price_minor;
@@ -0,0 +1,17 @@
+-- prices move from DECIMAL, which sqlc could only hand us as a float, to
+-- integer minor units and discounts to basis points (hundredths of a percent)
+ALTER TABLE products ADD COLUMN price_minor BIGINT;
+ALTER TABLE products ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE products ADD COLUMN discount_bps INTEGER NOT NULL DEFAULT 0;
+
+-- every existing price is in USD, which has two minor unit digits
+UPDATE products
+SET price_minor = ROUND(price * 100)::BIGINT,
+    discount_bps = ROUND(discount * 100)::INTEGER;
+
+ALTER TABLE products ALTER COLUMN price_minor SET NOT NULL;
+ALTER TABLE products ADD CONSTRAINT products_price_minor_check CHECK (price_minor > 0);
+ALTER TABLE products ADD CONSTRAINT products_discount_bps_check CHECK (discount_bps BETWEEN 0 AND 10000);
+
+ALTER TABLE products DROP COLUMN price;
+ALTER TABLE products DROP COLUMN discount;
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS product_variants_index_queue ON product_variants;
+DROP FUNCTION IF EXISTS enqueue_product_variant_index();
+DROP TABLE IF EXISTS product_variants;
@@ -0,0 +1,42 @@
+-- a variant is a purchasable version of a product, one size and colour of a
+-- shirt for example. Its price overrides the product's when set and is in the
+-- product's currency.
+CREATE TABLE product_variants (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    sku TEXT NOT NULL UNIQUE,
+    attributes JSONB NOT NULL DEFAULT '{}',
+    price_minor BIGINT CHECK (price_minor > 0),
+    quantity_in_stock INTEGER NOT NULL DEFAULT 0 CHECK (quantity_in_stock >= 0),
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    archived_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_product_variants_product_id ON product_variants (product_id);
+
+-- two live variants of one product can't have the same attributes
+CREATE UNIQUE INDEX idx_product_variants_attributes ON product_variants (product_id, attributes) WHERE archived_at IS NULL;
+
+-- variants are part of their product's search document and cache entry, so a
+-- change to one is queued as a change to the product
+CREATE FUNCTION enqueue_product_variant_index() RETURNS trigger AS $$
+DECLARE
+    changed_id INT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed_id := OLD.product_id;
+    ELSE
+        changed_id := NEW.product_id;
+    END IF;
+
+    INSERT INTO product_index_queue (product_id) VALUES (changed_id);
+    PERFORM pg_notify('product_index', changed_id::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER product_variants_index_queue
+AFTER INSERT OR UPDATE OR DELETE ON product_variants
+FOR EACH ROW EXECUTE FUNCTION enqueue_product_variant_index();
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_products_category;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_category_fkey;
+DROP TABLE IF EXISTS categories;
@@ -0,0 +1,45 @@
+-- categories form a tree, products point at one by slug and belong to every
+-- ancestor of it as well
+CREATE TABLE categories (
+    id SERIAL PRIMARY KEY,
+    parent_id INT REFERENCES categories (id),
+    slug TEXT NOT NULL UNIQUE CHECK (slug ~ '^[a-z0-9]+(-[a-z0-9]+)*$'),
+    name TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_categories_parent_id ON categories (parent_id);
+
+-- the free-text categories in use become top level categories. A category
+-- with no ascii letters or digits has no slug of its own, it is named after
+-- the first of its products instead.
+CREATE TEMPORARY TABLE category_slugs AS
+SELECT category AS name,
+       COALESCE(
+           NULLIF(trim(BOTH '-' FROM regexp_replace(lower(category), '[^a-z0-9]+', '-', 'g')), ''),
+           'category-' || min(id)
+       ) AS slug
+FROM products
+GROUP BY category;
+
+INSERT INTO categories (slug, name)
+SELECT DISTINCT ON (slug) slug, COALESCE(NULLIF(initcap(trim(name)), ''), slug)
+FROM category_slugs
+ORDER BY slug, name
+ON CONFLICT (slug) DO NOTHING;
+
+-- this touches every product, which also queues them all so their search
+-- documents pick up the category path
+UPDATE products
+SET category = category_slugs.slug
+FROM category_slugs
+WHERE products.category = category_slugs.name;
+
+DROP TABLE category_slugs;
+
+ALTER TABLE products
+ADD CONSTRAINT products_category_fkey FOREIGN KEY (category) REFERENCES categories (slug);
+
+CREATE INDEX idx_products_category ON products (category);
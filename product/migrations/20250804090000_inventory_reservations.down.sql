@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS inventory_reservation_items;
+DROP TABLE IF EXISTS inventory_reservations;
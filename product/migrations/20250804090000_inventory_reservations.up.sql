@@ -0,0 +1,26 @@
+-- a reservation holds stock for a checkout. The stock is taken off the product
+-- or variant when it is reserved and put back when the reservation is
+-- released or expires, committing keeps it off for good.
+CREATE TABLE inventory_reservations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    -- the caller's id for the checkout, reserving twice with it is a no-op
+    reference TEXT NOT NULL UNIQUE,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'committed', 'released', 'expired')),
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- the reaper looks for pending reservations past their expiry
+CREATE INDEX idx_inventory_reservations_pending ON inventory_reservations (expires_at) WHERE status = 'pending';
+
+CREATE TABLE inventory_reservation_items (
+    id SERIAL PRIMARY KEY,
+    reservation_id UUID NOT NULL REFERENCES inventory_reservations (id) ON DELETE CASCADE,
+    product_id INT NOT NULL REFERENCES products (id),
+    -- null when the product has no variants and its own stock was reserved
+    variant_id INT REFERENCES product_variants (id),
+    quantity INTEGER NOT NULL CHECK (quantity > 0)
+);
+
+CREATE INDEX idx_inventory_reservation_items_reservation_id ON inventory_reservation_items (reservation_id);
@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS product_reviews_rating ON product_reviews;
+DROP FUNCTION IF EXISTS update_product_rating();
+DROP TABLE IF EXISTS product_reviews;
+
+ALTER TABLE products DROP COLUMN IF EXISTS rating_sum;
+ALTER TABLE products DROP COLUMN IF EXISTS rating_count;
@@ -0,0 +1,56 @@
+-- a user has at most one review per product. Reviews are published straight
+-- away, admins can hold them back or reject them.
+CREATE TABLE product_reviews (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    title TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'published' CHECK (status IN ('pending', 'published', 'rejected')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (product_id, user_id)
+);
+
+CREATE INDEX idx_product_reviews_product_id ON product_reviews (product_id, status, created_at);
+CREATE INDEX idx_product_reviews_status ON product_reviews (status, created_at);
+
+-- the aggregate of the published reviews, kept as a sum so the average is
+-- exact
+ALTER TABLE products ADD COLUMN rating_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN rating_sum INTEGER NOT NULL DEFAULT 0;
+
+-- recounting rather than adjusting keeps the aggregate right whatever the
+-- change was. Updating the product queues it for the search index.
+CREATE FUNCTION update_product_rating() RETURNS trigger AS $$
+DECLARE
+    changed_id INT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed_id := OLD.product_id;
+    ELSE
+        changed_id := NEW.product_id;
+    END IF;
+
+    -- concurrent review changes for the product wait here, so each recount
+    -- sees the ones committed before it
+    PERFORM 1 FROM products WHERE id = changed_id FOR UPDATE;
+
+    UPDATE products
+    SET rating_count = agg.count,
+        rating_sum = agg.sum
+    FROM (
+        SELECT COUNT(*) AS count, COALESCE(SUM(rating), 0) AS sum
+        FROM product_reviews
+        WHERE product_id = changed_id AND status = 'published'
+    ) agg
+    WHERE products.id = changed_id
+      AND (products.rating_count, products.rating_sum) IS DISTINCT FROM (agg.count, agg.sum);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER product_reviews_rating
+AFTER INSERT OR UPDATE OR DELETE ON product_reviews
+FOR EACH ROW EXECUTE FUNCTION update_product_rating();
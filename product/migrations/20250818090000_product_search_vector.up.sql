@@ -0,0 +1,9 @@
+-- full text search over the catalog for when the search index is unavailable.
+-- Titles weigh most, so ranking follows roughly what the index does.
+ALTER TABLE products ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', title), 'A') ||
+    setweight(to_tsvector('english', replace(category, '-', ' ')), 'B') ||
+    setweight(to_tsvector('english', description), 'C')
+) STORED;
+
+CREATE INDEX idx_products_search_vector ON products USING GIN (search_vector);
@@ -0,0 +1,30 @@
+-- a sample of the searches made, written in batches after the fact, so
+-- created_at is when the search happened rather than when it was stored
+CREATE TABLE search_events (
+    id UUID PRIMARY KEY,
+    query TEXT NOT NULL,
+    filters TEXT[] NOT NULL DEFAULT '{}',
+    sort TEXT NOT NULL DEFAULT '',
+    hit_count BIGINT NOT NULL,
+    latency_ms INTEGER NOT NULL,
+    -- one of user_id or guest_id is set
+    user_id UUID,
+    guest_id UUID,
+    degraded BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_search_events_created_at ON search_events (created_at);
+
+-- results opened from a sampled search. There is no foreign key, a click can
+-- be stored before the batch holding its search.
+CREATE TABLE search_clicks (
+    id BIGSERIAL PRIMARY KEY,
+    search_id UUID NOT NULL,
+    product_id INT NOT NULL,
+    position INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_search_clicks_search_id ON search_clicks (search_id);
+CREATE INDEX idx_search_clicks_created_at ON search_clicks (created_at);
@@ -0,0 +1,15 @@
+-- versions of the relevance settings of the products index. A version is
+-- never changed once written, editing the settings adds a version and rolling
+-- back applies an earlier one again.
+CREATE TABLE search_settings (
+    version SERIAL PRIMARY KEY,
+    settings JSONB NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- when the version was last pushed to the index, the live settings are
+    -- those of the most recently applied version
+    applied_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_search_settings_applied_at ON search_settings (applied_at) WHERE applied_at IS NOT NULL;
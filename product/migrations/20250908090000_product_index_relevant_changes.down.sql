@@ -0,0 +1,31 @@
+CREATE OR REPLACE FUNCTION enqueue_product_index() RETURNS trigger AS $$
+DECLARE
+    changed_id INT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed_id := OLD.id;
+    ELSE
+        changed_id := NEW.id;
+    END IF;
+
+    INSERT INTO product_index_queue (product_id) VALUES (changed_id);
+    PERFORM pg_notify('product_index', changed_id::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION enqueue_product_variant_index() RETURNS trigger AS $$
+DECLARE
+    changed_id INT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed_id := OLD.product_id;
+    ELSE
+        changed_id := NEW.product_id;
+    END IF;
+
+    INSERT INTO product_index_queue (product_id) VALUES (changed_id);
+    PERFORM pg_notify('product_index', changed_id::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,54 @@
+-- stock moves with every order, but the search document only needs to know
+-- whether there is any. An update that only changes stock without crossing
+-- zero no longer queues the product for the index. Every change still
+-- notifies product_change, which the product cache listens on, so cached
+-- products always show the current stock.
+CREATE OR REPLACE FUNCTION enqueue_product_index() RETURNS trigger AS $$
+DECLARE
+    changed_id INT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed_id := OLD.id;
+    ELSE
+        changed_id := NEW.id;
+    END IF;
+
+    PERFORM pg_notify('product_change', changed_id::text);
+
+    -- comparing everything but the stock columns keeps columns added later,
+    -- like the rating, queuing the product without listing them here
+    IF TG_OP = 'UPDATE'
+        AND to_jsonb(OLD) - 'quantity_in_stock' - 'updated_at' = to_jsonb(NEW) - 'quantity_in_stock' - 'updated_at'
+        AND (OLD.quantity_in_stock > 0) = (NEW.quantity_in_stock > 0) THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO product_index_queue (product_id) VALUES (changed_id);
+    PERFORM pg_notify('product_index', changed_id::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION enqueue_product_variant_index() RETURNS trigger AS $$
+DECLARE
+    changed_id INT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed_id := OLD.product_id;
+    ELSE
+        changed_id := NEW.product_id;
+    END IF;
+
+    PERFORM pg_notify('product_change', changed_id::text);
+
+    IF TG_OP = 'UPDATE'
+        AND to_jsonb(OLD) - 'quantity_in_stock' - 'updated_at' = to_jsonb(NEW) - 'quantity_in_stock' - 'updated_at'
+        AND (OLD.quantity_in_stock > 0) = (NEW.quantity_in_stock > 0) THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO product_index_queue (product_id) VALUES (changed_id);
+    PERFORM pg_notify('product_index', changed_id::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
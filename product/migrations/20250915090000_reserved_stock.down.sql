@@ -0,0 +1,62 @@
+-- held stock is taken off the stock on hand again, the way reservations
+-- used to keep it
+UPDATE products
+SET quantity_in_stock = GREATEST(quantity_in_stock - reserved_quantity, 0)
+WHERE reserved_quantity > 0;
+
+UPDATE product_variants
+SET quantity_in_stock = GREATEST(quantity_in_stock - reserved_quantity, 0)
+WHERE reserved_quantity > 0;
+
+CREATE OR REPLACE FUNCTION enqueue_product_index() RETURNS trigger AS $$
+DECLARE
+    changed_id INT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed_id := OLD.id;
+    ELSE
+        changed_id := NEW.id;
+    END IF;
+
+    PERFORM pg_notify('product_change', changed_id::text);
+
+    -- comparing everything but the stock columns keeps columns added later,
+    -- like the rating, queuing the product without listing them here
+    IF TG_OP = 'UPDATE'
+        AND to_jsonb(OLD) - 'quantity_in_stock' - 'updated_at' = to_jsonb(NEW) - 'quantity_in_stock' - 'updated_at'
+        AND (OLD.quantity_in_stock > 0) = (NEW.quantity_in_stock > 0) THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO product_index_queue (product_id) VALUES (changed_id);
+    PERFORM pg_notify('product_index', changed_id::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION enqueue_product_variant_index() RETURNS trigger AS $$
+DECLARE
+    changed_id INT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed_id := OLD.product_id;
+    ELSE
+        changed_id := NEW.product_id;
+    END IF;
+
+    PERFORM pg_notify('product_change', changed_id::text);
+
+    IF TG_OP = 'UPDATE'
+        AND to_jsonb(OLD) - 'quantity_in_stock' - 'updated_at' = to_jsonb(NEW) - 'quantity_in_stock' - 'updated_at'
+        AND (OLD.quantity_in_stock > 0) = (NEW.quantity_in_stock > 0) THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO product_index_queue (product_id) VALUES (changed_id);
+    PERFORM pg_notify('product_index', changed_id::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE product_variants DROP COLUMN reserved_quantity;
+ALTER TABLE products DROP COLUMN reserved_quantity;
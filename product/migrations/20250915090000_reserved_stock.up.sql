@@ -0,0 +1,87 @@
+-- reservations used to take stock off quantity_in_stock, so an admin edit or
+-- import that set the stock while a reservation was pending had it added back
+-- on release. quantity_in_stock is now the stock on hand, the one admins set,
+-- and pending reservations hold reserved_quantity of it. What can be
+-- reserved is quantity_in_stock - reserved_quantity.
+ALTER TABLE products ADD COLUMN reserved_quantity INTEGER NOT NULL DEFAULT 0 CHECK (reserved_quantity >= 0);
+ALTER TABLE product_variants ADD COLUMN reserved_quantity INTEGER NOT NULL DEFAULT 0 CHECK (reserved_quantity >= 0);
+
+-- pending reservations already took their stock off, it goes back on hand and
+-- is held instead
+UPDATE products
+SET quantity_in_stock = products.quantity_in_stock + pending.quantity,
+    reserved_quantity = pending.quantity
+FROM (
+    SELECT i.product_id, SUM(i.quantity)::int AS quantity
+    FROM inventory_reservation_items i
+    JOIN inventory_reservations r ON r.id = i.reservation_id
+    WHERE r.status = 'pending' AND i.variant_id IS NULL
+    GROUP BY i.product_id
+) pending
+WHERE products.id = pending.product_id;
+
+UPDATE product_variants
+SET quantity_in_stock = product_variants.quantity_in_stock + pending.quantity,
+    reserved_quantity = pending.quantity
+FROM (
+    SELECT i.variant_id, SUM(i.quantity)::int AS quantity
+    FROM inventory_reservation_items i
+    JOIN inventory_reservations r ON r.id = i.reservation_id
+    WHERE r.status = 'pending' AND i.variant_id IS NOT NULL
+    GROUP BY i.variant_id
+) pending
+WHERE product_variants.id = pending.variant_id;
+
+-- the search document's stock is what is available, only a change to
+-- whether any is left queues the product
+CREATE OR REPLACE FUNCTION enqueue_product_index() RETURNS trigger AS $$
+DECLARE
+    changed_id INT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed_id := OLD.id;
+    ELSE
+        changed_id := NEW.id;
+    END IF;
+
+    PERFORM pg_notify('product_change', changed_id::text);
+
+    -- comparing everything but the stock columns keeps columns added later,
+    -- like the rating, queuing the product without listing them here
+    IF TG_OP = 'UPDATE'
+        AND to_jsonb(OLD) - 'quantity_in_stock' - 'reserved_quantity' - 'updated_at'
+            = to_jsonb(NEW) - 'quantity_in_stock' - 'reserved_quantity' - 'updated_at'
+        AND (OLD.quantity_in_stock > OLD.reserved_quantity) = (NEW.quantity_in_stock > NEW.reserved_quantity) THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO product_index_queue (product_id) VALUES (changed_id);
+    PERFORM pg_notify('product_index', changed_id::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION enqueue_product_variant_index() RETURNS trigger AS $$
+DECLARE
+    changed_id INT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed_id := OLD.product_id;
+    ELSE
+        changed_id := NEW.product_id;
+    END IF;
+
+    PERFORM pg_notify('product_change', changed_id::text);
+
+    IF TG_OP = 'UPDATE'
+        AND to_jsonb(OLD) - 'quantity_in_stock' - 'reserved_quantity' - 'updated_at'
+            = to_jsonb(NEW) - 'quantity_in_stock' - 'reserved_quantity' - 'updated_at'
+        AND (OLD.quantity_in_stock > OLD.reserved_quantity) = (NEW.quantity_in_stock > NEW.reserved_quantity) THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO product_index_queue (product_id) VALUES (changed_id);
+    PERFORM pg_notify('product_index', changed_id::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
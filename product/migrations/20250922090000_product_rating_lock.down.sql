@@ -0,0 +1,27 @@
+CREATE OR REPLACE FUNCTION update_product_rating() RETURNS trigger AS $$
+DECLARE
+    changed_id INT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed_id := OLD.product_id;
+    ELSE
+        changed_id := NEW.product_id;
+    END IF;
+
+    -- concurrent review changes for the product wait here, so each recount
+    -- sees the ones committed before it
+    PERFORM 1 FROM products WHERE id = changed_id FOR UPDATE;
+
+    UPDATE products
+    SET rating_count = agg.count,
+        rating_sum = agg.sum
+    FROM (
+        SELECT COUNT(*) AS count, COALESCE(SUM(rating), 0) AS sum
+        FROM product_reviews
+        WHERE product_id = changed_id AND status = 'published'
+    ) agg
+    WHERE products.id = changed_id
+      AND (products.rating_count, products.rating_sum) IS DISTINCT FROM (agg.count, agg.sum);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,32 @@
+-- inserting a review takes a KEY SHARE lock on its product for the foreign
+-- key, which FOR UPDATE conflicts with. Two reviews of one product inserted
+-- at once each held the KEY SHARE lock and waited for the other's to go
+-- before their recount could lock the product, a deadlock. FOR NO KEY UPDATE
+-- doesn't conflict with KEY SHARE and still queues the recounts.
+CREATE OR REPLACE FUNCTION update_product_rating() RETURNS trigger AS $$
+DECLARE
+    changed_id INT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed_id := OLD.product_id;
+    ELSE
+        changed_id := NEW.product_id;
+    END IF;
+
+    -- concurrent review changes for the product wait here, so each recount
+    -- sees the ones committed before it
+    PERFORM 1 FROM products WHERE id = changed_id FOR NO KEY UPDATE;
+
+    UPDATE products
+    SET rating_count = agg.count,
+        rating_sum = agg.sum
+    FROM (
+        SELECT COUNT(*) AS count, COALESCE(SUM(rating), 0) AS sum
+        FROM product_reviews
+        WHERE product_id = changed_id AND status = 'published'
+    ) agg
+    WHERE products.id = changed_id
+      AND (products.rating_count, products.rating_sum) IS DISTINCT FROM (agg.count, agg.sum);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_products_search_vector;
+
+DROP FUNCTION IF EXISTS product_search_vector(TEXT, TEXT, TEXT);
+
+ALTER TABLE products ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', title), 'A') ||
+    setweight(to_tsvector('english', replace(category, '-', ' ')), 'B') ||
+    setweight(to_tsvector('english', description), 'C')
+) STORED;
+
+CREATE INDEX idx_products_search_vector ON products USING GIN (search_vector);
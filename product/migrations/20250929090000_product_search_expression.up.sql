@@ -0,0 +1,15 @@
+-- a stored search_vector came back with every SELECT * and RETURNING * of a
+-- product. The fallback search indexes the expression instead, queries get
+-- the same index by calling product_search_vector.
+DROP INDEX IF EXISTS idx_products_search_vector;
+
+ALTER TABLE products DROP COLUMN IF EXISTS search_vector;
+
+-- titles weigh most, so ranking follows roughly what the index does
+CREATE FUNCTION product_search_vector(title TEXT, category TEXT, description TEXT) RETURNS tsvector AS $$
+    SELECT setweight(to_tsvector('english', title), 'A') ||
+           setweight(to_tsvector('english', replace(category, '-', ' ')), 'B') ||
+           setweight(to_tsvector('english', description), 'C')
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE INDEX idx_products_search_vector ON products USING GIN (product_search_vector(title, category, description));
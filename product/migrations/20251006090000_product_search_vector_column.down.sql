@@ -0,0 +1,13 @@
+DROP VIEW IF EXISTS product_rows;
+
+DROP INDEX IF EXISTS idx_products_search_vector;
+
+ALTER TABLE products DROP COLUMN IF EXISTS search_vector;
+
+CREATE FUNCTION product_search_vector(title TEXT, category TEXT, description TEXT) RETURNS tsvector AS $$
+    SELECT setweight(to_tsvector('english', title), 'A') ||
+           setweight(to_tsvector('english', replace(category, '-', ' ')), 'B') ||
+           setweight(to_tsvector('english', description), 'C')
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE INDEX idx_products_search_vector ON products USING GIN (product_search_vector(title, category, description));
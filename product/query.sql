@@ -1,13 +1,524 @@
 -- name: ListAllProducts :many
-SELECT * FROM products;
+SELECT * FROM product_rows;
 
 -- name: ListProductsByCategory :many
-SELECT * FROM products
-WHERE category = $1;
+-- products in the category or any of its descendants
+WITH RECURSIVE subtree AS (
+  SELECT id, slug FROM categories WHERE categories.slug = sqlc.arg('slug')
+  UNION ALL
+  SELECT c.id, c.slug FROM categories c JOIN subtree s ON c.parent_id = s.id
+)
+SELECT product_rows.* FROM product_rows
+WHERE product_rows.category IN (SELECT slug FROM subtree);
 
 -- name: CreateProduct :exec
 INSERT INTO products (
-  id, title, description, category, price, discount, quantity_in_stock
+  id, title, description, category, price_minor, currency, discount_bps, quantity_in_stock
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+);
+
+-- name: GetProduct :one
+SELECT * FROM product_rows
+WHERE id = $1;
+
+-- name: InsertProduct :one
+-- returns the columns of product_rows, search_vector is left out
+INSERT INTO products (
+  title, description, category, price_minor, currency, discount_bps, quantity_in_stock
 ) VALUES (
   $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING id, title, description, category, quantity_in_stock, created_at, updated_at, archived_at,
+          sku, price_minor, currency, discount_bps, rating_count, rating_sum, reserved_quantity;
+
+-- name: UpdateProduct :one
+-- returns the columns of product_rows, search_vector is left out
+UPDATE products
+SET title = $2,
+    description = $3,
+    category = $4,
+    price_minor = $5,
+    currency = $6,
+    discount_bps = $7,
+    quantity_in_stock = $8,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, title, description, category, quantity_in_stock, created_at, updated_at, archived_at,
+          sku, price_minor, currency, discount_bps, rating_count, rating_sum, reserved_quantity;
+
+-- name: ArchiveProduct :one
+-- returns the columns of product_rows, search_vector is left out
+UPDATE products
+SET archived_at = COALESCE(archived_at, CURRENT_TIMESTAMP),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, title, description, category, quantity_in_stock, created_at, updated_at, archived_at,
+          sku, price_minor, currency, discount_bps, rating_count, rating_sum, reserved_quantity;
+
+-- name: GetProductsByIds :many
+SELECT * FROM product_rows
+WHERE id = ANY(sqlc.arg('ids')::int[]);
+
+-- name: ListProducts :many
+-- category matches its descendants as well, categories holds the category
+-- and its descendants' slugs
+SELECT * FROM product_rows
+WHERE id > sqlc.arg('after')
+  AND (sqlc.narg('categories')::text[] IS NULL OR category = ANY(sqlc.narg('categories')::text[]))
+  AND (sqlc.arg('include_archived')::boolean OR archived_at IS NULL)
+ORDER BY id
+LIMIT sqlc.arg('page_size');
+
+-- name: SearchProductsFullText :many
+-- the search fallback, best matches first. An empty query matches every
+-- product. It filters the way the index does, so max_results caps the matching
+-- products rather than every match. categories holds the filtered categories
+-- and their descendants' slugs. The price bounds are in major units and
+-- infinite when unset, exponents holds the minor unit digits of each of
+-- currencies. For a product with
+-- variants the price is the lowest variant price and the stock is what the
+-- variants have available.
+SELECT product_rows.* FROM product_rows
+JOIN products ON products.id = product_rows.id
+WHERE product_rows.archived_at IS NULL
+  AND (sqlc.arg('query')::text = '' OR products.search_vector @@ websearch_to_tsquery('english', sqlc.arg('query')::text))
+  AND (sqlc.narg('categories')::text[] IS NULL OR product_rows.category = ANY(sqlc.narg('categories')::text[]))
+  AND product_rows.discount_bps::float8 / 100 >= sqlc.arg('min_discount')::float8
+  AND (
+    SELECT COALESCE(min(COALESCE(v.price_minor, product_rows.price_minor)), product_rows.price_minor)
+    FROM product_variants v
+    WHERE v.product_id = product_rows.id AND v.archived_at IS NULL
+  )::float8 / 10::float8 ^ COALESCE((sqlc.arg('exponents')::int[])[array_position(sqlc.arg('currencies')::text[], product_rows.currency::text)], 0)
+    BETWEEN sqlc.arg('min_price')::float8 AND sqlc.arg('max_price')::float8
+  AND (NOT sqlc.arg('in_stock')::boolean OR (
+    SELECT COALESCE(sum(GREATEST(v.quantity_in_stock - v.reserved_quantity, 0)), GREATEST(product_rows.quantity_in_stock - product_rows.reserved_quantity, 0))
+    FROM product_variants v
+    WHERE v.product_id = product_rows.id AND v.archived_at IS NULL
+  ) > 0)
+ORDER BY ts_rank_cd(products.search_vector, websearch_to_tsquery('english', sqlc.arg('query')::text)) DESC, product_rows.id
+LIMIT sqlc.arg('max_results');
+
+-- name: LockProductIndexQueue :exec
+-- held until the end of the transaction, so only one indexer applies changes
+-- at a time and they reach the index in order
+SELECT pg_advisory_xact_lock(hashtext('product_index_queue'));
+
+-- name: ListProductIndexQueue :many
+SELECT * FROM product_index_queue
+ORDER BY id
+LIMIT $1;
+
+-- name: DeleteProductIndexQueue :exec
+DELETE FROM product_index_queue
+WHERE id = ANY(sqlc.arg('ids')::bigint[]);
+
+-- name: GetProductIndexQueueStats :one
+SELECT COUNT(*)::bigint AS pending,
+       COALESCE(EXTRACT(EPOCH FROM CURRENT_TIMESTAMP - MIN(created_at)), 0)::float8 AS lag_seconds
+FROM product_index_queue;
+
+-- name: AdoptProductSKU :execrows
+-- products seeded before skus existed have none, the first one with the
+-- record's title and category takes the sku so the upsert updates it rather
+-- than inserting a copy on every import
+UPDATE products
+SET sku = sqlc.arg('sku')::text
+WHERE id = (
+  SELECT p.id FROM products p
+  WHERE p.sku IS NULL
+    AND p.archived_at IS NULL
+    AND p.title = sqlc.arg('title')
+    AND p.category = sqlc.arg('category')
+  ORDER BY p.id
+  LIMIT 1
+)
+AND NOT EXISTS (SELECT 1 FROM products taken WHERE taken.sku = sqlc.arg('sku')::text);
+
+-- name: UpsertProductBySKU :one
+-- returns no row when the product already matches, so re-running an import
+-- doesn't touch updated_at or queue the product for indexing again
+INSERT INTO products (
+  sku, title, description, category, price_minor, currency, discount_bps, quantity_in_stock
+) VALUES (
+  sqlc.arg('sku')::text, sqlc.arg('title'), sqlc.arg('description'), sqlc.arg('category'),
+  sqlc.arg('price_minor'), sqlc.arg('currency'), sqlc.arg('discount_bps'), sqlc.arg('quantity_in_stock')
+)
+ON CONFLICT (sku) WHERE sku IS NOT NULL DO UPDATE
+SET title = EXCLUDED.title,
+    description = EXCLUDED.description,
+    category = EXCLUDED.category,
+    price_minor = EXCLUDED.price_minor,
+    currency = EXCLUDED.currency,
+    discount_bps = EXCLUDED.discount_bps,
+    quantity_in_stock = EXCLUDED.quantity_in_stock,
+    updated_at = CURRENT_TIMESTAMP
+WHERE (products.title, products.description, products.category, products.price_minor, products.currency, products.discount_bps, products.quantity_in_stock)
+  IS DISTINCT FROM (EXCLUDED.title, EXCLUDED.description, EXCLUDED.category, EXCLUDED.price_minor, EXCLUDED.currency, EXCLUDED.discount_bps, EXCLUDED.quantity_in_stock)
+RETURNING id, (xmax = 0)::boolean AS inserted;
+
+-- name: ListVariantsByProductIds :many
+SELECT * FROM product_variants
+WHERE product_id = ANY(sqlc.arg('product_ids')::int[])
+  AND archived_at IS NULL
+ORDER BY product_id, position, id;
+
+-- name: InsertProductVariant :one
+INSERT INTO product_variants (
+  product_id, sku, attributes, price_minor, quantity_in_stock, position
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: UpdateProductVariant :one
+UPDATE product_variants
+SET sku = $3,
+    attributes = $4,
+    price_minor = $5,
+    quantity_in_stock = $6,
+    position = $7,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND product_id = $2 AND archived_at IS NULL
+RETURNING *;
+
+-- name: ArchiveProductVariant :one
+UPDATE product_variants
+SET archived_at = COALESCE(archived_at, CURRENT_TIMESTAMP),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND product_id = $2
+RETURNING *;
+
+-- name: ListCategories :many
+SELECT * FROM categories
+ORDER BY position, name;
+
+-- name: GetCategoryBySlug :one
+SELECT * FROM categories
+WHERE slug = $1;
+
+-- name: InsertCategory :one
+INSERT INTO categories (
+  parent_id, slug, name, position
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetCategoryBreadcrumb :many
+-- the category and its ancestors, root first
+WITH RECURSIVE ancestors AS (
+  SELECT id, parent_id, 0 AS depth FROM categories WHERE categories.slug = sqlc.arg('slug')
+  UNION ALL
+  SELECT c.id, c.parent_id, a.depth + 1 FROM categories c JOIN ancestors a ON c.id = a.parent_id
+)
+SELECT categories.* FROM categories
+JOIN ancestors ON ancestors.id = categories.id
+ORDER BY ancestors.depth DESC;
+
+-- name: ListCategorySubtree :many
+-- the category and all of its descendants, parents before their children
+WITH RECURSIVE subtree AS (
+  SELECT id, 0 AS depth FROM categories WHERE categories.slug = sqlc.arg('slug')
+  UNION ALL
+  SELECT c.id, s.depth + 1 FROM categories c JOIN subtree s ON c.parent_id = s.id
+)
+SELECT categories.* FROM categories
+JOIN subtree ON subtree.id = categories.id
+ORDER BY subtree.depth, categories.position, categories.name;
+
+-- name: ListCategoryPaths :many
+-- the slugs from the root down to each category, search documents carry them
+-- so filtering on a category matches its descendants
+WITH RECURSIVE paths AS (
+  SELECT id, slug, ARRAY[slug]::text[] AS path FROM categories WHERE parent_id IS NULL
+  UNION ALL
+  SELECT c.id, c.slug, p.path || c.slug FROM categories c JOIN paths p ON c.parent_id = p.id
+)
+SELECT slug, path::text[] AS path FROM paths;
+
+-- name: InsertReservation :one
+-- returns no row when a reservation with the reference already exists
+INSERT INTO inventory_reservations (
+  reference, expires_at
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (reference) DO NOTHING
+RETURNING *;
+
+-- name: InsertReservationItem :one
+INSERT INTO inventory_reservation_items (
+  reservation_id, product_id, variant_id, quantity
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetReservation :one
+SELECT * FROM inventory_reservations
+WHERE id = $1;
+
+-- name: GetReservationByReference :one
+SELECT * FROM inventory_reservations
+WHERE reference = $1;
+
+-- name: ListReservationItems :many
+SELECT * FROM inventory_reservation_items
+WHERE reservation_id = $1
+ORDER BY id;
+
+-- name: ReserveProductStock :execrows
+-- holds the stock only if enough of it is available. Concurrent reservations
+-- of the product queue on its row and recheck the condition against what the
+-- one before them held. Products with live variants keep their stock on the
+-- variants.
+UPDATE products
+SET reserved_quantity = products.reserved_quantity + sqlc.arg('quantity')
+WHERE products.id = sqlc.arg('id')
+  AND products.archived_at IS NULL
+  AND products.quantity_in_stock - products.reserved_quantity >= sqlc.arg('quantity')
+  AND NOT EXISTS (
+    SELECT 1 FROM product_variants v
+    WHERE v.product_id = products.id AND v.archived_at IS NULL
+  );
+
+-- name: ReserveVariantStock :execrows
+UPDATE product_variants
+SET reserved_quantity = product_variants.reserved_quantity + sqlc.arg('quantity')
+WHERE product_variants.id = sqlc.arg('id')
+  AND product_variants.product_id = sqlc.arg('product_id')
+  AND product_variants.archived_at IS NULL
+  AND product_variants.quantity_in_stock - product_variants.reserved_quantity >= sqlc.arg('quantity')
+  AND EXISTS (
+    SELECT 1 FROM products p
+    WHERE p.id = product_variants.product_id AND p.archived_at IS NULL
+  );
+
+-- name: CommitReservation :one
+UPDATE inventory_reservations
+SET status = 'committed',
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'pending' AND expires_at > CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: ReleaseReservation :one
+UPDATE inventory_reservations
+SET status = 'released',
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'pending'
+RETURNING *;
+
+-- name: ExpireReservations :many
+-- SKIP LOCKED lets several reapers run at once and keeps them off
+-- reservations that are being committed or released
+UPDATE inventory_reservations
+SET status = 'expired',
+    updated_at = CURRENT_TIMESTAMP
+WHERE id IN (
+  SELECT id FROM inventory_reservations
+  WHERE status = 'pending' AND expires_at <= CURRENT_TIMESTAMP
+  ORDER BY expires_at
+  LIMIT $1
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING id;
+
+-- name: ReleaseReservedProducts :exec
+-- stops holding the stock of released and expired reservations
+UPDATE products
+SET reserved_quantity = products.reserved_quantity - reserved.quantity
+FROM (
+  SELECT product_id, SUM(quantity)::int AS quantity FROM inventory_reservation_items
+  WHERE reservation_id = ANY(sqlc.arg('reservation_ids')::uuid[]) AND variant_id IS NULL
+  GROUP BY product_id
+) reserved
+WHERE products.id = reserved.product_id;
+
+-- name: ReleaseReservedVariants :exec
+UPDATE product_variants
+SET reserved_quantity = product_variants.reserved_quantity - reserved.quantity
+FROM (
+  SELECT variant_id, SUM(quantity)::int AS quantity FROM inventory_reservation_items
+  WHERE reservation_id = ANY(sqlc.arg('reservation_ids')::uuid[]) AND variant_id IS NOT NULL
+  GROUP BY variant_id
+) reserved
+WHERE product_variants.id = reserved.variant_id;
+
+-- name: ShipReservedProducts :exec
+-- takes the stock of a committed reservation off the stock on hand. Stock set
+-- below what was held after the reservation was made stops at 0.
+UPDATE products
+SET quantity_in_stock = GREATEST(products.quantity_in_stock - reserved.quantity, 0),
+    reserved_quantity = products.reserved_quantity - reserved.quantity
+FROM (
+  SELECT product_id, SUM(quantity)::int AS quantity FROM inventory_reservation_items
+  WHERE reservation_id = sqlc.arg('reservation_id') AND variant_id IS NULL
+  GROUP BY product_id
+) reserved
+WHERE products.id = reserved.product_id;
+
+-- name: ShipReservedVariants :exec
+UPDATE product_variants
+SET quantity_in_stock = GREATEST(product_variants.quantity_in_stock - reserved.quantity, 0),
+    reserved_quantity = product_variants.reserved_quantity - reserved.quantity
+FROM (
+  SELECT variant_id, SUM(quantity)::int AS quantity FROM inventory_reservation_items
+  WHERE reservation_id = sqlc.arg('reservation_id') AND variant_id IS NOT NULL
+  GROUP BY variant_id
+) reserved
+WHERE product_variants.id = reserved.variant_id;
+
+-- name: InsertReview :one
+-- returns no row when the product doesn't exist or is archived
+INSERT INTO product_reviews (product_id, user_id, rating, title, body)
+SELECT products.id, sqlc.arg('user_id'), sqlc.arg('rating'), sqlc.arg('title'), sqlc.arg('body')
+FROM products
+WHERE products.id = sqlc.arg('product_id') AND products.archived_at IS NULL
+RETURNING *;
+
+-- name: UpdateReview :one
+-- a rejected review goes back to pending once its author edits it
+UPDATE product_reviews
+SET rating = $3,
+    title = $4,
+    body = $5,
+    status = CASE WHEN status = 'rejected' THEN 'pending' ELSE status END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE product_id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: DeleteReview :one
+DELETE FROM product_reviews
+WHERE product_id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: GetUserReview :one
+SELECT * FROM product_reviews
+WHERE product_id = $1 AND user_id = $2;
+
+-- name: ListReviews :many
+-- sort is newest, oldest, rating_desc or rating_asc
+SELECT * FROM product_reviews
+WHERE (sqlc.narg('product_id')::int IS NULL OR product_id = sqlc.narg('product_id'))
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+ORDER BY
+  CASE WHEN sqlc.arg('sort')::text = 'rating_desc' THEN rating END DESC,
+  CASE WHEN sqlc.arg('sort')::text = 'rating_asc' THEN rating END ASC,
+  CASE WHEN sqlc.arg('sort')::text = 'oldest' THEN created_at END ASC,
+  CASE WHEN sqlc.arg('sort')::text = 'oldest' THEN id END ASC,
+  created_at DESC,
+  id DESC
+LIMIT sqlc.arg('page_size') OFFSET sqlc.arg('page_offset');
+
+-- name: SetReviewStatus :one
+UPDATE product_reviews
+SET status = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: InsertSearchEvents :copyfrom
+INSERT INTO search_events (
+  id, query, filters, sort, hit_count, latency_ms, user_id, guest_id, degraded, created_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
 );
+
+-- name: InsertSearchClicks :copyfrom
+INSERT INTO search_clicks (
+  search_id, product_id, position, created_at
+) VALUES (
+  $1, $2, $3, $4
+);
+
+-- name: TopSearchQueries :many
+SELECT query,
+       COUNT(*)::bigint AS searches,
+       AVG(hit_count)::float8 AS avg_hits,
+       AVG(latency_ms)::float8 AS avg_latency_ms
+FROM search_events
+WHERE created_at >= sqlc.arg('since') AND query <> ''
+GROUP BY query
+ORDER BY searches DESC, query
+LIMIT sqlc.arg('max_results');
+
+-- name: ZeroResultSearchQueries :many
+-- degraded searches are left out, the fallback misses what the index finds
+SELECT query,
+       COUNT(*)::bigint AS searches,
+       MAX(created_at)::timestamptz AS last_searched_at
+FROM search_events
+WHERE created_at >= sqlc.arg('since') AND query <> '' AND hit_count = 0 AND NOT degraded
+GROUP BY query
+ORDER BY searches DESC, query
+LIMIT sqlc.arg('max_results');
+
+-- name: SearchClickThrough :many
+-- only searches that found something can be clicked through
+SELECT e.query,
+       COUNT(*)::bigint AS searches,
+       COUNT(c.search_id)::bigint AS clicked_searches,
+       COALESCE(SUM(c.clicks), 0)::bigint AS clicks,
+       COALESCE(AVG(c.first_position), 0)::float8 AS avg_first_position
+FROM search_events e
+LEFT JOIN (
+  SELECT search_id, COUNT(*) AS clicks, MIN(position) AS first_position
+  FROM search_clicks
+  WHERE search_clicks.created_at >= sqlc.arg('since')
+  GROUP BY search_id
+) c ON c.search_id = e.id
+WHERE e.created_at >= sqlc.arg('since') AND e.query <> '' AND e.hit_count > 0
+GROUP BY e.query
+ORDER BY searches DESC, e.query
+LIMIT sqlc.arg('max_results');
+
+-- name: DeleteSearchEventsBefore :execrows
+DELETE FROM search_events
+WHERE created_at < $1;
+
+-- name: DeleteSearchClicksBefore :execrows
+DELETE FROM search_clicks
+WHERE created_at < $1;
+
+-- name: InsertSearchSettings :one
+INSERT INTO search_settings (
+  settings, note, created_by
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: GetSearchSettings :one
+SELECT * FROM search_settings
+WHERE version = $1;
+
+-- name: ListSearchSettings :many
+SELECT * FROM search_settings
+ORDER BY version DESC
+LIMIT $1;
+
+-- name: GetActiveSearchSettings :one
+SELECT * FROM search_settings
+WHERE applied_at IS NOT NULL
+ORDER BY applied_at DESC, version DESC
+LIMIT 1;
+
+-- name: MarkSearchSettingsApplied :one
+-- the time of the update rather than of the transaction, a transaction that
+-- started earlier but waited on the lock still marks its version the latest
+UPDATE search_settings
+SET applied_at = clock_timestamp()
+WHERE version = $1
+RETURNING *;
+
+-- name: LockSearchSettings :exec
+-- held until the end of the transaction, so settings are applied one at a
+-- time and a reindex swaps in an index with the active settings
+SELECT pg_advisory_xact_lock(hashtext('search_settings'));
+
+-- name: ListUserReviewRatings :many
+-- the user's own ratings whatever the review's status, to show them what
+-- they rated
+SELECT product_id, rating FROM product_reviews
+WHERE user_id = sqlc.arg('user_id') AND product_id = ANY(sqlc.arg('product_ids')::int[]);
@@ -0,0 +1,86 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: copyfrom.go
+
+package repository
+
+import (
+	"context"
+)
+
+// iteratorForInsertSearchClicks implements pgx.CopyFromSource.
+type iteratorForInsertSearchClicks struct {
+	rows                 []InsertSearchClicksParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForInsertSearchClicks) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForInsertSearchClicks) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].SearchID,
+		r.rows[0].ProductID,
+		r.rows[0].Position,
+		r.rows[0].CreatedAt,
+	}, nil
+}
+
+func (r iteratorForInsertSearchClicks) Err() error {
+	return nil
+}
+
+func (q *Queries) InsertSearchClicks(ctx context.Context, arg []InsertSearchClicksParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"search_clicks"}, []string{"search_id", "product_id", "position", "created_at"}, &iteratorForInsertSearchClicks{rows: arg})
+}
+
+// iteratorForInsertSearchEvents implements pgx.CopyFromSource.
+type iteratorForInsertSearchEvents struct {
+	rows                 []InsertSearchEventsParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForInsertSearchEvents) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForInsertSearchEvents) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].ID,
+		r.rows[0].Query,
+		r.rows[0].Filters,
+		r.rows[0].Sort,
+		r.rows[0].HitCount,
+		r.rows[0].LatencyMs,
+		r.rows[0].UserID,
+		r.rows[0].GuestID,
+		r.rows[0].Degraded,
+		r.rows[0].CreatedAt,
+	}, nil
+}
+
+func (r iteratorForInsertSearchEvents) Err() error {
+	return nil
+}
+
+func (q *Queries) InsertSearchEvents(ctx context.Context, arg []InsertSearchEventsParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"search_events"}, []string{"id", "query", "filters", "sort", "hit_count", "latency_ms", "user_id", "guest_id", "degraded", "created_at"}, &iteratorForInsertSearchEvents{rows: arg})
+}
@@ -4,12 +4,137 @@
 
 package repository
 
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/lmnzx/slopify/pkg/money"
+)
+
+type Category struct {
+	ID        int32       `json:"id"`
+	ParentID  pgtype.Int4 `json:"parent_id"`
+	Slug      string      `json:"slug"`
+	Name      string      `json:"name"`
+	Position  int32       `json:"position"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+type InventoryReservation struct {
+	ID        uuid.UUID `json:"id"`
+	Reference string    `json:"reference"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type InventoryReservationItem struct {
+	ID            int32       `json:"id"`
+	ReservationID uuid.UUID   `json:"reservation_id"`
+	ProductID     int32       `json:"product_id"`
+	VariantID     pgtype.Int4 `json:"variant_id"`
+	Quantity      int32       `json:"quantity"`
+}
+
 type Product struct {
-	ID              int32   `json:"id"`
-	Title           string  `json:"title"`
-	Description     string  `json:"description"`
-	Category        string  `json:"category"`
-	Price           float32 `json:"price"`
-	Discount        float32 `json:"discount"`
-	QuantityInStock int32   `json:"quantity_in_stock"`
+	ID               int32              `json:"id"`
+	Title            string             `json:"title"`
+	Description      string             `json:"description"`
+	Category         string             `json:"category"`
+	QuantityInStock  int32              `json:"quantity_in_stock"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+	ArchivedAt       pgtype.Timestamptz `json:"archived_at"`
+	Sku              pgtype.Text        `json:"sku"`
+	PriceMinor       money.Minor        `json:"price_minor"`
+	Currency         money.Currency     `json:"currency"`
+	DiscountBps      money.BasisPoints  `json:"discount_bps"`
+	RatingCount      int32              `json:"rating_count"`
+	RatingSum        int32              `json:"rating_sum"`
+	ReservedQuantity int32              `json:"reserved_quantity"`
+}
+
+type ProductIndexQueue struct {
+	ID        int64     `json:"id"`
+	ProductID int32     `json:"product_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ProductReview struct {
+	ID        int32     `json:"id"`
+	ProductID int32     `json:"product_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Rating    int32     `json:"rating"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ProductVariant struct {
+	ID               int32              `json:"id"`
+	ProductID        int32              `json:"product_id"`
+	Sku              string             `json:"sku"`
+	Attributes       json.RawMessage    `json:"attributes"`
+	PriceMinor       *money.Minor       `json:"price_minor"`
+	QuantityInStock  int32              `json:"quantity_in_stock"`
+	Position         int32              `json:"position"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+	ArchivedAt       pgtype.Timestamptz `json:"archived_at"`
+	ReservedQuantity int32              `json:"reserved_quantity"`
+}
+
+type ProductWithSearchVector struct {
+	ID               int32              `json:"id"`
+	Title            string             `json:"title"`
+	Description      string             `json:"description"`
+	Category         string             `json:"category"`
+	QuantityInStock  int32              `json:"quantity_in_stock"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+	ArchivedAt       pgtype.Timestamptz `json:"archived_at"`
+	Sku              pgtype.Text        `json:"sku"`
+	PriceMinor       money.Minor        `json:"price_minor"`
+	Currency         money.Currency     `json:"currency"`
+	DiscountBps      money.BasisPoints  `json:"discount_bps"`
+	RatingCount      int32              `json:"rating_count"`
+	RatingSum        int32              `json:"rating_sum"`
+	ReservedQuantity int32              `json:"reserved_quantity"`
+	SearchVector     interface{}        `json:"search_vector"`
+}
+
+type SearchClick struct {
+	ID        int64     `json:"id"`
+	SearchID  uuid.UUID `json:"search_id"`
+	ProductID int32     `json:"product_id"`
+	Position  int32     `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SearchEvent struct {
+	ID        uuid.UUID   `json:"id"`
+	Query     string      `json:"query"`
+	Filters   []string    `json:"filters"`
+	Sort      string      `json:"sort"`
+	HitCount  int64       `json:"hit_count"`
+	LatencyMs int32       `json:"latency_ms"`
+	UserID    pgtype.UUID `json:"user_id"`
+	GuestID   pgtype.UUID `json:"guest_id"`
+	Degraded  bool        `json:"degraded"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+type SearchSetting struct {
+	Version   int32              `json:"version"`
+	Settings  json.RawMessage    `json:"settings"`
+	Note      string             `json:"note"`
+	CreatedBy string             `json:"created_by"`
+	CreatedAt time.Time          `json:"created_at"`
+	AppliedAt pgtype.Timestamptz `json:"applied_at"`
 }
@@ -7,24 +7,168 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/lmnzx/slopify/pkg/money"
+)
+
+const adoptProductSKU = `-- name: AdoptProductSKU :execrows
+UPDATE products
+SET sku = $1::text
+WHERE id = (
+  SELECT p.id FROM products p
+  WHERE p.sku IS NULL
+    AND p.archived_at IS NULL
+    AND p.title = $2
+    AND p.category = $3
+  ORDER BY p.id
+  LIMIT 1
 )
+AND NOT EXISTS (SELECT 1 FROM products taken WHERE taken.sku = $1::text)
+`
+
+type AdoptProductSKUParams struct {
+	Sku      string `json:"sku"`
+	Title    string `json:"title"`
+	Category string `json:"category"`
+}
+
+// products seeded before skus existed have none, the first one with the
+// record's title and category takes the sku so the upsert updates it rather
+// than inserting a copy on every import
+func (q *Queries) AdoptProductSKU(ctx context.Context, arg AdoptProductSKUParams) (int64, error) {
+	result, err := q.db.Exec(ctx, adoptProductSKU, arg.Sku, arg.Title, arg.Category)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const archiveProduct = `-- name: ArchiveProduct :one
+UPDATE products
+SET archived_at = COALESCE(archived_at, CURRENT_TIMESTAMP),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, title, description, category, quantity_in_stock, created_at, updated_at, archived_at,
+          sku, price_minor, currency, discount_bps, rating_count, rating_sum, reserved_quantity
+`
+
+type ArchiveProductRow struct {
+	ID               int32              `json:"id"`
+	Title            string             `json:"title"`
+	Description      string             `json:"description"`
+	Category         string             `json:"category"`
+	QuantityInStock  int32              `json:"quantity_in_stock"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+	ArchivedAt       pgtype.Timestamptz `json:"archived_at"`
+	Sku              pgtype.Text        `json:"sku"`
+	PriceMinor       money.Minor        `json:"price_minor"`
+	Currency         money.Currency     `json:"currency"`
+	DiscountBps      money.BasisPoints  `json:"discount_bps"`
+	RatingCount      int32              `json:"rating_count"`
+	RatingSum        int32              `json:"rating_sum"`
+	ReservedQuantity int32              `json:"reserved_quantity"`
+}
+
+// returns the columns of product_rows, search_vector is left out
+func (q *Queries) ArchiveProduct(ctx context.Context, id int32) (ArchiveProductRow, error) {
+	row := q.db.QueryRow(ctx, archiveProduct, id)
+	var i ArchiveProductRow
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.Category,
+		&i.QuantityInStock,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ArchivedAt,
+		&i.Sku,
+		&i.PriceMinor,
+		&i.Currency,
+		&i.DiscountBps,
+		&i.RatingCount,
+		&i.RatingSum,
+		&i.ReservedQuantity,
+	)
+	return i, err
+}
+
+const archiveProductVariant = `-- name: ArchiveProductVariant :one
+UPDATE product_variants
+SET archived_at = COALESCE(archived_at, CURRENT_TIMESTAMP),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND product_id = $2
+RETURNING id, product_id, sku, attributes, price_minor, quantity_in_stock, position, created_at, updated_at, archived_at, reserved_quantity
+`
+
+type ArchiveProductVariantParams struct {
+	ID        int32 `json:"id"`
+	ProductID int32 `json:"product_id"`
+}
+
+func (q *Queries) ArchiveProductVariant(ctx context.Context, arg ArchiveProductVariantParams) (ProductVariant, error) {
+	row := q.db.QueryRow(ctx, archiveProductVariant, arg.ID, arg.ProductID)
+	var i ProductVariant
+	err := row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.Sku,
+		&i.Attributes,
+		&i.PriceMinor,
+		&i.QuantityInStock,
+		&i.Position,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ArchivedAt,
+		&i.ReservedQuantity,
+	)
+	return i, err
+}
+
+const commitReservation = `-- name: CommitReservation :one
+UPDATE inventory_reservations
+SET status = 'committed',
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'pending' AND expires_at > CURRENT_TIMESTAMP
+RETURNING id, reference, status, expires_at, created_at, updated_at
+`
+
+func (q *Queries) CommitReservation(ctx context.Context, id uuid.UUID) (InventoryReservation, error) {
+	row := q.db.QueryRow(ctx, commitReservation, id)
+	var i InventoryReservation
+	err := row.Scan(
+		&i.ID,
+		&i.Reference,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
 
 const createProduct = `-- name: CreateProduct :exec
 INSERT INTO products (
-  id, title, description, category, price, discount, quantity_in_stock
+  id, title, description, category, price_minor, currency, discount_bps, quantity_in_stock
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7
+  $1, $2, $3, $4, $5, $6, $7, $8
 )
 `
 
 type CreateProductParams struct {
-	ID              int32   `json:"id"`
-	Title           string  `json:"title"`
-	Description     string  `json:"description"`
-	Category        string  `json:"category"`
-	Price           float32 `json:"price"`
-	Discount        float32 `json:"discount"`
-	QuantityInStock int32   `json:"quantity_in_stock"`
+	ID              int32             `json:"id"`
+	Title           string            `json:"title"`
+	Description     string            `json:"description"`
+	Category        string            `json:"category"`
+	PriceMinor      money.Minor       `json:"price_minor"`
+	Currency        money.Currency    `json:"currency"`
+	DiscountBps     money.BasisPoints `json:"discount_bps"`
+	QuantityInStock int32             `json:"quantity_in_stock"`
 }
 
 func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) error {
@@ -33,19 +177,248 @@ func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) er
 		arg.Title,
 		arg.Description,
 		arg.Category,
-		arg.Price,
-		arg.Discount,
+		arg.PriceMinor,
+		arg.Currency,
+		arg.DiscountBps,
 		arg.QuantityInStock,
 	)
 	return err
 }
 
-const listAllProducts = `-- name: ListAllProducts :many
-SELECT id, title, description, category, price, discount, quantity_in_stock FROM products
+const deleteProductIndexQueue = `-- name: DeleteProductIndexQueue :exec
+DELETE FROM product_index_queue
+WHERE id = ANY($1::bigint[])
 `
 
-func (q *Queries) ListAllProducts(ctx context.Context) ([]Product, error) {
-	rows, err := q.db.Query(ctx, listAllProducts)
+func (q *Queries) DeleteProductIndexQueue(ctx context.Context, ids []int64) error {
+	_, err := q.db.Exec(ctx, deleteProductIndexQueue, ids)
+	return err
+}
+
+const deleteReview = `-- name: DeleteReview :one
+DELETE FROM product_reviews
+WHERE product_id = $1 AND user_id = $2
+RETURNING id, product_id, user_id, rating, title, body, status, created_at, updated_at
+`
+
+type DeleteReviewParams struct {
+	ProductID int32     `json:"product_id"`
+	UserID    uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) DeleteReview(ctx context.Context, arg DeleteReviewParams) (ProductReview, error) {
+	row := q.db.QueryRow(ctx, deleteReview, arg.ProductID, arg.UserID)
+	var i ProductReview
+	err := row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.UserID,
+		&i.Rating,
+		&i.Title,
+		&i.Body,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteSearchClicksBefore = `-- name: DeleteSearchClicksBefore :execrows
+DELETE FROM search_clicks
+WHERE created_at < $1
+`
+
+func (q *Queries) DeleteSearchClicksBefore(ctx context.Context, createdAt time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteSearchClicksBefore, createdAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteSearchEventsBefore = `-- name: DeleteSearchEventsBefore :execrows
+DELETE FROM search_events
+WHERE created_at < $1
+`
+
+func (q *Queries) DeleteSearchEventsBefore(ctx context.Context, createdAt time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteSearchEventsBefore, createdAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const expireReservations = `-- name: ExpireReservations :many
+UPDATE inventory_reservations
+SET status = 'expired',
+    updated_at = CURRENT_TIMESTAMP
+WHERE id IN (
+  SELECT id FROM inventory_reservations
+  WHERE status = 'pending' AND expires_at <= CURRENT_TIMESTAMP
+  ORDER BY expires_at
+  LIMIT $1
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING id
+`
+
+// SKIP LOCKED lets several reapers run at once and keeps them off
+// reservations that are being committed or released
+func (q *Queries) ExpireReservations(ctx context.Context, limit int32) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, expireReservations, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getActiveSearchSettings = `-- name: GetActiveSearchSettings :one
+SELECT version, settings, note, created_by, created_at, applied_at FROM search_settings
+WHERE applied_at IS NOT NULL
+ORDER BY applied_at DESC, version DESC
+LIMIT 1
+`
+
+func (q *Queries) GetActiveSearchSettings(ctx context.Context) (SearchSetting, error) {
+	row := q.db.QueryRow(ctx, getActiveSearchSettings)
+	var i SearchSetting
+	err := row.Scan(
+		&i.Version,
+		&i.Settings,
+		&i.Note,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.AppliedAt,
+	)
+	return i, err
+}
+
+const getCategoryBreadcrumb = `-- name: GetCategoryBreadcrumb :many
+WITH RECURSIVE ancestors AS (
+  SELECT id, parent_id, 0 AS depth FROM categories WHERE categories.slug = $1
+  UNION ALL
+  SELECT c.id, c.parent_id, a.depth + 1 FROM categories c JOIN ancestors a ON c.id = a.parent_id
+)
+SELECT categories.id, categories.parent_id, categories.slug, categories.name, categories.position, categories.created_at, categories.updated_at FROM categories
+JOIN ancestors ON ancestors.id = categories.id
+ORDER BY ancestors.depth DESC
+`
+
+// the category and its ancestors, root first
+func (q *Queries) GetCategoryBreadcrumb(ctx context.Context, slug string) ([]Category, error) {
+	rows, err := q.db.Query(ctx, getCategoryBreadcrumb, slug)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Category
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(
+			&i.ID,
+			&i.ParentID,
+			&i.Slug,
+			&i.Name,
+			&i.Position,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCategoryBySlug = `-- name: GetCategoryBySlug :one
+SELECT id, parent_id, slug, name, position, created_at, updated_at FROM categories
+WHERE slug = $1
+`
+
+func (q *Queries) GetCategoryBySlug(ctx context.Context, slug string) (Category, error) {
+	row := q.db.QueryRow(ctx, getCategoryBySlug, slug)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.ParentID,
+		&i.Slug,
+		&i.Name,
+		&i.Position,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getProduct = `-- name: GetProduct :one
+SELECT id, title, description, category, quantity_in_stock, created_at, updated_at, archived_at, sku, price_minor, currency, discount_bps, rating_count, rating_sum, reserved_quantity FROM product_rows
+WHERE id = $1
+`
+
+func (q *Queries) GetProduct(ctx context.Context, id int32) (Product, error) {
+	row := q.db.QueryRow(ctx, getProduct, id)
+	var i Product
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.Category,
+		&i.QuantityInStock,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ArchivedAt,
+		&i.Sku,
+		&i.PriceMinor,
+		&i.Currency,
+		&i.DiscountBps,
+		&i.RatingCount,
+		&i.RatingSum,
+		&i.ReservedQuantity,
+	)
+	return i, err
+}
+
+const getProductIndexQueueStats = `-- name: GetProductIndexQueueStats :one
+SELECT COUNT(*)::bigint AS pending,
+       COALESCE(EXTRACT(EPOCH FROM CURRENT_TIMESTAMP - MIN(created_at)), 0)::float8 AS lag_seconds
+FROM product_index_queue
+`
+
+type GetProductIndexQueueStatsRow struct {
+	Pending    int64   `json:"pending"`
+	LagSeconds float64 `json:"lag_seconds"`
+}
+
+func (q *Queries) GetProductIndexQueueStats(ctx context.Context) (GetProductIndexQueueStatsRow, error) {
+	row := q.db.QueryRow(ctx, getProductIndexQueueStats)
+	var i GetProductIndexQueueStatsRow
+	err := row.Scan(&i.Pending, &i.LagSeconds)
+	return i, err
+}
+
+const getProductsByIds = `-- name: GetProductsByIds :many
+SELECT id, title, description, category, quantity_in_stock, created_at, updated_at, archived_at, sku, price_minor, currency, discount_bps, rating_count, rating_sum, reserved_quantity FROM product_rows
+WHERE id = ANY($1::int[])
+`
+
+func (q *Queries) GetProductsByIds(ctx context.Context, ids []int32) ([]Product, error) {
+	rows, err := q.db.Query(ctx, getProductsByIds, ids)
 	if err != nil {
 		return nil, err
 	}
@@ -58,9 +431,17 @@ func (q *Queries) ListAllProducts(ctx context.Context) ([]Product, error) {
 			&i.Title,
 			&i.Description,
 			&i.Category,
-			&i.Price,
-			&i.Discount,
 			&i.QuantityInStock,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ArchivedAt,
+			&i.Sku,
+			&i.PriceMinor,
+			&i.Currency,
+			&i.DiscountBps,
+			&i.RatingCount,
+			&i.RatingSum,
+			&i.ReservedQuantity,
 		); err != nil {
 			return nil, err
 		}
@@ -72,13 +453,399 @@ func (q *Queries) ListAllProducts(ctx context.Context) ([]Product, error) {
 	return items, nil
 }
 
-const listProductsByCategory = `-- name: ListProductsByCategory :many
-SELECT id, title, description, category, price, discount, quantity_in_stock FROM products
-WHERE category = $1
+const getReservation = `-- name: GetReservation :one
+SELECT id, reference, status, expires_at, created_at, updated_at FROM inventory_reservations
+WHERE id = $1
+`
+
+func (q *Queries) GetReservation(ctx context.Context, id uuid.UUID) (InventoryReservation, error) {
+	row := q.db.QueryRow(ctx, getReservation, id)
+	var i InventoryReservation
+	err := row.Scan(
+		&i.ID,
+		&i.Reference,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getReservationByReference = `-- name: GetReservationByReference :one
+SELECT id, reference, status, expires_at, created_at, updated_at FROM inventory_reservations
+WHERE reference = $1
+`
+
+func (q *Queries) GetReservationByReference(ctx context.Context, reference string) (InventoryReservation, error) {
+	row := q.db.QueryRow(ctx, getReservationByReference, reference)
+	var i InventoryReservation
+	err := row.Scan(
+		&i.ID,
+		&i.Reference,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getSearchSettings = `-- name: GetSearchSettings :one
+SELECT version, settings, note, created_by, created_at, applied_at FROM search_settings
+WHERE version = $1
+`
+
+func (q *Queries) GetSearchSettings(ctx context.Context, version int32) (SearchSetting, error) {
+	row := q.db.QueryRow(ctx, getSearchSettings, version)
+	var i SearchSetting
+	err := row.Scan(
+		&i.Version,
+		&i.Settings,
+		&i.Note,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.AppliedAt,
+	)
+	return i, err
+}
+
+const getUserReview = `-- name: GetUserReview :one
+SELECT id, product_id, user_id, rating, title, body, status, created_at, updated_at FROM product_reviews
+WHERE product_id = $1 AND user_id = $2
+`
+
+type GetUserReviewParams struct {
+	ProductID int32     `json:"product_id"`
+	UserID    uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) GetUserReview(ctx context.Context, arg GetUserReviewParams) (ProductReview, error) {
+	row := q.db.QueryRow(ctx, getUserReview, arg.ProductID, arg.UserID)
+	var i ProductReview
+	err := row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.UserID,
+		&i.Rating,
+		&i.Title,
+		&i.Body,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const insertCategory = `-- name: InsertCategory :one
+INSERT INTO categories (
+  parent_id, slug, name, position
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING id, parent_id, slug, name, position, created_at, updated_at
+`
+
+type InsertCategoryParams struct {
+	ParentID pgtype.Int4 `json:"parent_id"`
+	Slug     string      `json:"slug"`
+	Name     string      `json:"name"`
+	Position int32       `json:"position"`
+}
+
+func (q *Queries) InsertCategory(ctx context.Context, arg InsertCategoryParams) (Category, error) {
+	row := q.db.QueryRow(ctx, insertCategory,
+		arg.ParentID,
+		arg.Slug,
+		arg.Name,
+		arg.Position,
+	)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.ParentID,
+		&i.Slug,
+		&i.Name,
+		&i.Position,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const insertProduct = `-- name: InsertProduct :one
+INSERT INTO products (
+  title, description, category, price_minor, currency, discount_bps, quantity_in_stock
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING id, title, description, category, quantity_in_stock, created_at, updated_at, archived_at,
+          sku, price_minor, currency, discount_bps, rating_count, rating_sum, reserved_quantity
+`
+
+type InsertProductParams struct {
+	Title           string            `json:"title"`
+	Description     string            `json:"description"`
+	Category        string            `json:"category"`
+	PriceMinor      money.Minor       `json:"price_minor"`
+	Currency        money.Currency    `json:"currency"`
+	DiscountBps     money.BasisPoints `json:"discount_bps"`
+	QuantityInStock int32             `json:"quantity_in_stock"`
+}
+
+type InsertProductRow struct {
+	ID               int32              `json:"id"`
+	Title            string             `json:"title"`
+	Description      string             `json:"description"`
+	Category         string             `json:"category"`
+	QuantityInStock  int32              `json:"quantity_in_stock"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+	ArchivedAt       pgtype.Timestamptz `json:"archived_at"`
+	Sku              pgtype.Text        `json:"sku"`
+	PriceMinor       money.Minor        `json:"price_minor"`
+	Currency         money.Currency     `json:"currency"`
+	DiscountBps      money.BasisPoints  `json:"discount_bps"`
+	RatingCount      int32              `json:"rating_count"`
+	RatingSum        int32              `json:"rating_sum"`
+	ReservedQuantity int32              `json:"reserved_quantity"`
+}
+
+// returns the columns of product_rows, search_vector is left out
+func (q *Queries) InsertProduct(ctx context.Context, arg InsertProductParams) (InsertProductRow, error) {
+	row := q.db.QueryRow(ctx, insertProduct,
+		arg.Title,
+		arg.Description,
+		arg.Category,
+		arg.PriceMinor,
+		arg.Currency,
+		arg.DiscountBps,
+		arg.QuantityInStock,
+	)
+	var i InsertProductRow
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.Category,
+		&i.QuantityInStock,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ArchivedAt,
+		&i.Sku,
+		&i.PriceMinor,
+		&i.Currency,
+		&i.DiscountBps,
+		&i.RatingCount,
+		&i.RatingSum,
+		&i.ReservedQuantity,
+	)
+	return i, err
+}
+
+const insertProductVariant = `-- name: InsertProductVariant :one
+INSERT INTO product_variants (
+  product_id, sku, attributes, price_minor, quantity_in_stock, position
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING id, product_id, sku, attributes, price_minor, quantity_in_stock, position, created_at, updated_at, archived_at, reserved_quantity
+`
+
+type InsertProductVariantParams struct {
+	ProductID       int32           `json:"product_id"`
+	Sku             string          `json:"sku"`
+	Attributes      json.RawMessage `json:"attributes"`
+	PriceMinor      *money.Minor    `json:"price_minor"`
+	QuantityInStock int32           `json:"quantity_in_stock"`
+	Position        int32           `json:"position"`
+}
+
+func (q *Queries) InsertProductVariant(ctx context.Context, arg InsertProductVariantParams) (ProductVariant, error) {
+	row := q.db.QueryRow(ctx, insertProductVariant,
+		arg.ProductID,
+		arg.Sku,
+		arg.Attributes,
+		arg.PriceMinor,
+		arg.QuantityInStock,
+		arg.Position,
+	)
+	var i ProductVariant
+	err := row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.Sku,
+		&i.Attributes,
+		&i.PriceMinor,
+		&i.QuantityInStock,
+		&i.Position,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ArchivedAt,
+		&i.ReservedQuantity,
+	)
+	return i, err
+}
+
+const insertReservation = `-- name: InsertReservation :one
+INSERT INTO inventory_reservations (
+  reference, expires_at
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (reference) DO NOTHING
+RETURNING id, reference, status, expires_at, created_at, updated_at
+`
+
+type InsertReservationParams struct {
+	Reference string    `json:"reference"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// returns no row when a reservation with the reference already exists
+func (q *Queries) InsertReservation(ctx context.Context, arg InsertReservationParams) (InventoryReservation, error) {
+	row := q.db.QueryRow(ctx, insertReservation, arg.Reference, arg.ExpiresAt)
+	var i InventoryReservation
+	err := row.Scan(
+		&i.ID,
+		&i.Reference,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const insertReservationItem = `-- name: InsertReservationItem :one
+INSERT INTO inventory_reservation_items (
+  reservation_id, product_id, variant_id, quantity
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING id, reservation_id, product_id, variant_id, quantity
+`
+
+type InsertReservationItemParams struct {
+	ReservationID uuid.UUID   `json:"reservation_id"`
+	ProductID     int32       `json:"product_id"`
+	VariantID     pgtype.Int4 `json:"variant_id"`
+	Quantity      int32       `json:"quantity"`
+}
+
+func (q *Queries) InsertReservationItem(ctx context.Context, arg InsertReservationItemParams) (InventoryReservationItem, error) {
+	row := q.db.QueryRow(ctx, insertReservationItem,
+		arg.ReservationID,
+		arg.ProductID,
+		arg.VariantID,
+		arg.Quantity,
+	)
+	var i InventoryReservationItem
+	err := row.Scan(
+		&i.ID,
+		&i.ReservationID,
+		&i.ProductID,
+		&i.VariantID,
+		&i.Quantity,
+	)
+	return i, err
+}
+
+const insertReview = `-- name: InsertReview :one
+INSERT INTO product_reviews (product_id, user_id, rating, title, body)
+SELECT products.id, $1, $2, $3, $4
+FROM products
+WHERE products.id = $5 AND products.archived_at IS NULL
+RETURNING id, product_id, user_id, rating, title, body, status, created_at, updated_at
+`
+
+type InsertReviewParams struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Rating    int32     `json:"rating"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	ProductID int32     `json:"product_id"`
+}
+
+// returns no row when the product doesn't exist or is archived
+func (q *Queries) InsertReview(ctx context.Context, arg InsertReviewParams) (ProductReview, error) {
+	row := q.db.QueryRow(ctx, insertReview,
+		arg.UserID,
+		arg.Rating,
+		arg.Title,
+		arg.Body,
+		arg.ProductID,
+	)
+	var i ProductReview
+	err := row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.UserID,
+		&i.Rating,
+		&i.Title,
+		&i.Body,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+type InsertSearchClicksParams struct {
+	SearchID  uuid.UUID `json:"search_id"`
+	ProductID int32     `json:"product_id"`
+	Position  int32     `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type InsertSearchEventsParams struct {
+	ID        uuid.UUID   `json:"id"`
+	Query     string      `json:"query"`
+	Filters   []string    `json:"filters"`
+	Sort      string      `json:"sort"`
+	HitCount  int64       `json:"hit_count"`
+	LatencyMs int32       `json:"latency_ms"`
+	UserID    pgtype.UUID `json:"user_id"`
+	GuestID   pgtype.UUID `json:"guest_id"`
+	Degraded  bool        `json:"degraded"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+const insertSearchSettings = `-- name: InsertSearchSettings :one
+INSERT INTO search_settings (
+  settings, note, created_by
+) VALUES (
+  $1, $2, $3
+)
+RETURNING version, settings, note, created_by, created_at, applied_at
+`
+
+type InsertSearchSettingsParams struct {
+	Settings  json.RawMessage `json:"settings"`
+	Note      string          `json:"note"`
+	CreatedBy string          `json:"created_by"`
+}
+
+func (q *Queries) InsertSearchSettings(ctx context.Context, arg InsertSearchSettingsParams) (SearchSetting, error) {
+	row := q.db.QueryRow(ctx, insertSearchSettings, arg.Settings, arg.Note, arg.CreatedBy)
+	var i SearchSetting
+	err := row.Scan(
+		&i.Version,
+		&i.Settings,
+		&i.Note,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.AppliedAt,
+	)
+	return i, err
+}
+
+const listAllProducts = `-- name: ListAllProducts :many
+SELECT id, title, description, category, quantity_in_stock, created_at, updated_at, archived_at, sku, price_minor, currency, discount_bps, rating_count, rating_sum, reserved_quantity FROM product_rows
 `
 
-func (q *Queries) ListProductsByCategory(ctx context.Context, category string) ([]Product, error) {
-	rows, err := q.db.Query(ctx, listProductsByCategory, category)
+func (q *Queries) ListAllProducts(ctx context.Context) ([]Product, error) {
+	rows, err := q.db.Query(ctx, listAllProducts)
 	if err != nil {
 		return nil, err
 	}
@@ -91,9 +858,50 @@ func (q *Queries) ListProductsByCategory(ctx context.Context, category string) (
 			&i.Title,
 			&i.Description,
 			&i.Category,
-			&i.Price,
-			&i.Discount,
 			&i.QuantityInStock,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ArchivedAt,
+			&i.Sku,
+			&i.PriceMinor,
+			&i.Currency,
+			&i.DiscountBps,
+			&i.RatingCount,
+			&i.RatingSum,
+			&i.ReservedQuantity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCategories = `-- name: ListCategories :many
+SELECT id, parent_id, slug, name, position, created_at, updated_at FROM categories
+ORDER BY position, name
+`
+
+func (q *Queries) ListCategories(ctx context.Context) ([]Category, error) {
+	rows, err := q.db.Query(ctx, listCategories)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Category
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(
+			&i.ID,
+			&i.ParentID,
+			&i.Slug,
+			&i.Name,
+			&i.Position,
+			&i.CreatedAt,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -104,3 +912,1095 @@ func (q *Queries) ListProductsByCategory(ctx context.Context, category string) (
 	}
 	return items, nil
 }
+
+const listCategoryPaths = `-- name: ListCategoryPaths :many
+WITH RECURSIVE paths AS (
+  SELECT id, slug, ARRAY[slug]::text[] AS path FROM categories WHERE parent_id IS NULL
+  UNION ALL
+  SELECT c.id, c.slug, p.path || c.slug FROM categories c JOIN paths p ON c.parent_id = p.id
+)
+SELECT slug, path::text[] AS path FROM paths
+`
+
+type ListCategoryPathsRow struct {
+	Slug string   `json:"slug"`
+	Path []string `json:"path"`
+}
+
+// the slugs from the root down to each category, search documents carry them
+// so filtering on a category matches its descendants
+func (q *Queries) ListCategoryPaths(ctx context.Context) ([]ListCategoryPathsRow, error) {
+	rows, err := q.db.Query(ctx, listCategoryPaths)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCategoryPathsRow
+	for rows.Next() {
+		var i ListCategoryPathsRow
+		if err := rows.Scan(&i.Slug, &i.Path); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCategorySubtree = `-- name: ListCategorySubtree :many
+WITH RECURSIVE subtree AS (
+  SELECT id, 0 AS depth FROM categories WHERE categories.slug = $1
+  UNION ALL
+  SELECT c.id, s.depth + 1 FROM categories c JOIN subtree s ON c.parent_id = s.id
+)
+SELECT categories.id, categories.parent_id, categories.slug, categories.name, categories.position, categories.created_at, categories.updated_at FROM categories
+JOIN subtree ON subtree.id = categories.id
+ORDER BY subtree.depth, categories.position, categories.name
+`
+
+// the category and all of its descendants, parents before their children
+func (q *Queries) ListCategorySubtree(ctx context.Context, slug string) ([]Category, error) {
+	rows, err := q.db.Query(ctx, listCategorySubtree, slug)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Category
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(
+			&i.ID,
+			&i.ParentID,
+			&i.Slug,
+			&i.Name,
+			&i.Position,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProductIndexQueue = `-- name: ListProductIndexQueue :many
+SELECT id, product_id, created_at FROM product_index_queue
+ORDER BY id
+LIMIT $1
+`
+
+func (q *Queries) ListProductIndexQueue(ctx context.Context, limit int32) ([]ProductIndexQueue, error) {
+	rows, err := q.db.Query(ctx, listProductIndexQueue, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProductIndexQueue
+	for rows.Next() {
+		var i ProductIndexQueue
+		if err := rows.Scan(&i.ID, &i.ProductID, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProducts = `-- name: ListProducts :many
+SELECT id, title, description, category, quantity_in_stock, created_at, updated_at, archived_at, sku, price_minor, currency, discount_bps, rating_count, rating_sum, reserved_quantity FROM product_rows
+WHERE id > $1
+  AND ($2::text[] IS NULL OR category = ANY($2::text[]))
+  AND ($3::boolean OR archived_at IS NULL)
+ORDER BY id
+LIMIT $4
+`
+
+type ListProductsParams struct {
+	After           int32    `json:"after"`
+	Categories      []string `json:"categories"`
+	IncludeArchived bool     `json:"include_archived"`
+	PageSize        int32    `json:"page_size"`
+}
+
+// category matches its descendants as well, categories holds the category
+// and its descendants' slugs
+func (q *Queries) ListProducts(ctx context.Context, arg ListProductsParams) ([]Product, error) {
+	rows, err := q.db.Query(ctx, listProducts,
+		arg.After,
+		arg.Categories,
+		arg.IncludeArchived,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Product
+	for rows.Next() {
+		var i Product
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Category,
+			&i.QuantityInStock,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ArchivedAt,
+			&i.Sku,
+			&i.PriceMinor,
+			&i.Currency,
+			&i.DiscountBps,
+			&i.RatingCount,
+			&i.RatingSum,
+			&i.ReservedQuantity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProductsByCategory = `-- name: ListProductsByCategory :many
+WITH RECURSIVE subtree AS (
+  SELECT id, slug FROM categories WHERE categories.slug = $1
+  UNION ALL
+  SELECT c.id, c.slug FROM categories c JOIN subtree s ON c.parent_id = s.id
+)
+SELECT product_rows.id, product_rows.title, product_rows.description, product_rows.category, product_rows.quantity_in_stock, product_rows.created_at, product_rows.updated_at, product_rows.archived_at, product_rows.sku, product_rows.price_minor, product_rows.currency, product_rows.discount_bps, product_rows.rating_count, product_rows.rating_sum, product_rows.reserved_quantity FROM product_rows
+WHERE product_rows.category IN (SELECT slug FROM subtree)
+`
+
+// products in the category or any of its descendants
+func (q *Queries) ListProductsByCategory(ctx context.Context, slug string) ([]Product, error) {
+	rows, err := q.db.Query(ctx, listProductsByCategory, slug)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Product
+	for rows.Next() {
+		var i Product
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Category,
+			&i.QuantityInStock,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ArchivedAt,
+			&i.Sku,
+			&i.PriceMinor,
+			&i.Currency,
+			&i.DiscountBps,
+			&i.RatingCount,
+			&i.RatingSum,
+			&i.ReservedQuantity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReservationItems = `-- name: ListReservationItems :many
+SELECT id, reservation_id, product_id, variant_id, quantity FROM inventory_reservation_items
+WHERE reservation_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListReservationItems(ctx context.Context, reservationID uuid.UUID) ([]InventoryReservationItem, error) {
+	rows, err := q.db.Query(ctx, listReservationItems, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []InventoryReservationItem
+	for rows.Next() {
+		var i InventoryReservationItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.ReservationID,
+			&i.ProductID,
+			&i.VariantID,
+			&i.Quantity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReviews = `-- name: ListReviews :many
+SELECT id, product_id, user_id, rating, title, body, status, created_at, updated_at FROM product_reviews
+WHERE ($1::int IS NULL OR product_id = $1)
+  AND ($2::text IS NULL OR status = $2)
+ORDER BY
+  CASE WHEN $3::text = 'rating_desc' THEN rating END DESC,
+  CASE WHEN $3::text = 'rating_asc' THEN rating END ASC,
+  CASE WHEN $3::text = 'oldest' THEN created_at END ASC,
+  CASE WHEN $3::text = 'oldest' THEN id END ASC,
+  created_at DESC,
+  id DESC
+LIMIT $5 OFFSET $4
+`
+
+type ListReviewsParams struct {
+	ProductID  pgtype.Int4 `json:"product_id"`
+	Status     pgtype.Text `json:"status"`
+	Sort       string      `json:"sort"`
+	PageOffset int32       `json:"page_offset"`
+	PageSize   int32       `json:"page_size"`
+}
+
+// sort is newest, oldest, rating_desc or rating_asc
+func (q *Queries) ListReviews(ctx context.Context, arg ListReviewsParams) ([]ProductReview, error) {
+	rows, err := q.db.Query(ctx, listReviews,
+		arg.ProductID,
+		arg.Status,
+		arg.Sort,
+		arg.PageOffset,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProductReview
+	for rows.Next() {
+		var i ProductReview
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProductID,
+			&i.UserID,
+			&i.Rating,
+			&i.Title,
+			&i.Body,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSearchSettings = `-- name: ListSearchSettings :many
+SELECT version, settings, note, created_by, created_at, applied_at FROM search_settings
+ORDER BY version DESC
+LIMIT $1
+`
+
+func (q *Queries) ListSearchSettings(ctx context.Context, limit int32) ([]SearchSetting, error) {
+	rows, err := q.db.Query(ctx, listSearchSettings, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchSetting
+	for rows.Next() {
+		var i SearchSetting
+		if err := rows.Scan(
+			&i.Version,
+			&i.Settings,
+			&i.Note,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.AppliedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserReviewRatings = `-- name: ListUserReviewRatings :many
+SELECT product_id, rating FROM product_reviews
+WHERE user_id = $1 AND product_id = ANY($2::int[])
+`
+
+type ListUserReviewRatingsParams struct {
+	UserID     uuid.UUID `json:"user_id"`
+	ProductIds []int32   `json:"product_ids"`
+}
+
+type ListUserReviewRatingsRow struct {
+	ProductID int32 `json:"product_id"`
+	Rating    int32 `json:"rating"`
+}
+
+// the user's own ratings whatever the review's status, to show them what
+// they rated
+func (q *Queries) ListUserReviewRatings(ctx context.Context, arg ListUserReviewRatingsParams) ([]ListUserReviewRatingsRow, error) {
+	rows, err := q.db.Query(ctx, listUserReviewRatings, arg.UserID, arg.ProductIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUserReviewRatingsRow
+	for rows.Next() {
+		var i ListUserReviewRatingsRow
+		if err := rows.Scan(&i.ProductID, &i.Rating); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listVariantsByProductIds = `-- name: ListVariantsByProductIds :many
+SELECT id, product_id, sku, attributes, price_minor, quantity_in_stock, position, created_at, updated_at, archived_at, reserved_quantity FROM product_variants
+WHERE product_id = ANY($1::int[])
+  AND archived_at IS NULL
+ORDER BY product_id, position, id
+`
+
+func (q *Queries) ListVariantsByProductIds(ctx context.Context, productIds []int32) ([]ProductVariant, error) {
+	rows, err := q.db.Query(ctx, listVariantsByProductIds, productIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProductVariant
+	for rows.Next() {
+		var i ProductVariant
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProductID,
+			&i.Sku,
+			&i.Attributes,
+			&i.PriceMinor,
+			&i.QuantityInStock,
+			&i.Position,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ArchivedAt,
+			&i.ReservedQuantity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockProductIndexQueue = `-- name: LockProductIndexQueue :exec
+SELECT pg_advisory_xact_lock(hashtext('product_index_queue'))
+`
+
+// held until the end of the transaction, so only one indexer applies changes
+// at a time and they reach the index in order
+func (q *Queries) LockProductIndexQueue(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, lockProductIndexQueue)
+	return err
+}
+
+const lockSearchSettings = `-- name: LockSearchSettings :exec
+SELECT pg_advisory_xact_lock(hashtext('search_settings'))
+`
+
+// held until the end of the transaction, so settings are applied one at a
+// time and a reindex swaps in an index with the active settings
+func (q *Queries) LockSearchSettings(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, lockSearchSettings)
+	return err
+}
+
+const markSearchSettingsApplied = `-- name: MarkSearchSettingsApplied :one
+UPDATE search_settings
+SET applied_at = clock_timestamp()
+WHERE version = $1
+RETURNING version, settings, note, created_by, created_at, applied_at
+`
+
+// the time of the update rather than of the transaction, a transaction that
+// started earlier but waited on the lock still marks its version the latest
+func (q *Queries) MarkSearchSettingsApplied(ctx context.Context, version int32) (SearchSetting, error) {
+	row := q.db.QueryRow(ctx, markSearchSettingsApplied, version)
+	var i SearchSetting
+	err := row.Scan(
+		&i.Version,
+		&i.Settings,
+		&i.Note,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.AppliedAt,
+	)
+	return i, err
+}
+
+const releaseReservation = `-- name: ReleaseReservation :one
+UPDATE inventory_reservations
+SET status = 'released',
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'pending'
+RETURNING id, reference, status, expires_at, created_at, updated_at
+`
+
+func (q *Queries) ReleaseReservation(ctx context.Context, id uuid.UUID) (InventoryReservation, error) {
+	row := q.db.QueryRow(ctx, releaseReservation, id)
+	var i InventoryReservation
+	err := row.Scan(
+		&i.ID,
+		&i.Reference,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const releaseReservedProducts = `-- name: ReleaseReservedProducts :exec
+UPDATE products
+SET reserved_quantity = products.reserved_quantity - reserved.quantity
+FROM (
+  SELECT product_id, SUM(quantity)::int AS quantity FROM inventory_reservation_items
+  WHERE reservation_id = ANY($1::uuid[]) AND variant_id IS NULL
+  GROUP BY product_id
+) reserved
+WHERE products.id = reserved.product_id
+`
+
+// stops holding the stock of released and expired reservations
+func (q *Queries) ReleaseReservedProducts(ctx context.Context, reservationIds []uuid.UUID) error {
+	_, err := q.db.Exec(ctx, releaseReservedProducts, reservationIds)
+	return err
+}
+
+const releaseReservedVariants = `-- name: ReleaseReservedVariants :exec
+UPDATE product_variants
+SET reserved_quantity = product_variants.reserved_quantity - reserved.quantity
+FROM (
+  SELECT variant_id, SUM(quantity)::int AS quantity FROM inventory_reservation_items
+  WHERE reservation_id = ANY($1::uuid[]) AND variant_id IS NOT NULL
+  GROUP BY variant_id
+) reserved
+WHERE product_variants.id = reserved.variant_id
+`
+
+func (q *Queries) ReleaseReservedVariants(ctx context.Context, reservationIds []uuid.UUID) error {
+	_, err := q.db.Exec(ctx, releaseReservedVariants, reservationIds)
+	return err
+}
+
+const reserveProductStock = `-- name: ReserveProductStock :execrows
+UPDATE products
+SET reserved_quantity = products.reserved_quantity + $1
+WHERE products.id = $2
+  AND products.archived_at IS NULL
+  AND products.quantity_in_stock - products.reserved_quantity >= $1
+  AND NOT EXISTS (
+    SELECT 1 FROM product_variants v
+    WHERE v.product_id = products.id AND v.archived_at IS NULL
+  )
+`
+
+type ReserveProductStockParams struct {
+	Quantity int32 `json:"quantity"`
+	ID       int32 `json:"id"`
+}
+
+// holds the stock only if enough of it is available. Concurrent reservations
+// of the product queue on its row and recheck the condition against what the
+// one before them held. Products with live variants keep their stock on the
+// variants.
+func (q *Queries) ReserveProductStock(ctx context.Context, arg ReserveProductStockParams) (int64, error) {
+	result, err := q.db.Exec(ctx, reserveProductStock, arg.Quantity, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const reserveVariantStock = `-- name: ReserveVariantStock :execrows
+UPDATE product_variants
+SET reserved_quantity = product_variants.reserved_quantity + $1
+WHERE product_variants.id = $2
+  AND product_variants.product_id = $3
+  AND product_variants.archived_at IS NULL
+  AND product_variants.quantity_in_stock - product_variants.reserved_quantity >= $1
+  AND EXISTS (
+    SELECT 1 FROM products p
+    WHERE p.id = product_variants.product_id AND p.archived_at IS NULL
+  )
+`
+
+type ReserveVariantStockParams struct {
+	Quantity  int32 `json:"quantity"`
+	ID        int32 `json:"id"`
+	ProductID int32 `json:"product_id"`
+}
+
+func (q *Queries) ReserveVariantStock(ctx context.Context, arg ReserveVariantStockParams) (int64, error) {
+	result, err := q.db.Exec(ctx, reserveVariantStock, arg.Quantity, arg.ID, arg.ProductID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const searchClickThrough = `-- name: SearchClickThrough :many
+SELECT e.query,
+       COUNT(*)::bigint AS searches,
+       COUNT(c.search_id)::bigint AS clicked_searches,
+       COALESCE(SUM(c.clicks), 0)::bigint AS clicks,
+       COALESCE(AVG(c.first_position), 0)::float8 AS avg_first_position
+FROM search_events e
+LEFT JOIN (
+  SELECT search_id, COUNT(*) AS clicks, MIN(position) AS first_position
+  FROM search_clicks
+  WHERE search_clicks.created_at >= $1
+  GROUP BY search_id
+) c ON c.search_id = e.id
+WHERE e.created_at >= $1 AND e.query <> '' AND e.hit_count > 0
+GROUP BY e.query
+ORDER BY searches DESC, e.query
+LIMIT $2
+`
+
+type SearchClickThroughParams struct {
+	Since      time.Time `json:"since"`
+	MaxResults int32     `json:"max_results"`
+}
+
+type SearchClickThroughRow struct {
+	Query            string  `json:"query"`
+	Searches         int64   `json:"searches"`
+	ClickedSearches  int64   `json:"clicked_searches"`
+	Clicks           int64   `json:"clicks"`
+	AvgFirstPosition float64 `json:"avg_first_position"`
+}
+
+// only searches that found something can be clicked through
+func (q *Queries) SearchClickThrough(ctx context.Context, arg SearchClickThroughParams) ([]SearchClickThroughRow, error) {
+	rows, err := q.db.Query(ctx, searchClickThrough, arg.Since, arg.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchClickThroughRow
+	for rows.Next() {
+		var i SearchClickThroughRow
+		if err := rows.Scan(
+			&i.Query,
+			&i.Searches,
+			&i.ClickedSearches,
+			&i.Clicks,
+			&i.AvgFirstPosition,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchProductsFullText = `-- name: SearchProductsFullText :many
+SELECT product_rows.id, product_rows.title, product_rows.description, product_rows.category, product_rows.quantity_in_stock, product_rows.created_at, product_rows.updated_at, product_rows.archived_at, product_rows.sku, product_rows.price_minor, product_rows.currency, product_rows.discount_bps, product_rows.rating_count, product_rows.rating_sum, product_rows.reserved_quantity FROM product_rows
+JOIN products ON products.id = product_rows.id
+WHERE product_rows.archived_at IS NULL
+  AND ($1::text = '' OR products.search_vector @@ websearch_to_tsquery('english', $1::text))
+  AND ($2::text[] IS NULL OR product_rows.category = ANY($2::text[]))
+  AND product_rows.discount_bps::float8 / 100 >= $3::float8
+  AND (
+    SELECT COALESCE(min(COALESCE(v.price_minor, product_rows.price_minor)), product_rows.price_minor)
+    FROM product_variants v
+    WHERE v.product_id = product_rows.id AND v.archived_at IS NULL
+  )::float8 / 10::float8 ^ COALESCE(($4::int[])[array_position($5::text[], product_rows.currency::text)], 0)
+    BETWEEN $6::float8 AND $7::float8
+  AND (NOT $8::boolean OR (
+    SELECT COALESCE(sum(GREATEST(v.quantity_in_stock - v.reserved_quantity, 0)), GREATEST(product_rows.quantity_in_stock - product_rows.reserved_quantity, 0))
+    FROM product_variants v
+    WHERE v.product_id = product_rows.id AND v.archived_at IS NULL
+  ) > 0)
+ORDER BY ts_rank_cd(products.search_vector, websearch_to_tsquery('english', $1::text)) DESC, product_rows.id
+LIMIT $9
+`
+
+type SearchProductsFullTextParams struct {
+	Query       string   `json:"query"`
+	Categories  []string `json:"categories"`
+	MinDiscount float64  `json:"min_discount"`
+	Exponents   []int32  `json:"exponents"`
+	Currencies  []string `json:"currencies"`
+	MinPrice    float64  `json:"min_price"`
+	MaxPrice    float64  `json:"max_price"`
+	InStock     bool     `json:"in_stock"`
+	MaxResults  int32    `json:"max_results"`
+}
+
+// the search fallback, best matches first. An empty query matches every
+// product. It filters the way the index does, so max_results caps the matching
+// products rather than every match. categories holds the filtered categories
+// and their descendants' slugs. The price bounds are in major units, exponents
+// holds the minor unit digits of each of currencies. For a product with
+// variants the price is the lowest variant price and the stock is what the
+// variants have available.
+func (q *Queries) SearchProductsFullText(ctx context.Context, arg SearchProductsFullTextParams) ([]Product, error) {
+	rows, err := q.db.Query(ctx, searchProductsFullText,
+		arg.Query,
+		arg.Categories,
+		arg.MinDiscount,
+		arg.Exponents,
+		arg.Currencies,
+		arg.MinPrice,
+		arg.MaxPrice,
+		arg.InStock,
+		arg.MaxResults,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Product
+	for rows.Next() {
+		var i Product
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Category,
+			&i.QuantityInStock,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ArchivedAt,
+			&i.Sku,
+			&i.PriceMinor,
+			&i.Currency,
+			&i.DiscountBps,
+			&i.RatingCount,
+			&i.RatingSum,
+			&i.ReservedQuantity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setReviewStatus = `-- name: SetReviewStatus :one
+UPDATE product_reviews
+SET status = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, product_id, user_id, rating, title, body, status, created_at, updated_at
+`
+
+type SetReviewStatusParams struct {
+	ID     int32  `json:"id"`
+	Status string `json:"status"`
+}
+
+func (q *Queries) SetReviewStatus(ctx context.Context, arg SetReviewStatusParams) (ProductReview, error) {
+	row := q.db.QueryRow(ctx, setReviewStatus, arg.ID, arg.Status)
+	var i ProductReview
+	err := row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.UserID,
+		&i.Rating,
+		&i.Title,
+		&i.Body,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const shipReservedProducts = `-- name: ShipReservedProducts :exec
+UPDATE products
+SET quantity_in_stock = GREATEST(products.quantity_in_stock - reserved.quantity, 0),
+    reserved_quantity = products.reserved_quantity - reserved.quantity
+FROM (
+  SELECT product_id, SUM(quantity)::int AS quantity FROM inventory_reservation_items
+  WHERE reservation_id = $1 AND variant_id IS NULL
+  GROUP BY product_id
+) reserved
+WHERE products.id = reserved.product_id
+`
+
+// takes the stock of a committed reservation off the stock on hand. Stock set
+// below what was held after the reservation was made stops at 0.
+func (q *Queries) ShipReservedProducts(ctx context.Context, reservationID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, shipReservedProducts, reservationID)
+	return err
+}
+
+const shipReservedVariants = `-- name: ShipReservedVariants :exec
+UPDATE product_variants
+SET quantity_in_stock = GREATEST(product_variants.quantity_in_stock - reserved.quantity, 0),
+    reserved_quantity = product_variants.reserved_quantity - reserved.quantity
+FROM (
+  SELECT variant_id, SUM(quantity)::int AS quantity FROM inventory_reservation_items
+  WHERE reservation_id = $1 AND variant_id IS NOT NULL
+  GROUP BY variant_id
+) reserved
+WHERE product_variants.id = reserved.variant_id
+`
+
+func (q *Queries) ShipReservedVariants(ctx context.Context, reservationID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, shipReservedVariants, reservationID)
+	return err
+}
+
+const topSearchQueries = `-- name: TopSearchQueries :many
+SELECT query,
+       COUNT(*)::bigint AS searches,
+       AVG(hit_count)::float8 AS avg_hits,
+       AVG(latency_ms)::float8 AS avg_latency_ms
+FROM search_events
+WHERE created_at >= $1 AND query <> ''
+GROUP BY query
+ORDER BY searches DESC, query
+LIMIT $2
+`
+
+type TopSearchQueriesParams struct {
+	Since      time.Time `json:"since"`
+	MaxResults int32     `json:"max_results"`
+}
+
+type TopSearchQueriesRow struct {
+	Query        string  `json:"query"`
+	Searches     int64   `json:"searches"`
+	AvgHits      float64 `json:"avg_hits"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+}
+
+func (q *Queries) TopSearchQueries(ctx context.Context, arg TopSearchQueriesParams) ([]TopSearchQueriesRow, error) {
+	rows, err := q.db.Query(ctx, topSearchQueries, arg.Since, arg.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TopSearchQueriesRow
+	for rows.Next() {
+		var i TopSearchQueriesRow
+		if err := rows.Scan(
+			&i.Query,
+			&i.Searches,
+			&i.AvgHits,
+			&i.AvgLatencyMs,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateProduct = `-- name: UpdateProduct :one
+UPDATE products
+SET title = $2,
+    description = $3,
+    category = $4,
+    price_minor = $5,
+    currency = $6,
+    discount_bps = $7,
+    quantity_in_stock = $8,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, title, description, category, quantity_in_stock, created_at, updated_at, archived_at,
+          sku, price_minor, currency, discount_bps, rating_count, rating_sum, reserved_quantity
+`
+
+type UpdateProductParams struct {
+	ID              int32             `json:"id"`
+	Title           string            `json:"title"`
+	Description     string            `json:"description"`
+	Category        string            `json:"category"`
+	PriceMinor      money.Minor       `json:"price_minor"`
+	Currency        money.Currency    `json:"currency"`
+	DiscountBps     money.BasisPoints `json:"discount_bps"`
+	QuantityInStock int32             `json:"quantity_in_stock"`
+}
+
+type UpdateProductRow struct {
+	ID               int32              `json:"id"`
+	Title            string             `json:"title"`
+	Description      string             `json:"description"`
+	Category         string             `json:"category"`
+	QuantityInStock  int32              `json:"quantity_in_stock"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+	ArchivedAt       pgtype.Timestamptz `json:"archived_at"`
+	Sku              pgtype.Text        `json:"sku"`
+	PriceMinor       money.Minor        `json:"price_minor"`
+	Currency         money.Currency     `json:"currency"`
+	DiscountBps      money.BasisPoints  `json:"discount_bps"`
+	RatingCount      int32              `json:"rating_count"`
+	RatingSum        int32              `json:"rating_sum"`
+	ReservedQuantity int32              `json:"reserved_quantity"`
+}
+
+// returns the columns of product_rows, search_vector is left out
+func (q *Queries) UpdateProduct(ctx context.Context, arg UpdateProductParams) (UpdateProductRow, error) {
+	row := q.db.QueryRow(ctx, updateProduct,
+		arg.ID,
+		arg.Title,
+		arg.Description,
+		arg.Category,
+		arg.PriceMinor,
+		arg.Currency,
+		arg.DiscountBps,
+		arg.QuantityInStock,
+	)
+	var i UpdateProductRow
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.Category,
+		&i.QuantityInStock,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ArchivedAt,
+		&i.Sku,
+		&i.PriceMinor,
+		&i.Currency,
+		&i.DiscountBps,
+		&i.RatingCount,
+		&i.RatingSum,
+		&i.ReservedQuantity,
+	)
+	return i, err
+}
+
+const updateProductVariant = `-- name: UpdateProductVariant :one
+UPDATE product_variants
+SET sku = $3,
+    attributes = $4,
+    price_minor = $5,
+    quantity_in_stock = $6,
+    position = $7,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND product_id = $2 AND archived_at IS NULL
+RETURNING id, product_id, sku, attributes, price_minor, quantity_in_stock, position, created_at, updated_at, archived_at, reserved_quantity
+`
+
+type UpdateProductVariantParams struct {
+	ID              int32           `json:"id"`
+	ProductID       int32           `json:"product_id"`
+	Sku             string          `json:"sku"`
+	Attributes      json.RawMessage `json:"attributes"`
+	PriceMinor      *money.Minor    `json:"price_minor"`
+	QuantityInStock int32           `json:"quantity_in_stock"`
+	Position        int32           `json:"position"`
+}
+
+func (q *Queries) UpdateProductVariant(ctx context.Context, arg UpdateProductVariantParams) (ProductVariant, error) {
+	row := q.db.QueryRow(ctx, updateProductVariant,
+		arg.ID,
+		arg.ProductID,
+		arg.Sku,
+		arg.Attributes,
+		arg.PriceMinor,
+		arg.QuantityInStock,
+		arg.Position,
+	)
+	var i ProductVariant
+	err := row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.Sku,
+		&i.Attributes,
+		&i.PriceMinor,
+		&i.QuantityInStock,
+		&i.Position,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ArchivedAt,
+		&i.ReservedQuantity,
+	)
+	return i, err
+}
+
+const updateReview = `-- name: UpdateReview :one
+UPDATE product_reviews
+SET rating = $3,
+    title = $4,
+    body = $5,
+    status = CASE WHEN status = 'rejected' THEN 'pending' ELSE status END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE product_id = $1 AND user_id = $2
+RETURNING id, product_id, user_id, rating, title, body, status, created_at, updated_at
+`
+
+type UpdateReviewParams struct {
+	ProductID int32     `json:"product_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Rating    int32     `json:"rating"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+}
+
+// a rejected review goes back to pending once its author edits it
+func (q *Queries) UpdateReview(ctx context.Context, arg UpdateReviewParams) (ProductReview, error) {
+	row := q.db.QueryRow(ctx, updateReview,
+		arg.ProductID,
+		arg.UserID,
+		arg.Rating,
+		arg.Title,
+		arg.Body,
+	)
+	var i ProductReview
+	err := row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.UserID,
+		&i.Rating,
+		&i.Title,
+		&i.Body,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertProductBySKU = `-- name: UpsertProductBySKU :one
+INSERT INTO products (
+  sku, title, description, category, price_minor, currency, discount_bps, quantity_in_stock
+) VALUES (
+  $1::text, $2, $3, $4,
+  $5, $6, $7, $8
+)
+ON CONFLICT (sku) WHERE sku IS NOT NULL DO UPDATE
+SET title = EXCLUDED.title,
+    description = EXCLUDED.description,
+    category = EXCLUDED.category,
+    price_minor = EXCLUDED.price_minor,
+    currency = EXCLUDED.currency,
+    discount_bps = EXCLUDED.discount_bps,
+    quantity_in_stock = EXCLUDED.quantity_in_stock,
+    updated_at = CURRENT_TIMESTAMP
+WHERE (products.title, products.description, products.category, products.price_minor, products.currency, products.discount_bps, products.quantity_in_stock)
+  IS DISTINCT FROM (EXCLUDED.title, EXCLUDED.description, EXCLUDED.category, EXCLUDED.price_minor, EXCLUDED.currency, EXCLUDED.discount_bps, EXCLUDED.quantity_in_stock)
+RETURNING id, (xmax = 0)::boolean AS inserted
+`
+
+type UpsertProductBySKUParams struct {
+	Sku             string            `json:"sku"`
+	Title           string            `json:"title"`
+	Description     string            `json:"description"`
+	Category        string            `json:"category"`
+	PriceMinor      money.Minor       `json:"price_minor"`
+	Currency        money.Currency    `json:"currency"`
+	DiscountBps     money.BasisPoints `json:"discount_bps"`
+	QuantityInStock int32             `json:"quantity_in_stock"`
+}
+
+type UpsertProductBySKURow struct {
+	ID       int32 `json:"id"`
+	Inserted bool  `json:"inserted"`
+}
+
+// returns no row when the product already matches, so re-running an import
+// doesn't touch updated_at or queue the product for indexing again
+func (q *Queries) UpsertProductBySKU(ctx context.Context, arg UpsertProductBySKUParams) (UpsertProductBySKURow, error) {
+	row := q.db.QueryRow(ctx, upsertProductBySKU,
+		arg.Sku,
+		arg.Title,
+		arg.Description,
+		arg.Category,
+		arg.PriceMinor,
+		arg.Currency,
+		arg.DiscountBps,
+		arg.QuantityInStock,
+	)
+	var i UpsertProductBySKURow
+	err := row.Scan(&i.ID, &i.Inserted)
+	return i, err
+}
+
+const zeroResultSearchQueries = `-- name: ZeroResultSearchQueries :many
+SELECT query,
+       COUNT(*)::bigint AS searches,
+       MAX(created_at)::timestamptz AS last_searched_at
+FROM search_events
+WHERE created_at >= $1 AND query <> '' AND hit_count = 0 AND NOT degraded
+GROUP BY query
+ORDER BY searches DESC, query
+LIMIT $2
+`
+
+type ZeroResultSearchQueriesParams struct {
+	Since      time.Time `json:"since"`
+	MaxResults int32     `json:"max_results"`
+}
+
+type ZeroResultSearchQueriesRow struct {
+	Query          string    `json:"query"`
+	Searches       int64     `json:"searches"`
+	LastSearchedAt time.Time `json:"last_searched_at"`
+}
+
+// degraded searches are left out, the fallback misses what the index finds
+func (q *Queries) ZeroResultSearchQueries(ctx context.Context, arg ZeroResultSearchQueriesParams) ([]ZeroResultSearchQueriesRow, error) {
+	rows, err := q.db.Query(ctx, zeroResultSearchQueries, arg.Since, arg.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ZeroResultSearchQueriesRow
+	for rows.Next() {
+		var i ZeroResultSearchQueriesRow
+		if err := rows.Scan(&i.Query, &i.Searches, &i.LastSearchedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}